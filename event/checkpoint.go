@@ -0,0 +1,135 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Position is the key and creation time of the last event processed for an object.
+type Position struct {
+	Key         int32     `json:"key"`
+	CreatedTime time.Time `json:"createdTime"`
+}
+
+// Checkpoint records the Position of the last event processed for each object streamed by Manager.Stream,
+// such that a consumer can resume after a restart without duplicate or missing events.
+// A Checkpoint is persisted to a file after each page of events is processed.
+type Checkpoint struct {
+	mu      sync.Mutex
+	file    string
+	Objects map[string]Position `json:"objects"`
+}
+
+// NewCheckpoint loads the Checkpoint from the given file, if it exists.
+// If file is empty, the Checkpoint is only kept in memory.
+func NewCheckpoint(file string) (*Checkpoint, error) {
+	c := &Checkpoint{
+		file:    file,
+		Objects: make(map[string]Position),
+	}
+
+	if file == "" {
+		return c, nil
+	}
+
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, err
+	}
+
+	if err = json.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
+
+	if c.Objects == nil {
+		c.Objects = make(map[string]Position)
+	}
+
+	return c, nil
+}
+
+// Position returns the last Position recorded for the given object, if any.
+func (c *Checkpoint) Position(obj types.ManagedObjectReference) (Position, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, ok := c.Objects[obj.String()]
+	return p, ok
+}
+
+// update records the newest of the given events for the given object and saves the Checkpoint.
+func (c *Checkpoint) update(obj types.ManagedObjectReference, events []types.BaseEvent) error {
+	c.mu.Lock()
+
+	p := c.Objects[obj.String()]
+	for _, e := range events {
+		event := e.GetEvent()
+		if event.Key > p.Key {
+			p = Position{Key: event.Key, CreatedTime: event.CreatedTime}
+		}
+	}
+	c.Objects[obj.String()] = p
+
+	c.mu.Unlock()
+
+	return c.Save()
+}
+
+// Save writes the Checkpoint to its file, replacing any previous version atomically.
+func (c *Checkpoint) Save() error {
+	if c.file == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	b, err := json.Marshal(c)
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(c.file), filepath.Base(c.file))
+	if err != nil {
+		return err
+	}
+
+	if _, err = f.Write(b); err == nil {
+		err = f.Sync()
+	}
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), c.file)
+}
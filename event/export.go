@@ -0,0 +1,232 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Record is a normalized Event, suitable for encoding as JSON.
+type Record struct {
+	Type      string                 `json:"type"`
+	Key       int32                  `json:"key"`
+	ChainID   int32                  `json:"chainId"`
+	Time      time.Time              `json:"time"`
+	User      string                 `json:"user,omitempty"`
+	Category  string                 `json:"category"`
+	Entity    string                 `json:"entity,omitempty"` // Inventory path of the primary entity
+	Ref       string                 `json:"ref,omitempty"`    // Reference of the primary entity
+	Message   string                 `json:"message"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+// Sink is the destination of exported Records.
+type Sink interface {
+	Write(context.Context, []Record) error
+	Close() error
+}
+
+// Exporter normalizes events to Records and writes them to a Sink.
+type Exporter struct {
+	m    *Manager
+	sink Sink
+
+	mu    sync.Mutex
+	paths map[types.ManagedObjectReference]string
+	args  map[string][]types.EventArgDesc
+}
+
+// NewExporter creates a new Exporter instance.
+func NewExporter(m *Manager, sink Sink) *Exporter {
+	return &Exporter{
+		m:     m,
+		sink:  sink,
+		paths: make(map[types.ManagedObjectReference]string),
+		args:  make(map[string][]types.EventArgDesc),
+	}
+}
+
+// entity returns the primary entity argument of the event, if any.
+func entity(e *types.Event) (*types.ManagedObjectReference, *types.EntityEventArgument) {
+	switch {
+	case e.Vm != nil:
+		return &e.Vm.Vm, &e.Vm.EntityEventArgument
+	case e.Host != nil:
+		return &e.Host.Host, &e.Host.EntityEventArgument
+	case e.ComputeResource != nil:
+		return &e.ComputeResource.ComputeResource, &e.ComputeResource.EntityEventArgument
+	case e.Ds != nil:
+		return &e.Ds.Datastore, &e.Ds.EntityEventArgument
+	case e.Net != nil:
+		return &e.Net.Network, &e.Net.EntityEventArgument
+	case e.Dvs != nil:
+		return &e.Dvs.Dvs, &e.Dvs.EntityEventArgument
+	case e.Datacenter != nil:
+		return &e.Datacenter.Datacenter, &e.Datacenter.EntityEventArgument
+	}
+
+	return nil, nil
+}
+
+// path returns the inventory path of the given entity, or name if the entity no longer exists.
+func (e *Exporter) path(ctx context.Context, ref types.ManagedObjectReference, name string) string {
+	e.mu.Lock()
+	p, ok := e.paths[ref]
+	e.mu.Unlock()
+	if ok {
+		return p
+	}
+
+	c := e.m.Client()
+	mes, err := mo.Ancestors(ctx, c, c.ServiceContent.PropertyCollector, ref)
+	if err != nil {
+		return name // not cached, as the error may be transient
+	}
+
+	for _, me := range mes {
+		// Skip root entity in building inventory path.
+		if me.Parent == nil {
+			continue
+		}
+
+		p = p + "/" + me.Name
+	}
+
+	e.mu.Lock()
+	e.paths[ref] = p
+	e.mu.Unlock()
+
+	return p
+}
+
+// arguments returns the event argument values, as described by the EventManager for the event type.
+func (e *Exporter) arguments(ctx context.Context, event types.BaseEvent) (map[string]interface{}, error) {
+	val := reflect.ValueOf(event).Elem()
+	kind := val.Type().Name()
+
+	e.mu.Lock()
+	desc, ok := e.args[kind]
+	e.mu.Unlock()
+
+	if !ok {
+		var err error
+		desc, err = e.m.RetrieveArgumentDescription(ctx, kind)
+		if err != nil {
+			return nil, err
+		}
+
+		e.mu.Lock()
+		e.args[kind] = desc
+		e.mu.Unlock()
+	}
+
+	args := make(map[string]interface{})
+
+	for _, arg := range desc {
+		field, ok := val.Type().FieldByNameFunc(func(name string) bool {
+			return strings.EqualFold(name, arg.Name)
+		})
+		if !ok {
+			continue
+		}
+
+		v := val.FieldByIndex(field.Index)
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Slice:
+			if v.IsNil() {
+				continue
+			}
+		}
+
+		if a, ok := v.Interface().(types.BaseEntityEventArgument); ok {
+			args[arg.Name] = a.GetEntityEventArgument().Name
+			continue
+		}
+
+		args[arg.Name] = v.Interface()
+	}
+
+	if len(args) == 0 {
+		return nil, nil
+	}
+
+	return args, nil
+}
+
+// Record returns the normalized Record for the given event.
+func (e *Exporter) Record(ctx context.Context, event types.BaseEvent) (*Record, error) {
+	category, err := e.m.EventCategory(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := e.arguments(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	base := event.GetEvent()
+
+	r := &Record{
+		Type:      reflect.TypeOf(event).Elem().Name(),
+		Key:       base.Key,
+		ChainID:   base.ChainId,
+		Time:      base.CreatedTime,
+		User:      base.UserName,
+		Category:  category,
+		Message:   strings.TrimSpace(base.FullFormattedMessage),
+		Arguments: args,
+	}
+
+	if ref, arg := entity(base); ref != nil {
+		r.Ref = ref.String()
+		r.Entity = e.path(ctx, *ref, arg.Name)
+	}
+
+	return r, nil
+}
+
+// Export writes the given events, in ascending Key order, to the Sink.
+func (e *Exporter) Export(ctx context.Context, events []types.BaseEvent) error {
+	Sort(events)
+
+	records := make([]Record, 0, len(events))
+
+	for _, event := range events {
+		r, err := e.Record(ctx, event)
+		if err != nil {
+			return err
+		}
+		records = append(records, *r)
+	}
+
+	return e.sink.Write(ctx, records)
+}
+
+// Stream exports the events matching the given Filter via Manager.Stream.
+func (e *Exporter) Stream(ctx context.Context, filter Filter) error {
+	return e.m.Stream(ctx, filter, func(_ types.ManagedObjectReference, events []types.BaseEvent) error {
+		return e.Export(ctx, events)
+	})
+}
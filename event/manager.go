@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"reflect"
 	"sync"
+	"time"
 
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/property"
@@ -170,17 +171,93 @@ func (m Manager) EventCategory(ctx context.Context, event types.BaseEvent) (stri
 
 // Get the events from the specified object(s) and optionanlly tail the event stream
 func (m Manager) Events(ctx context.Context, objects []types.ManagedObjectReference, pageSize int32, tail bool, force bool, f func(types.ManagedObjectReference, []types.BaseEvent) error, kind ...string) error {
-	// TODO: deprecated this method and add one that uses a single config struct, so we can extend further without breaking the method signature.
-	if len(objects) >= m.maxObjects && !force {
+	filter := Filter{
+		Objects:  objects,
+		PageSize: pageSize,
+		Tail:     tail,
+		Force:    force,
+		Type:     kind,
+	}
+
+	return m.Stream(ctx, filter, f)
+}
+
+// Filter configures the events returned by Manager.Stream.
+type Filter struct {
+	Objects   []types.ManagedObjectReference       // Entities to collect events for, required
+	Recursion types.EventFilterSpecRecursionOption // Defaults to "all", including events for children of Objects
+	Type      []string                             // Event type IDs, such as "VmPoweredOnEvent"
+	Category  []string                             // Event categories, such as "error" or "warning"
+	UserName  []string                             // Users that logged the events
+	System    bool                                 // Include events logged by the system, if UserName is set
+	ChainID   int32                                // Events with the given chain ID, such as the events of a single task
+	Begin     *time.Time                           // Events created at or after this time
+	End       *time.Time                           // Events created at or before this time
+
+	PageSize int32 // Number of events per page, defaults to 10
+	Tail     bool  // Follow the event stream
+	Force    bool  // Disable the maximum number of objects limit
+
+	// Checkpoint, if set, is used to resume streaming from the last event processed for each object,
+	// reading events via the collectors' scrollable view rather than the latest page.
+	Checkpoint *Checkpoint
+}
+
+// spec returns the EventFilterSpec for the given object.
+func (f *Filter) spec(obj types.ManagedObjectReference) types.EventFilterSpec {
+	recursion := f.Recursion
+	if recursion == "" {
+		recursion = types.EventFilterSpecRecursionOptionAll
+	}
+
+	spec := types.EventFilterSpec{
+		Entity: &types.EventFilterSpecByEntity{
+			Entity:    obj,
+			Recursion: recursion,
+		},
+		EventTypeId:  f.Type,
+		Category:     f.Category,
+		EventChainId: f.ChainID,
+	}
+
+	if len(f.UserName) != 0 {
+		spec.UserName = &types.EventFilterSpecByUsername{
+			SystemUser: f.System,
+			UserList:   f.UserName,
+		}
+	}
+
+	begin := f.Begin
+	if f.Checkpoint != nil {
+		if pos, ok := f.Checkpoint.Position(obj); ok {
+			if begin == nil || begin.Before(pos.CreatedTime) {
+				begin = &pos.CreatedTime
+			}
+		}
+	}
+
+	if begin != nil || f.End != nil {
+		spec.Time = &types.EventFilterSpecByTime{
+			BeginTime: begin,
+			EndTime:   f.End,
+		}
+	}
+
+	return spec
+}
+
+// Stream calls f with the events for each of the filter Objects and optionally tails the event stream.
+func (m Manager) Stream(ctx context.Context, filter Filter, f func(types.ManagedObjectReference, []types.BaseEvent) error) error {
+	if len(filter.Objects) >= m.maxObjects && !filter.Force {
 		return fmt.Errorf("Maximum number of objects to monitor (%d) exceeded, refine search", m.maxObjects)
 	}
 
-	proc := newEventProcessor(m, pageSize, f, kind)
-	for _, o := range objects {
+	proc := newEventProcessor(m, &filter, f)
+	for _, o := range filter.Objects {
 		proc.addObject(ctx, o)
 	}
 
 	defer proc.destroy()
 
-	return proc.run(ctx, tail)
+	return proc.run(ctx, filter.Tail)
 }
@@ -33,48 +33,50 @@ type tailInfo struct {
 
 type eventProcessor struct {
 	mgr      Manager
-	pageSize int32
-	kind     []string
+	filter   *Filter
 	tailers  map[types.ManagedObjectReference]*tailInfo // tailers by collector ref
 	callback func(types.ManagedObjectReference, []types.BaseEvent) error
 }
 
-func newEventProcessor(mgr Manager, pageSize int32, callback func(types.ManagedObjectReference, []types.BaseEvent) error, kind []string) *eventProcessor {
+func newEventProcessor(mgr Manager, filter *Filter, callback func(types.ManagedObjectReference, []types.BaseEvent) error) *eventProcessor {
 	return &eventProcessor{
 		mgr:      mgr,
+		filter:   filter,
 		tailers:  make(map[types.ManagedObjectReference]*tailInfo),
 		callback: callback,
-		pageSize: pageSize,
-		kind:     kind,
 	}
 }
 
 func (p *eventProcessor) addObject(ctx context.Context, obj types.ManagedObjectReference) error {
-	filter := types.EventFilterSpec{
-		Entity: &types.EventFilterSpecByEntity{
-			Entity:    obj,
-			Recursion: types.EventFilterSpecRecursionOptionAll,
-		},
-		EventTypeId: p.kind,
-	}
-
-	collector, err := p.mgr.CreateCollectorForEvents(ctx, filter)
+	collector, err := p.mgr.CreateCollectorForEvents(ctx, p.filter.spec(obj))
 	if err != nil {
 		return fmt.Errorf("[%#v] %s", obj, err)
 	}
 
-	err = collector.SetPageSize(ctx, p.pageSize)
+	info := &tailInfo{
+		t:         newEventTailer(),
+		obj:       obj,
+		collector: collector,
+	}
+
+	p.tailers[collector.Reference()] = info
+
+	err = collector.SetPageSize(ctx, p.filter.PageSize)
 	if err != nil {
 		return err
 	}
 
-	p.tailers[collector.Reference()] = &tailInfo{
-		t:         newEventTailer(),
-		obj:       obj,
-		collector: collector,
+	if p.filter.Checkpoint == nil {
+		return nil
 	}
 
-	return nil
+	// Resume after the checkpoint, otherwise start with the latest page
+	if pos, ok := p.filter.Checkpoint.Position(obj); ok {
+		info.t.lastKey = pos.Key
+		return collector.Rewind(ctx)
+	}
+
+	return collector.Reset(ctx)
 }
 
 func (p *eventProcessor) destroy() {
@@ -93,18 +95,36 @@ func (p *eventProcessor) run(ctx context.Context, tail bool) error {
 		collectors = append(collectors, ref)
 	}
 
+	if p.filter.Checkpoint != nil {
+		// catch up, then read via the scrollable view when the latest page changes
+		for ref := range p.tailers {
+			if err := p.read(ctx, ref); err != nil {
+				return err
+			}
+		}
+
+		if !tail {
+			return nil
+		}
+	}
+
 	c := property.DefaultCollector(p.mgr.Client())
 	props := []string{"latestPage"}
+	var perr error // callback error, stops waiting for updates
 
 	if len(collectors) == 1 {
 		// only one object to follow, don't bother creating a view
-		return property.Wait(ctx, c, collectors[0], props, func(pc []types.PropertyChange) bool {
-			if err := p.process(collectors[0], pc); err != nil {
-				return false
+		err := property.Wait(ctx, c, collectors[0], props, func(pc []types.PropertyChange) bool {
+			if perr = p.process(ctx, collectors[0], pc); perr != nil {
+				return true
 			}
 
 			return !tail
 		})
+		if perr != nil {
+			return perr
+		}
+		return err
 	}
 
 	// create and populate a ListView
@@ -120,23 +140,72 @@ func (p *eventProcessor) run(ctx context.Context, tail bool) error {
 	ref := list.Reference()
 	filter := new(property.WaitFilter).Add(ref, collectors[0].Type, props, list.TraversalSpec())
 
-	return property.WaitForUpdates(ctx, c, filter, func(updates []types.ObjectUpdate) bool {
+	err = property.WaitForUpdates(ctx, c, filter, func(updates []types.ObjectUpdate) bool {
 		for _, update := range updates {
-			if err := p.process(update.Obj, update.ChangeSet); err != nil {
-				return false
+			if perr = p.process(ctx, update.Obj, update.ChangeSet); perr != nil {
+				return true
 			}
 		}
 
 		return !tail
 	})
+	if perr != nil {
+		return perr
+	}
+	return err
+}
+
+// read calls the callback with the events following the collector's scrollable view position, updating the Checkpoint.
+func (p *eventProcessor) read(ctx context.Context, c types.ManagedObjectReference) error {
+	t := p.tailers[c]
+
+	size := p.filter.PageSize
+	if size <= 0 {
+		size = 10
+	}
+
+	for {
+		evs, err := t.collector.ReadNextEvents(ctx, size)
+		if err != nil {
+			return err
+		}
+
+		if len(evs) == 0 {
+			return nil
+		}
+
+		var events []types.BaseEvent
+		for _, e := range evs {
+			if key := e.GetEvent().Key; key > t.t.lastKey {
+				events = append(events, e)
+				t.t.lastKey = key
+			}
+		}
+
+		if len(events) == 0 {
+			continue
+		}
+
+		if err = p.callback(t.obj, events); err != nil {
+			return err
+		}
+
+		if err = p.filter.Checkpoint.update(t.obj, events); err != nil {
+			return err
+		}
+	}
 }
 
-func (p *eventProcessor) process(c types.ManagedObjectReference, pc []types.PropertyChange) error {
+func (p *eventProcessor) process(ctx context.Context, c types.ManagedObjectReference, pc []types.PropertyChange) error {
 	t := p.tailers[c]
 	if t == nil {
 		return fmt.Errorf("unknown collector %s", c.String())
 	}
 
+	if p.filter.Checkpoint != nil {
+		return p.read(ctx, c)
+	}
+
 	for _, u := range pc {
 		evs := t.t.newEvents(u.Val.(types.ArrayOfEvent).Event)
 		if len(evs) == 0 {
@@ -0,0 +1,330 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OpenSink returns a Sink for the given name.
+// An empty name or "-" writes JSON lines to stdout. A udp:// or tcp:// URL sends RFC 5424 syslog messages
+// to the given host:port. An http:// or https:// URL posts to a Webhook.
+// Any other name is a file, where JSON lines are appended.
+func OpenSink(name string) (Sink, error) {
+	if name == "" || name == "-" {
+		return NewJSONSink(os.Stdout), nil
+	}
+
+	if strings.Contains(name, "://") {
+		u, err := url.Parse(name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch u.Scheme {
+		case "udp", "tcp":
+			return NewSyslog(u.Scheme, u.Host)
+		case "http", "https":
+			return &Webhook{URL: name}, nil
+		default:
+			return nil, fmt.Errorf("unsupported sink scheme: %s", u.Scheme)
+		}
+	}
+
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewJSONSink(f), nil
+}
+
+type jsonSink struct {
+	w   io.Writer
+	enc *json.Encoder
+}
+
+// NewJSONSink returns a Sink that writes each Record as a line of JSON.
+// If w is an io.Closer other than os.Stdout, it is closed when the Sink is closed.
+func NewJSONSink(w io.Writer) Sink {
+	return &jsonSink{w: w, enc: json.NewEncoder(w)}
+}
+
+func (s *jsonSink) Write(_ context.Context, records []Record) error {
+	for i := range records {
+		if err := s.enc.Encode(&records[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *jsonSink) Close() error {
+	if c, ok := s.w.(io.Closer); ok && s.w != os.Stdout {
+		return c.Close()
+	}
+	return nil
+}
+
+// Syslog is a Sink that sends each Record as an RFC 5424 message, where the MSG part is the Record encoded as JSON.
+// The TCP transport uses octet counting framing, as described in RFC 6587.
+type Syslog struct {
+	Network  string
+	Address  string
+	Facility int    // Defaults to 1 (user-level messages)
+	Hostname string // Defaults to os.Hostname
+	AppName  string // Defaults to "govmomi"
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslog connects to the syslog server at the given address, where network is "udp" or "tcp".
+func NewSyslog(network, address string) (*Syslog, error) {
+	s := &Syslog{
+		Network:  network,
+		Address:  address,
+		Facility: 1,
+		AppName:  "govmomi",
+	}
+
+	s.Hostname, _ = os.Hostname()
+
+	if err := s.connect(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Syslog) connect() error {
+	conn, err := net.Dial(s.Network, s.Address)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	return nil
+}
+
+// severity maps an event category to a syslog severity.
+func severity(category string) int {
+	switch category {
+	case "error":
+		return 3
+	case "warning":
+		return 4
+	case "user":
+		return 5
+	default:
+		return 6
+	}
+}
+
+// nilValue returns the RFC 5424 NILVALUE if s is empty, otherwise s truncated to n printable characters.
+func nilValue(s string, n int) string {
+	s = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, s)
+
+	if s == "" {
+		return "-"
+	}
+
+	if len(s) > n {
+		s = s[:n]
+	}
+
+	return s
+}
+
+// Format returns the RFC 5424 message for the given Record.
+func (s *Syslog) Format(r *Record) ([]byte, error) {
+	msg, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	// 6876 is the VMware private enterprise number
+	fmt.Fprintf(&buf, "<%d>1 %s %s %s %d %s [event@6876 key=\"%d\" chainId=\"%d\" category=\"%s\"] ",
+		s.Facility*8+severity(r.Category),
+		r.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		nilValue(s.Hostname, 255),
+		nilValue(s.AppName, 48),
+		os.Getpid(),
+		nilValue(r.Type, 32),
+		r.Key, r.ChainID, nilValue(r.Category, 32))
+
+	buf.Write(msg)
+
+	return buf.Bytes(), nil
+}
+
+func (s *Syslog) Write(_ context.Context, records []Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range records {
+		msg, err := s.Format(&records[i])
+		if err != nil {
+			return err
+		}
+
+		if s.Network == "tcp" {
+			msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+		}
+
+		if _, err = s.conn.Write(msg); err != nil {
+			// reconnect and retry once, in case the server closed the connection
+			_ = s.conn.Close()
+			if err = s.connect(); err != nil {
+				return err
+			}
+			if _, err = s.conn.Write(msg); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Syslog) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.conn.Close()
+}
+
+// Webhook is a Sink that sends Records as a JSON array via HTTP POST.
+// Records are sent in batches of up to BatchSize. A batch that fails with a transport error,
+// an HTTP 429 or 5xx status is retried up to MaxRetries times, doubling the Backoff delay each time.
+type Webhook struct {
+	URL        string
+	Client     *http.Client // Defaults to http.DefaultClient
+	Header     http.Header  // Additional request headers, such as Authorization
+	BatchSize  int          // Defaults to 100
+	MaxRetries int          // Defaults to 3
+	Backoff    time.Duration
+}
+
+func (w *Webhook) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+
+	for k, v := range w.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+	_ = res.Body.Close()
+
+	switch {
+	case res.StatusCode < 300:
+		return false, nil
+	case res.StatusCode == http.StatusTooManyRequests, res.StatusCode >= 500:
+		return true, fmt.Errorf("POST %s: %s", w.URL, res.Status)
+	default:
+		return false, fmt.Errorf("POST %s: %s", w.URL, res.Status)
+	}
+}
+
+func (w *Webhook) send(ctx context.Context, records []Record) error {
+	body, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	retries := w.MaxRetries
+	if retries == 0 {
+		retries = 3
+	}
+
+	backoff := w.Backoff
+	if backoff == 0 {
+		backoff = time.Second
+	}
+
+	for i := 0; ; i++ {
+		retry, err := w.post(ctx, body)
+		if err == nil || !retry || i == retries {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+func (w *Webhook) Write(ctx context.Context, records []Record) error {
+	size := w.BatchSize
+	if size <= 0 {
+		size = 100
+	}
+
+	for len(records) != 0 {
+		n := size
+		if n > len(records) {
+			n = len(records)
+		}
+
+		if err := w.send(ctx, records[:n]); err != nil {
+			return err
+		}
+
+		records = records[n:]
+	}
+
+	return nil
+}
+
+func (w *Webhook) Close() error {
+	return nil
+}
@@ -0,0 +1,192 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fault
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/vmware/govmomi/task"
+	"github.com/vmware/govmomi/vapi/rest"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// vapiFaults maps vAPI standard error kinds to the closest vim fault type.
+var vapiFaults = map[string]func() types.BaseMethodFault{
+	"already_exists":               func() types.BaseMethodFault { return new(types.DuplicateName) },
+	"concurrent_change":            func() types.BaseMethodFault { return new(types.ConcurrentAccess) },
+	"internal_server_error":        func() types.BaseMethodFault { return new(types.SystemError) },
+	"invalid_argument":             func() types.BaseMethodFault { return new(types.InvalidArgument) },
+	"not_allowed_in_current_state": func() types.BaseMethodFault { return new(types.InvalidState) },
+	"not_found":                    func() types.BaseMethodFault { return new(types.NotFound) },
+	"resource_busy":                func() types.BaseMethodFault { return new(types.ResourceInUse) },
+	"resource_in_use":              func() types.BaseMethodFault { return new(types.ResourceInUse) },
+	"timed_out":                    func() types.BaseMethodFault { return new(types.Timedout) },
+	"unauthenticated":              func() types.BaseMethodFault { return new(types.NotAuthenticated) },
+	"unauthorized":                 func() types.BaseMethodFault { return new(types.NoPermission) },
+	"unsupported":                  func() types.BaseMethodFault { return new(types.NotSupported) },
+}
+
+// pointer returns a pointer to the given fault value, as soap.Fault.VimFault returns fault values
+// rather than the pointers that implement types.BaseMethodFault.
+func pointer(val interface{}) types.BaseMethodFault {
+	if val == nil {
+		return nil
+	}
+
+	if f, ok := val.(types.BaseMethodFault); ok {
+		return f
+	}
+
+	v := reflect.ValueOf(val)
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+
+	f, _ := p.Interface().(types.BaseMethodFault)
+	return f
+}
+
+// get returns the fault and localized message of a single error, without unwrapping.
+func get(err error) (types.BaseMethodFault, string, bool) {
+	switch e := err.(type) {
+	case task.Error:
+		if e.LocalizedMethodFault == nil {
+			return nil, "", false
+		}
+		return e.Fault(), e.LocalizedMessage, true
+	case *task.Error:
+		return get(*e)
+	case *rest.Error:
+		if f, ok := vapiFaults[e.Kind()]; ok {
+			return f(), strings.Join(e.Messages, "\n"), true
+		}
+		return nil, "", false
+	}
+
+	if soap.IsSoapFault(err) {
+		f := soap.ToSoapFault(err)
+		return pointer(f.VimFault()), f.String, true
+	}
+
+	if soap.IsVimFault(err) {
+		return soap.ToVimFault(err), "", true
+	}
+
+	return nil, "", false
+}
+
+// Get returns the fault and its localized message from an error returned by a vim25/methods function,
+// a task such as object.Task.Wait or a vapi/rest.Client request. Errors are unwrapped via an Unwrap method
+// if needed. Errors from vapi/rest are mapped to the closest vim fault type, such as NotFound for "not_found".
+// A nil fault is returned if err does not contain a fault.
+func Get(err error) (types.BaseMethodFault, string) {
+	for err != nil {
+		if f, msg, ok := get(err); ok {
+			return f, msg
+		}
+
+		u, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = u.Unwrap()
+	}
+
+	return nil, ""
+}
+
+// Message returns the localized message of the fault contained in err, or err.Error() if there is no such message.
+func Message(err error) string {
+	if _, msg := Get(err); msg != "" {
+		return msg
+	}
+
+	if err == nil {
+		return ""
+	}
+
+	return err.Error()
+}
+
+// inherits returns the given fault, or the fault it inherits from, that is of the given type.
+// Faults inherit by embedding the parent fault as the first field, for example FileNotFound embeds FileFault.
+func inherits(fault types.BaseMethodFault, kind reflect.Type) (reflect.Value, bool) {
+	v := reflect.ValueOf(fault)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return v, false
+	}
+
+	for v = v.Elem(); v.Kind() == reflect.Struct; v = v.Field(0) {
+		if v.Type() == kind {
+			return v.Addr(), true
+		}
+
+		if v.NumField() == 0 || !v.Type().Field(0).Anonymous {
+			break
+		}
+	}
+
+	return v, false
+}
+
+// As finds the fault contained in err that matches target, and if so, sets target to that fault value and returns true.
+// The target must be a non-nil pointer to a fault pointer type, such as **types.FileFault,
+// or a pointer to an interface type implemented by faults, such as *types.BaseFileFault.
+// A fault matches if it is the target type or inherits from it, for example a FileNotFound fault matches *types.FileFault.
+func As(err error, target interface{}) bool {
+	t := reflect.ValueOf(target)
+	if t.Kind() != reflect.Ptr || t.IsNil() {
+		panic("fault: target must be a non-nil pointer")
+	}
+
+	fault, _ := Get(err)
+	if fault == nil {
+		return false
+	}
+
+	kind := t.Type().Elem()
+
+	switch kind.Kind() {
+	case reflect.Interface:
+		if reflect.TypeOf(fault).Implements(kind) {
+			t.Elem().Set(reflect.ValueOf(fault))
+			return true
+		}
+	case reflect.Ptr:
+		if v, ok := inherits(fault, kind.Elem()); ok {
+			t.Elem().Set(v)
+			return true
+		}
+	default:
+		panic("fault: target must be a pointer to a fault pointer or interface type")
+	}
+
+	return false
+}
+
+// Is returns true if err contains a fault of the same type as target, or a fault that inherits from target's type.
+// For example: fault.Is(err, &types.DuplicateName{}) or fault.Is(err, new(types.FileFault))
+func Is(err error, target types.BaseMethodFault) bool {
+	fault, _ := Get(err)
+	if fault == nil || target == nil {
+		return false
+	}
+
+	_, ok := inherits(fault, reflect.TypeOf(target).Elem())
+	return ok
+}
@@ -0,0 +1,182 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/task"
+	"github.com/vmware/govmomi/vapi/rest"
+	vapi "github.com/vmware/govmomi/vapi/simulator"
+	"github.com/vmware/govmomi/vapi/tags"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type wrapped struct {
+	error
+}
+
+func (w wrapped) Unwrap() error {
+	return w.error
+}
+
+func TestGet(t *testing.T) {
+	sf := &soap.Fault{String: "busy"}
+	sf.Detail.Fault = types.TaskInProgress{}
+
+	tests := []struct {
+		err   error
+		fault types.BaseMethodFault
+		msg   string
+	}{
+		{nil, nil, ""},
+		{errors.New("other"), nil, ""},
+		{soap.WrapSoapFault(sf), new(types.TaskInProgress), "busy"},
+		{soap.WrapVimFault(new(types.FileNotFound)), new(types.FileNotFound), ""},
+		{task.Error{LocalizedMethodFault: &types.LocalizedMethodFault{
+			Fault:            new(types.DuplicateName),
+			LocalizedMessage: "The name 'foo' already exists.",
+		}}, new(types.DuplicateName), "The name 'foo' already exists."},
+		{wrapped{soap.WrapSoapFault(sf)}, new(types.TaskInProgress), "busy"},
+	}
+
+	for i, test := range tests {
+		fault, msg := Get(test.err)
+		if fmt.Sprintf("%T", fault) != fmt.Sprintf("%T", test.fault) || msg != test.msg {
+			t.Errorf("%d: fault=%T, msg=%q", i, fault, msg)
+		}
+
+		if test.err != nil && Message(test.err) == "" {
+			t.Errorf("%d: empty message", i)
+		}
+	}
+}
+
+func TestAs(t *testing.T) {
+	err := wrapped{soap.WrapVimFault(&types.FileNotFound{FileFault: types.FileFault{File: "foo.vmdk"}})}
+
+	var ff *types.FileFault
+	if !As(err, &ff) || ff.File != "foo.vmdk" {
+		t.Errorf("ff=%#v", ff)
+	}
+
+	var fnf *types.FileNotFound
+	if !As(err, &fnf) {
+		t.Error("expected FileNotFound")
+	}
+
+	var base types.BaseFileFault
+	if !As(err, &base) || base.GetFileFault().File != "foo.vmdk" {
+		t.Errorf("base=%#v", base)
+	}
+
+	var dn *types.DuplicateName
+	if As(err, &dn) || As(errors.New("other"), &ff) {
+		t.Error("unexpected match")
+	}
+
+	if !Is(err, new(types.VimFault)) || !Is(err, new(types.FileFault)) || Is(err, new(types.FileAlreadyExists)) {
+		t.Error("Is")
+	}
+}
+
+func TestSimulator(t *testing.T) {
+	ctx := context.Background()
+
+	m := simulator.VPX()
+	defer m.Remove()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service.NewServer()
+	defer s.Close()
+
+	path, handler := vapi.New(s.URL, nil)
+	m.Service.ServeMux.Handle(path, handler)
+
+	c, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	finder := find.NewFinder(c.Client, true)
+
+	dc, err := finder.DefaultDatacenter(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	finder.SetDatacenter(dc)
+
+	// method fault
+	pool, err := finder.ResourcePool(ctx, "DC0_C0/Resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err = pool.Create(ctx, "dup", types.DefaultResourceConfigSpec())
+	}
+	if !Is(err, &types.DuplicateName{}) {
+		t.Errorf("err=%#v", err)
+	}
+
+	// task fault
+	vm, err := finder.VirtualMachine(ctx, "DC0_H0_VM0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ptask, err := vm.PowerOn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ptask.Wait(ctx)
+	if !Is(err, &types.InvalidState{}) {
+		t.Errorf("err=%#v", err)
+	}
+
+	var ps *types.InvalidPowerState
+	if !As(err, &ps) || ps.ExistingState != types.VirtualMachinePowerStatePoweredOn {
+		t.Errorf("ps=%#v", ps)
+	}
+
+	// vapi error
+	r := rest.NewClient(c.Client)
+	if err = r.Login(ctx, s.URL.User); err != nil {
+		t.Fatal(err)
+	}
+
+	tm := tags.NewManager(r)
+	for i := 0; i < 2; i++ {
+		_, err = tm.CreateCategory(ctx, &tags.Category{Name: "dup"})
+	}
+
+	if !Is(err, &types.DuplicateName{}) {
+		t.Errorf("err=%#v", err)
+	}
+}
@@ -104,6 +104,18 @@ func clientLogout(ctx context.Context, cmd Command) error {
 	return nil
 }
 
+// registerOutputAliases registers the -format, -template and -fields aliases of the
+// -output.* flags, for commands that do not define flags with the same name.
+func registerOutputAliases(fs *flag.FlagSet) {
+	for _, name := range []string{"format", "template", "fields"} {
+		f := fs.Lookup("output." + name)
+		if f == nil || fs.Lookup(name) != nil {
+			continue
+		}
+		fs.Var(f.Value, name, "Alias for -"+f.Name)
+	}
+}
+
 func Run(args []string) int {
 	hw := os.Stderr
 	rc := 1
@@ -144,6 +156,7 @@ func Run(args []string) int {
 	}
 
 	cmd.Register(ctx, fs)
+	registerOutputAliases(fs)
 
 	if err = fs.Parse(args[1:]); err != nil {
 		goto error
@@ -124,6 +124,10 @@ type infoResult struct {
 	objects    []*object.Datastore
 }
 
+func (r *infoResult) Columns() []string {
+	return []string{"name", "summary.type", "summary.capacity", "summary.freeSpace"}
+}
+
 func (r *infoResult) Write(w io.Writer) error {
 	// Maintain order via r.objects as Property collector does not always return results in order.
 	objects := make(map[types.ManagedObjectReference]mo.Datastore, len(r.Datastores))
@@ -35,11 +35,19 @@ import (
 type events struct {
 	*flags.DatacenterFlag
 
-	Max   int32
-	Tail  bool
-	Force bool
-	Long  bool
-	Kind  kinds
+	Max        int32
+	Tail       bool
+	Force      bool
+	Long       bool
+	Kind       kinds
+	Category   kinds
+	User       kinds
+	Chain      int32
+	Since      since
+	Until      since
+	Checkpoint string
+	Format     string
+	Sink       string
 }
 
 type kinds []string
@@ -53,6 +61,31 @@ func (e *kinds) Set(value string) error {
 	return nil
 }
 
+// since accepts an RFC3339 timestamp or a duration relative to now, such as "1h" for 1 hour ago.
+type since struct {
+	t *time.Time
+}
+
+func (s *since) String() string {
+	if s.t == nil {
+		return ""
+	}
+	return s.t.Format(time.RFC3339)
+}
+
+func (s *since) Set(value string) error {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		d, derr := time.ParseDuration(value)
+		if derr != nil {
+			return fmt.Errorf("invalid time %q: expected RFC3339 timestamp or duration", value)
+		}
+		t = time.Now().Add(-d)
+	}
+	s.t = &t
+	return nil
+}
+
 func init() {
 	// initialize with the maximum allowed objects set
 	cli.Register("events", &events{})
@@ -68,6 +101,14 @@ func (cmd *events) Register(ctx context.Context, f *flag.FlagSet) {
 	f.BoolVar(&cmd.Force, "force", false, "Disable number objects to monitor limit")
 	f.BoolVar(&cmd.Long, "l", false, "Long listing format")
 	f.Var(&cmd.Kind, "type", "Include only the specified event types")
+	f.Var(&cmd.Category, "category", "Include only the specified event categories (info, warning, error, user)")
+	f.Var(&cmd.User, "user", "Include only events logged by the specified users")
+	f.Var(flags.NewInt32(&cmd.Chain), "chain", "Include only events with the specified chain ID")
+	f.Var(&cmd.Since, "since", "Include only events created after TIME (RFC3339 or duration ago)")
+	f.Var(&cmd.Until, "until", "Include only events created before TIME (RFC3339 or duration ago)")
+	f.StringVar(&cmd.Checkpoint, "checkpoint", "", "Resume from and record the last event seen in FILE")
+	f.StringVar(&cmd.Format, "format", "", "Export format (jsonl)")
+	f.StringVar(&cmd.Sink, "sink", "", "Export destination: FILE, udp://HOST:PORT, tcp://HOST:PORT (syslog) or http(s)://URL (webhook)")
 }
 
 func (cmd *events) Description() string {
@@ -77,6 +118,14 @@ Examples:
   govc events vm/my-vm1 vm/my-vm2
   govc events /dc1/vm/* /dc2/vm/*
   govc events -type VmPoweredOffEvent -type VmPoweredOnEvent
+  govc events -since 1h -category error -category warning
+  govc events -since 2018-10-01T00:00:00Z -until 2018-10-02T00:00:00Z -user root
+  govc events -chain 42 vm/my-vm1
+  govc events -f -checkpoint events.json vm/my-vm1
+  govc events -format jsonl -f
+  govc events -format jsonl -sink events.jsonl -f
+  govc events -format jsonl -sink udp://syslog.example.com:514 -f
+  govc events -format jsonl -sink https://audit.example.com/events -f -checkpoint events.json
   govc ls -t HostSystem host/* | xargs govc events | grep -i vsan`
 }
 
@@ -89,7 +138,7 @@ func (cmd *events) printEvents(ctx context.Context, obj *types.ManagedObjectRefe
 	source := ""
 	if obj != nil {
 		source = obj.String()
-		if !cmd.JSON {
+		if !cmd.All() {
 			// print the object reference
 			fmt.Fprintf(os.Stdout, "\n==> %s <==\n", source)
 		}
@@ -154,6 +203,16 @@ func (r *record) Write(w io.Writer) error {
 }
 
 func (cmd *events) Run(ctx context.Context, f *flag.FlagSet) error {
+	switch cmd.Format {
+	case "":
+		if cmd.Sink != "" {
+			return flag.ErrHelp
+		}
+	case "jsonl":
+	default:
+		return fmt.Errorf("unsupported format: %s", cmd.Format)
+	}
+
 	c, err := cmd.Client()
 	if err != nil {
 		return err
@@ -166,8 +225,42 @@ func (cmd *events) Run(ctx context.Context, f *flag.FlagSet) error {
 
 	m := event.NewManager(c)
 
+	filter := event.Filter{
+		Objects:  objs,
+		Type:     cmd.Kind,
+		Category: cmd.Category,
+		UserName: cmd.User,
+		ChainID:  cmd.Chain,
+		Begin:    cmd.Since.t,
+		End:      cmd.Until.t,
+		PageSize: cmd.Max,
+		Tail:     cmd.Tail,
+		Force:    cmd.Force,
+	}
+
+	if cmd.Checkpoint != "" {
+		filter.Checkpoint, err = event.NewCheckpoint(cmd.Checkpoint)
+		if err != nil {
+			return err
+		}
+	}
+
+	if cmd.Format == "jsonl" {
+		sink, err := event.OpenSink(cmd.Sink)
+		if err != nil {
+			return err
+		}
+		defer sink.Close()
+
+		e := event.NewExporter(m, sink)
+
+		return cmd.WithCancel(ctx, func(wctx context.Context) error {
+			return e.Stream(wctx, filter)
+		})
+	}
+
 	return cmd.WithCancel(ctx, func(wctx context.Context) error {
-		return m.Events(wctx, objs, cmd.Max, cmd.Tail, cmd.Force,
+		return m.Stream(wctx, filter,
 			func(obj types.ManagedObjectReference, ee []types.BaseEvent) error {
 				var o *types.ManagedObjectReference
 				if len(objs) > 1 {
@@ -175,6 +268,6 @@ func (cmd *events) Run(ctx context.Context, f *flag.FlagSet) error {
 				}
 
 				return cmd.printEvents(ctx, o, ee, m)
-			}, cmd.Kind...)
+			})
 	})
 }
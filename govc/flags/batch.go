@@ -0,0 +1,378 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/task"
+	"github.com/vmware/govmomi/vim25/progress"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// BatchFlag runs an action against a batch of objects, optionally in parallel,
+// aggregating progress and writing a summary of the results.
+type BatchFlag struct {
+	common
+
+	*OutputFlag
+
+	Parallel  int
+	KeepGoing bool
+
+	once   sync.Once
+	waiter *task.Waiter
+}
+
+var batchFlagKey = flagKey("batch")
+
+func NewBatchFlag(ctx context.Context) (*BatchFlag, context.Context) {
+	if v := ctx.Value(batchFlagKey); v != nil {
+		return v.(*BatchFlag), ctx
+	}
+
+	v := &BatchFlag{}
+	v.OutputFlag, ctx = NewOutputFlag(ctx)
+	ctx = context.WithValue(ctx, batchFlagKey, v)
+	return v, ctx
+}
+
+func (flag *BatchFlag) Register(ctx context.Context, f *flag.FlagSet) {
+	flag.RegisterOnce(func() {
+		flag.OutputFlag.Register(ctx, f)
+
+		f.IntVar(&flag.Parallel, "parallel", 1, "Number of objects to process in parallel")
+		f.BoolVar(&flag.KeepGoing, "keep-going", false, "Continue with the remaining objects after an error")
+	})
+}
+
+func (flag *BatchFlag) Process(ctx context.Context) error {
+	return flag.ProcessOnce(func() error {
+		if err := flag.OutputFlag.Process(ctx); err != nil {
+			return err
+		}
+
+		if flag.Parallel < 1 {
+			return errors.New("-parallel must be at least 1")
+		}
+
+		return nil
+	})
+}
+
+// Batch returns true if -parallel or -keep-going is set, in which case RunBatch aggregates progress
+// and writes a summary, rather than logging the progress of each object in turn.
+// The output flags alone do not enable batch mode, so that commands keep their existing -json output.
+func (flag *BatchFlag) Batch() bool {
+	return flag.Parallel > 1 || flag.KeepGoing
+}
+
+// WaitForResult waits for the given task to finish, sending progress to s.
+// All tasks of a batch are waited for using a single task.Waiter, rather than a property filter per task.
+func (flag *BatchFlag) WaitForResult(ctx context.Context, t *object.Task, s progress.Sinker) (*types.TaskInfo, error) {
+	flag.once.Do(func() {
+		flag.waiter = task.NewWaiter(t.Client())
+	})
+
+	return flag.waiter.WaitForResult(ctx, t.Reference(), s)
+}
+
+// BatchItem is an action to run against a single object of a batch.
+type BatchItem struct {
+	// Name identifies the object in progress reports and the summary.
+	Name string
+
+	// Message is the progress logger prefix used when not in batch mode.
+	// If empty, progress is not logged for this object.
+	Message string
+
+	// Run performs the action, passing s to WaitForResult for at most one task.
+	// The sinker may be nil.
+	Run func(ctx context.Context, s progress.Sinker) error
+}
+
+// IgnoredError can be returned by a BatchItem to report an error that does not fail the batch,
+// such as a task error when -force is set. The summary lists the item with status "ignored".
+type IgnoredError struct {
+	Err error
+}
+
+func (e *IgnoredError) Error() string {
+	return e.Err.Error()
+}
+
+func isIgnored(err error) bool {
+	_, ok := err.(*IgnoredError)
+	return ok
+}
+
+// BatchResult is the outcome of a BatchItem.
+type BatchResult struct {
+	Name    string  `json:"name"`
+	Status  string  `json:"status"`
+	Error   string  `json:"error,omitempty"`
+	Seconds float64 `json:"seconds"`
+}
+
+type batchResult struct {
+	Results []BatchResult `json:"results"`
+}
+
+func (r *batchResult) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 2, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Name\tStatus\tDuration\tError\n")
+
+	for _, res := range r.Results {
+		d := time.Duration(res.Seconds * float64(time.Second)).Round(time.Millisecond)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", res.Name, res.Status, d, res.Error)
+	}
+
+	return tw.Flush()
+}
+
+// RunBatch runs the given items.
+// When not in batch mode, items run one at a time and the first error is returned.
+// An IgnoredError is reported in the summary, but is not counted as a failure.
+// Otherwise, up to -parallel items run at once, with progress aggregated into a single status line.
+// Unless -keep-going is set, no more items are started after an error.
+// A summary is written once all items have finished, and an error is returned if any item failed.
+func (flag *BatchFlag) RunBatch(ctx context.Context, items []BatchItem) error {
+	if !flag.Batch() {
+		for _, item := range items {
+			var logger *progressLogger
+			var s progress.Sinker
+
+			if item.Message != "" {
+				logger = flag.ProgressLogger(item.Message)
+				s = logger
+			}
+
+			err := item.Run(ctx, s)
+
+			if logger != nil {
+				logger.Wait()
+			}
+
+			if err != nil && !isIgnored(err) {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	status := newBatchStatus(flag.OutputFlag, len(items))
+	agg := progress.NewAggregator(status)
+
+	results := make([]BatchResult, len(items))
+	for i, item := range items {
+		results[i] = BatchResult{Name: item.Name, Status: "skipped"}
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, flag.Parallel)
+
+	for i, item := range items {
+		if status.stop(flag.KeepGoing) {
+			break
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil || status.stop(flag.KeepGoing) {
+			break
+		}
+
+		status.start()
+		wg.Add(1)
+
+		go func(i int, item BatchItem) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			s := &batchSinker{agg: agg, prefix: item.Name}
+			start := time.Now()
+			err := item.Run(ctx, s)
+			s.wg.Wait()
+
+			results[i].Seconds = time.Since(start).Seconds()
+			switch {
+			case err == nil:
+				results[i].Status = "ok"
+			case isIgnored(err):
+				results[i].Status = "ignored"
+				results[i].Error = err.Error()
+				err = nil
+			default:
+				results[i].Status = "failed"
+				results[i].Error = err.Error()
+			}
+
+			status.finish(err)
+		}(i, item)
+	}
+
+	wg.Wait()
+	agg.Done()
+	status.wait()
+
+	if err := flag.WriteResult(&batchResult{results}); err != nil {
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if n := status.failures(); n != 0 {
+		return fmt.Errorf("%d of %d failed", n, len(items))
+	}
+
+	return nil
+}
+
+// batchSinker forwards each progress report of a single item to the Aggregator
+// via a short-lived sink, as the Aggregator drains one sink at a time.
+type batchSinker struct {
+	agg    *progress.Aggregator
+	prefix string
+	wg     sync.WaitGroup
+}
+
+func (s *batchSinker) Sink() chan<- progress.Report {
+	ch := make(chan progress.Report)
+	upstream := progress.Prefix(progress.SinkFunc(s.agg.Sink), s.prefix)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for r := range ch {
+			downstream := upstream.Sink()
+			downstream <- r
+			close(downstream)
+		}
+	}()
+
+	return ch
+}
+
+// batchStatus counts the items of a batch and logs a single status line,
+// along with the latest progress report from any item.
+type batchStatus struct {
+	flag  *OutputFlag
+	total int
+
+	mu      sync.Mutex
+	running int
+	done    int
+	failed  int
+
+	wg sync.WaitGroup
+}
+
+func newBatchStatus(flag *OutputFlag, total int) *batchStatus {
+	return &batchStatus{flag: flag, total: total}
+}
+
+func (s *batchStatus) Sink() chan<- progress.Report {
+	ch := make(chan progress.Report)
+
+	s.wg.Add(1)
+	go s.loop(ch)
+
+	return ch
+}
+
+func (s *batchStatus) loop(ch <-chan progress.Report) {
+	defer s.wg.Done()
+
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+
+	var r progress.Report
+
+	for {
+		select {
+		case report, ok := <-ch:
+			if !ok {
+				s.flag.Log("\r" + s.line(nil) + "\n")
+				return
+			}
+			r = report
+		case <-tick.C:
+			s.flag.Log("\r" + s.line(r))
+		}
+	}
+}
+
+func (s *batchStatus) line(r progress.Report) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	line := fmt.Sprintf("%d/%d done, %d failed, %d running", s.done, s.total, s.failed, s.running)
+
+	if r != nil {
+		line += fmt.Sprintf(" (%s %.0f%%)", r.Detail(), r.Percentage())
+	}
+
+	return line
+}
+
+func (s *batchStatus) start() {
+	s.mu.Lock()
+	s.running++
+	s.mu.Unlock()
+}
+
+func (s *batchStatus) finish(err error) {
+	s.mu.Lock()
+	s.running--
+	s.done++
+	if err != nil {
+		s.failed++
+	}
+	s.mu.Unlock()
+}
+
+// stop returns true if no more items should be started.
+func (s *batchStatus) stop(keepGoing bool) bool {
+	return !keepGoing && s.failures() != 0
+}
+
+func (s *batchStatus) failures() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.failed
+}
+
+func (s *batchStatus) wait() {
+	s.wg.Wait()
+}
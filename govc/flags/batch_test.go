@@ -0,0 +1,185 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/progress"
+)
+
+type batchReport struct {
+	pct float32
+}
+
+func (r batchReport) Percentage() float32 { return r.pct }
+func (r batchReport) Detail() string      { return "" }
+func (r batchReport) Error() error        { return nil }
+
+func newTestBatchFlag(parallel int, keepGoing bool) (*BatchFlag, *bytes.Buffer) {
+	var out bytes.Buffer
+
+	flag := &BatchFlag{
+		OutputFlag: &OutputFlag{Out: &out, JSON: true},
+		Parallel:   parallel,
+		KeepGoing:  keepGoing,
+	}
+
+	return flag, &out
+}
+
+func batchItems(n int, fail map[int]bool, running, max *int32) []BatchItem {
+	items := make([]BatchItem, n)
+
+	for i := range items {
+		i := i
+
+		items[i] = BatchItem{
+			Name: string('a' + rune(i)),
+			Run: func(ctx context.Context, s progress.Sinker) error {
+				n := atomic.AddInt32(running, 1)
+				defer atomic.AddInt32(running, -1)
+
+				for {
+					m := atomic.LoadInt32(max)
+					if n <= m || atomic.CompareAndSwapInt32(max, m, n) {
+						break
+					}
+				}
+
+				ch := s.Sink()
+				ch <- batchReport{50}
+				time.Sleep(10 * time.Millisecond)
+				close(ch)
+
+				if fail[i] {
+					return errors.New("failed")
+				}
+
+				return nil
+			},
+		}
+	}
+
+	return items
+}
+
+func TestRunBatch(t *testing.T) {
+	tests := []struct {
+		parallel  int
+		keepGoing bool
+		fail      map[int]bool
+		status    string
+		err       bool
+	}{
+		{4, false, nil, "ok,ok,ok,ok,ok,ok,ok,ok", false},
+		{1, true, map[int]bool{2: true}, "ok,ok,failed,ok,ok,ok,ok,ok", true},
+		{2, false, map[int]bool{0: true, 1: true}, "failed,failed,skipped,skipped,skipped,skipped,skipped,skipped", true},
+		{8, true, map[int]bool{0: true, 7: true}, "failed,ok,ok,ok,ok,ok,ok,failed", true},
+	}
+
+	for i, test := range tests {
+		var running, max int32
+
+		flag, out := newTestBatchFlag(test.parallel, test.keepGoing)
+
+		err := flag.RunBatch(context.Background(), batchItems(8, test.fail, &running, &max))
+		if (err != nil) != test.err {
+			t.Errorf("%d: err=%v", i, err)
+		}
+
+		if int(max) > test.parallel {
+			t.Errorf("%d: %d running, parallel=%d", i, max, test.parallel)
+		}
+
+		var res batchResult
+		if err = json.NewDecoder(out).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		var status []string
+		for _, r := range res.Results {
+			status = append(status, r.Status)
+		}
+
+		if s := strings.Join(status, ","); s != test.status {
+			t.Errorf("%d: status=%s, expected %s", i, s, test.status)
+		}
+	}
+}
+
+func TestRunBatchSequential(t *testing.T) {
+	var running, max int32
+
+	flag, out := newTestBatchFlag(1, false) // -json alone does not enable batch mode
+
+	items := batchItems(3, map[int]bool{1: true}, &running, &max)
+	for i := range items {
+		items[i].Message = "running..."
+	}
+
+	err := flag.RunBatch(context.Background(), items)
+	if err == nil || err.Error() != "failed" {
+		t.Errorf("err=%v", err)
+	}
+
+	if out.Len() != 0 {
+		t.Errorf("unexpected summary: %s", out)
+	}
+}
+
+func TestRunBatchIgnored(t *testing.T) {
+	for _, parallel := range []int{1, 2} {
+		flag, out := newTestBatchFlag(parallel, false)
+
+		items := []BatchItem{
+			{Name: "a", Run: func(context.Context, progress.Sinker) error { return nil }},
+			{Name: "b", Run: func(context.Context, progress.Sinker) error { return &IgnoredError{errors.New("task failed")} }},
+			{Name: "c", Run: func(context.Context, progress.Sinker) error { return nil }},
+		}
+
+		err := flag.RunBatch(context.Background(), items)
+		if err != nil {
+			t.Errorf("parallel=%d: err=%v", parallel, err)
+		}
+
+		if !flag.Batch() {
+			continue // no summary
+		}
+
+		var res batchResult
+		if err = json.NewDecoder(out).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		r := res.Results[1]
+		if r.Status != "ignored" || r.Error != "task failed" {
+			t.Errorf("result=%#v", r)
+		}
+
+		if res.Results[2].Status != "ok" {
+			t.Errorf("result=%#v", res.Results[2])
+		}
+	}
+}
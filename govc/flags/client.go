@@ -34,6 +34,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/vmware/govmomi/pbm"
 	"github.com/vmware/govmomi/session"
 	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/soap"
@@ -548,6 +549,16 @@ func (flag *ClientFlag) Client() (*vim25.Client, error) {
 	return flag.client, nil
 }
 
+// PbmClient returns a pbm.Client using the current session.
+func (flag *ClientFlag) PbmClient(ctx context.Context) (*pbm.Client, error) {
+	c, err := flag.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	return pbm.NewClient(ctx, c)
+}
+
 func (flag *ClientFlag) Logout(ctx context.Context) error {
 	if flag.persist || flag.client == nil {
 		return nil
@@ -0,0 +1,433 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+)
+
+// jsonMember is a field of a jsonObject.
+type jsonMember struct {
+	Key   string
+	Value interface{}
+}
+
+// jsonObject is a JSON object that maintains field order, for use with -output.fields and the yaml, table and csv formats.
+type jsonObject []jsonMember
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(m.Key)
+		val, err := json.Marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+var xmlInvalidName = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+func (o jsonObject) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, m := range o {
+		name := strings.Trim(xmlInvalidName.ReplaceAllString(m.Key, "_"), "_")
+		if err := e.EncodeElement(m.Value, xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// normalize converts val to its JSON representation: a jsonObject, []interface{}, string, json.Number, bool or nil.
+func normalize(val interface{}) (interface{}, error) {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	return decodeValue(dec)
+}
+
+func decodeValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		obj := jsonObject{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			val, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonMember{key.(string), val})
+		}
+		_, err = dec.Token() // '}'
+		return obj, err
+	case json.Delim('['):
+		list := []interface{}{}
+		for dec.More() {
+			val, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, val)
+		}
+		_, err = dec.Token() // ']'
+		return list, err
+	default:
+		return tok, nil
+	}
+}
+
+// outputItems returns the objects contained in an OutputWriter result, such as the VirtualMachines field of vm.info,
+// or the result itself if it does not contain a list.
+func outputItems(result interface{}) []interface{} {
+	rval := reflect.Indirect(reflect.ValueOf(result))
+
+	if rval.Kind() == reflect.Struct {
+		rtype := rval.Type()
+		for i := 0; i < rtype.NumField(); i++ {
+			field := rtype.Field(i)
+			if field.PkgPath != "" || field.Anonymous || field.Tag.Get("json") == "-" {
+				continue
+			}
+			if field.Type.Kind() == reflect.Slice {
+				rval = rval.Field(i)
+				break
+			}
+		}
+	}
+
+	if rval.Kind() != reflect.Slice {
+		return []interface{}{result}
+	}
+
+	items := make([]interface{}, rval.Len())
+	for i := range items {
+		items[i] = rval.Index(i).Interface()
+	}
+
+	return items
+}
+
+// lookup returns the value of the given field path, such as "config.hardware.numCPU" or "guest.net[0].ipAddress".
+// Field names are case insensitive.  A path element applied to a list is applied to each element of the list,
+// as is the "[*]" index.
+func lookup(val interface{}, path []string) interface{} {
+	if len(path) == 0 || val == nil {
+		return val
+	}
+
+	name := path[0]
+	index := ""
+	if i := strings.IndexByte(name, '['); i >= 0 && strings.HasSuffix(name, "]") {
+		name, index = name[:i], name[i+1:len(name)-1]
+	}
+
+	if list, ok := val.([]interface{}); ok && name != "" {
+		var res []interface{}
+		for _, item := range list {
+			if v := lookup(item, path); v != nil {
+				res = append(res, v)
+			}
+		}
+		return res
+	}
+
+	if name != "" {
+		obj, ok := val.(jsonObject)
+		if !ok {
+			return nil
+		}
+		val = nil
+		for _, m := range obj {
+			if strings.EqualFold(m.Key, name) {
+				val = m.Value
+				break
+			}
+		}
+	}
+
+	if index == "" {
+		return lookup(val, path[1:])
+	}
+
+	list, ok := val.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	if index == "*" {
+		if len(path) == 1 {
+			return list
+		}
+		return lookup(list, path[1:])
+	}
+
+	i, err := strconv.Atoi(index)
+	if err != nil || i < 0 || i >= len(list) {
+		return nil
+	}
+
+	return lookup(list[i], path[1:])
+}
+
+// project returns an object for each item, containing only the given fields.
+func project(items []interface{}, fields []string) ([]interface{}, error) {
+	res := make([]interface{}, len(items))
+
+	for i, item := range items {
+		val, err := normalize(item)
+		if err != nil {
+			return nil, err
+		}
+
+		obj := make(jsonObject, len(fields))
+		for j, field := range fields {
+			path := strings.Split(strings.TrimPrefix(strings.TrimPrefix(field, "$"), "."), ".")
+			obj[j] = jsonMember{field, lookup(val, path)}
+		}
+		res[i] = obj
+	}
+
+	return res, nil
+}
+
+// columns returns the fields to display for the given items in table or csv format, if not specified via -output.fields
+// or OutputColumns.  These are the scalar fields of the first item.
+func columns(items []interface{}) []string {
+	var fields []string
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	if obj, ok := items[0].(jsonObject); ok {
+		for _, m := range obj {
+			switch m.Value.(type) {
+			case jsonObject, []interface{}:
+			default:
+				fields = append(fields, m.Key)
+			}
+		}
+	}
+
+	return fields
+}
+
+// cell returns the string representation of a normalized value for use in a table or csv row.
+func cell(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		s := make([]string, len(v))
+		for i := range v {
+			s[i] = cell(v[i])
+		}
+		return strings.Join(s, ",")
+	case jsonObject:
+		b, _ := json.Marshal(v)
+		return string(b)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (flag *OutputFlag) writeFormat(result OutputWriter) error {
+	var fields []string
+	if flag.Fields != "" {
+		fields = strings.Split(flag.Fields, ",")
+	}
+
+	var val interface{}
+	var items []interface{}
+	var err error
+
+	switch flag.Format {
+	case "json", "yaml", "xml":
+		if fields == nil {
+			if flag.Format == "xml" {
+				items = outputItems(result)
+				break
+			}
+			val, err = normalize(result)
+			break
+		}
+		fallthrough
+	case "", "table", "csv":
+		items = outputItems(result)
+		if c, ok := result.(OutputColumns); ok && fields == nil {
+			fields = c.Columns()
+		}
+		if fields == nil {
+			for i := range items {
+				if items[i], err = normalize(items[i]); err != nil {
+					return err
+				}
+			}
+			fields = columns(items)
+		}
+		items, err = project(items, fields)
+		val = items
+	default:
+		return fmt.Errorf("unsupported format: %s", flag.Format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	switch flag.Format {
+	case "json":
+		return json.NewEncoder(flag.Out).Encode(val)
+	case "yaml":
+		return writeYAML(flag.Out, val)
+	case "xml":
+		return writeXML(flag.Out, items)
+	case "csv":
+		return writeCSV(flag.Out, fields, items)
+	default:
+		return writeTable(flag.Out, fields, items)
+	}
+}
+
+func writeTable(w io.Writer, fields []string, items []interface{}) error {
+	tw := tabwriter.NewWriter(w, 2, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(fields, "\t")))
+
+	for _, item := range items {
+		row := make([]string, len(fields))
+		for i, m := range item.(jsonObject) {
+			row[i] = cell(m.Value)
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func writeCSV(w io.Writer, fields []string, items []interface{}) error {
+	cw := csv.NewWriter(w)
+
+	_ = cw.Write(fields)
+
+	for _, item := range items {
+		row := make([]string, len(fields))
+		for i, m := range item.(jsonObject) {
+			row[i] = cell(m.Value)
+		}
+		_ = cw.Write(row)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeXML(w io.Writer, items []interface{}) error {
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	start := xml.StartElement{Name: xml.Name{Local: "result"}}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if err := enc.EncodeElement(item, xml.StartElement{Name: xml.Name{Local: "item"}}); err != nil {
+			return err
+		}
+	}
+
+	if err := enc.EncodeToken(start.End()); err != nil {
+		return err
+	}
+
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(val interface{}) (string, error) {
+		b, err := json.Marshal(val)
+		return string(b), err
+	},
+	"join": strings.Join,
+}
+
+// writeTemplate executes the given text/template for each of the result's items.
+func writeTemplate(w io.Writer, text string, result OutputWriter) error {
+	t, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range outputItems(result) {
+		if err = t.Execute(w, item); err != nil {
+			return err
+		}
+		if !strings.HasSuffix(text, "\n") {
+			fmt.Fprintln(w)
+		}
+	}
+
+	return nil
+}
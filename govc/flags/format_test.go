@@ -0,0 +1,135 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+type formatResult struct {
+	Items []formatItem
+}
+
+type formatItem struct {
+	Name   string
+	Config struct {
+		CPU  int `json:"numCPU"`
+		Tags []string
+	}
+	Nics []struct {
+		Mac string
+	}
+}
+
+type formatColumns struct {
+	Items []formatItem
+}
+
+func (r *formatColumns) Columns() []string {
+	return []string{"config.numCPU", "name"}
+}
+
+func (r *formatColumns) Write(io.Writer) error {
+	return nil
+}
+
+func TestOutputFormat(t *testing.T) {
+	a := formatItem{Name: "vm-a"}
+	a.Config.CPU = 2
+	a.Config.Tags = []string{"x", "y"}
+	a.Nics = append(a.Nics, struct{ Mac string }{"00:01"}, struct{ Mac string }{"00:02"})
+
+	b := formatItem{Name: "true"}
+
+	items := outputItems(&formatResult{Items: []formatItem{a, b}})
+	if len(items) != 2 {
+		t.Fatalf("items=%d", len(items))
+	}
+
+	fields := []string{"name", "config.numcpu", "$.config.tags", "nics.mac", "nics[1].mac", "nics[*].mac", "enoent"}
+
+	res, err := project(items, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []string{"vm-a", "2", "x,y", "00:01,00:02", "00:02", "00:01,00:02", ""}
+	for i, m := range res[0].(jsonObject) {
+		if m.Key != fields[i] || cell(m.Value) != expect[i] {
+			t.Errorf("%s=%s", m.Key, cell(m.Value))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err = writeYAML(&buf, res); err != nil {
+		t.Fatal(err)
+	}
+
+	yaml := `- name: vm-a
+  config.numcpu: 2
+  $.config.tags:
+  - x
+  - "y"
+  nics.mac:
+  - "00:01"
+  - "00:02"
+  nics[1].mac: "00:02"
+  nics[*].mac:
+  - "00:01"
+  - "00:02"
+  enoent: null
+- name: "true"
+  config.numcpu: 0
+  $.config.tags: null
+  nics.mac: null
+  nics[1].mac: null
+  nics[*].mac: null
+  enoent: null
+`
+	if buf.String() != yaml {
+		t.Errorf("yaml=%s", buf.String())
+	}
+
+	if res, err = project(items, fields[:2]); err != nil {
+		t.Fatal(err)
+	}
+
+	buf.Reset()
+	if err = writeCSV(&buf, fields[:2], res); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "name,config.numcpu\nvm-a,2\ntrue,0\n" {
+		t.Errorf("csv=%s", buf.String())
+	}
+
+	buf.Reset()
+	flag := &OutputFlag{Format: "csv", Out: &buf}
+	if err = flag.WriteResult(&formatColumns{Items: []formatItem{a}}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "config.numCPU,name\n2,vm-a\n" {
+		t.Errorf("csv=%s", buf.String())
+	}
+
+	normal, _ := normalize(items[0])
+	if cols := columns([]interface{}{normal}); strings.Join(cols, ",") != "Name" {
+		t.Errorf("columns=%v", cols)
+	}
+}
@@ -35,13 +35,22 @@ type OutputWriter interface {
 	Write(io.Writer) error
 }
 
+// OutputColumns can be implemented by an OutputWriter to specify the fields displayed
+// by the table and csv formats when -output.fields is not specified.
+type OutputColumns interface {
+	Columns() []string
+}
+
 type OutputFlag struct {
 	common
 
-	JSON bool
-	TTY  bool
-	Dump bool
-	Out  io.Writer
+	JSON     bool
+	TTY      bool
+	Dump     bool
+	Format   string
+	Template string
+	Fields   string
+	Out      io.Writer
 }
 
 var outputFlagKey = flagKey("output")
@@ -60,12 +69,20 @@ func (flag *OutputFlag) Register(ctx context.Context, f *flag.FlagSet) {
 	flag.RegisterOnce(func() {
 		f.BoolVar(&flag.JSON, "json", false, "Enable JSON output")
 		f.BoolVar(&flag.Dump, "dump", false, "Enable Go output")
+		// Namespaced, as commands define their own flags such as vm.clone -template and events -format
+		f.StringVar(&flag.Format, "output.format", "", "Output format (json, yaml, xml, table, csv)")
+		f.StringVar(&flag.Fields, "output.fields", "", "Output only the given comma separated FIELDS (e.g. name,config.hardware.numCPU)")
+		f.StringVar(&flag.Template, "output.template", "", "Output using the given Go text/template")
 	})
 }
 
 func (flag *OutputFlag) Process(ctx context.Context) error {
 	return flag.ProcessOnce(func() error {
-		if !flag.JSON {
+		if flag.Format == "json" {
+			flag.JSON = true
+		}
+
+		if !flag.All() {
 			// Assume we have a tty if not outputting JSON
 			flag.TTY = true
 		}
@@ -100,8 +117,10 @@ func (flag *OutputFlag) WriteString(s string) (int, error) {
 	return flag.Write([]byte(s))
 }
 
+// All returns true if the output is not the command's default text format,
+// in which case commands should collect all properties of the objects written.
 func (flag *OutputFlag) All() bool {
-	return flag.JSON || flag.Dump
+	return flag.JSON || flag.Dump || flag.Format != "" || flag.Template != "" || flag.Fields != ""
 }
 
 func dumpValue(val interface{}) interface{} {
@@ -136,11 +155,16 @@ func dumpValue(val interface{}) interface{} {
 func (flag *OutputFlag) WriteResult(result OutputWriter) error {
 	var err error
 
-	if flag.JSON {
+	switch {
+	case flag.Template != "":
+		err = writeTemplate(flag.Out, flag.Template, result)
+	case flag.Fields != "" || (flag.Format != "" && flag.Format != "json"):
+		err = flag.writeFormat(result)
+	case flag.JSON:
 		err = json.NewEncoder(flag.Out).Encode(result)
-	} else if flag.Dump {
+	case flag.Dump:
 		pretty.Fprintf(flag.Out, "%# v\n", dumpValue(result))
-	} else {
+	default:
 		err = result.Write(flag.Out)
 	}
 
@@ -110,3 +110,22 @@ func (flag *VirtualMachineFlag) VirtualMachine() (*object.VirtualMachine, error)
 	flag.vm, err = finder.VirtualMachine(ctx, flag.name)
 	return flag.vm, err
 }
+
+// VirtualMachineList returns the virtual machines matching the -vm flag or search flags.
+// Unlike VirtualMachine, the -vm flag may be a pattern that matches more than one virtual machine.
+func (flag *VirtualMachineFlag) VirtualMachineList() ([]*object.VirtualMachine, error) {
+	if flag.SearchFlag.IsSet() || flag.name == "" {
+		vm, err := flag.VirtualMachine()
+		if err != nil || vm == nil {
+			return nil, err
+		}
+		return []*object.VirtualMachine{vm}, nil
+	}
+
+	finder, err := flag.Finder()
+	if err != nil {
+		return nil, err
+	}
+
+	return finder.VirtualMachineList(context.TODO(), flag.name)
+}
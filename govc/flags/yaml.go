@@ -0,0 +1,116 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DecodeYAML decodes YAML (or JSON) into v, via encoding/json such that v's json tags apply.
+func DecodeYAML(data []byte, v interface{}) error {
+	var val interface{}
+
+	if err := yaml.Unmarshal(data, &val); err != nil {
+		return err
+	}
+
+	val, err := jsonValue(val)
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// jsonValue converts the mappings decoded by yaml.Unmarshal, which have interface{} keys,
+// to map[string]interface{} for use with encoding/json.
+func jsonValue(val interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			k, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("unsupported mapping key: %v", key)
+			}
+			item, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = item
+		}
+		return m, nil
+	case []interface{}:
+		for i, item := range v {
+			item, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = item
+		}
+	}
+
+	return val, nil
+}
+
+// yamlValue converts a normalized value for use with yaml.Marshal, preserving the field order of objects.
+func yamlValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case jsonObject:
+		m := make(yaml.MapSlice, len(v))
+		for i, item := range v {
+			m[i] = yaml.MapItem{Key: item.Key, Value: yamlValue(item.Value)}
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = yamlValue(item)
+		}
+		return list
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		if n, err := v.Float64(); err == nil {
+			return n
+		}
+		return v.String()
+	}
+
+	return val
+}
+
+// writeYAML writes a normalized value in YAML format.
+func writeYAML(w io.Writer, val interface{}) error {
+	b, err := yaml.Marshal(yamlValue(val))
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,140 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package flags
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	type disk struct {
+		Size string
+		Thin *bool
+	}
+
+	var spec struct {
+		Name        string
+		Notes       string
+		CPUs        int32
+		Ratio       float64
+		Disks       []disk
+		Networks    []string
+		Tags        []string
+		ExtraConfig map[string]string
+		Empty       map[string]string
+		Missing     *string
+	}
+
+	in := `---
+# comment
+name: "vm # 1"
+notes: it's a VM # comment
+cpus: 2
+ratio: 0.5
+disks:
+- size: 10GB   # root
+  thin: false
+-
+  size: '1GB'
+networks: [VM Network, "DC0_DVPG0"]
+tags:
+  - a
+  - 'it''s'
+extraConfig:
+  disk.enableUUID: "TRUE"
+  foo: bar:baz
+empty: {}
+missing: ~
+`
+
+	if err := DecodeYAML([]byte(in), &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	if spec.Name != "vm # 1" || spec.Notes != "it's a VM" || spec.CPUs != 2 || spec.Ratio != 0.5 || spec.Missing != nil {
+		t.Errorf("spec=%#v", spec)
+	}
+
+	if len(spec.Disks) != 2 || spec.Disks[0].Size != "10GB" || spec.Disks[0].Thin == nil || *spec.Disks[0].Thin ||
+		spec.Disks[1].Size != "1GB" || spec.Disks[1].Thin != nil {
+		t.Errorf("disks=%#v", spec.Disks)
+	}
+
+	if !reflect.DeepEqual(spec.Networks, []string{"VM Network", "DC0_DVPG0"}) {
+		t.Errorf("networks=%#v", spec.Networks)
+	}
+
+	if !reflect.DeepEqual(spec.Tags, []string{"a", "it's"}) {
+		t.Errorf("tags=%#v", spec.Tags)
+	}
+
+	if spec.ExtraConfig["disk.enableUUID"] != "TRUE" || spec.ExtraConfig["foo"] != "bar:baz" {
+		t.Errorf("extraConfig=%#v", spec.ExtraConfig)
+	}
+
+	if spec.Empty == nil || len(spec.Empty) != 0 {
+		t.Errorf("empty=%#v", spec.Empty)
+	}
+
+	// JSON input
+	if err := DecodeYAML([]byte(`{"name": "json"}`), &spec); err != nil || spec.Name != "json" {
+		t.Errorf("name=%s, err=%v", spec.Name, err)
+	}
+
+	tests := []string{
+		"a: 1\n  b: 2\n",
+		"a: \"unterminated\n",
+		"a:\n\t- 1\n",
+		"1: a\n",
+	}
+
+	for _, test := range tests {
+		var val interface{}
+		if err := DecodeYAML([]byte(test), &val); err == nil {
+			t.Errorf("expected error decoding %q", test)
+		}
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	in := `{"name": "vm", "list": [[1, 2], {"a": null, "b": [true]}], "s": "- x", "n": "10", "e": [], "o": {}}`
+
+	val, err := normalize(json.RawMessage(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err = writeYAML(&buf, val); err != nil {
+		t.Fatal(err)
+	}
+
+	var x, y interface{}
+	if err = DecodeYAML(buf.Bytes(), &x); err != nil {
+		t.Fatalf("%s: %s", err, buf.String())
+	}
+	if err = json.Unmarshal([]byte(in), &y); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(x, y) {
+		t.Errorf("%#v != %#v\n%s", x, y, buf.String())
+	}
+}
@@ -125,6 +125,10 @@ type infoResult struct {
 	objects     []*object.HostSystem
 }
 
+func (r *infoResult) Columns() []string {
+	return []string{"name", "runtime.connectionState", "summary.hardware.numCpuCores", "summary.hardware.memorySize"}
+}
+
 func (r *infoResult) Write(w io.Writer) error {
 	// Maintain order via r.objects as Property collector does not always return results in order.
 	objects := make(map[types.ManagedObjectReference]mo.HostSystem, len(r.HostSystems))
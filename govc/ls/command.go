@@ -141,6 +141,10 @@ type listResult struct {
 	Elements []list.Element `json:"elements"`
 }
 
+func (l listResult) Columns() []string {
+	return []string{"path", "object.self.type"}
+}
+
 func (l listResult) Write(w io.Writer) error {
 	var err error
 
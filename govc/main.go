@@ -71,7 +71,9 @@ import (
 	_ "github.com/vmware/govmomi/govc/role"
 	_ "github.com/vmware/govmomi/govc/session"
 	_ "github.com/vmware/govmomi/govc/sso/service"
+	_ "github.com/vmware/govmomi/govc/sso/token"
 	_ "github.com/vmware/govmomi/govc/sso/user"
+	_ "github.com/vmware/govmomi/govc/storage/policy"
 	_ "github.com/vmware/govmomi/govc/tags"
 	_ "github.com/vmware/govmomi/govc/tags/association"
 	_ "github.com/vmware/govmomi/govc/tags/category"
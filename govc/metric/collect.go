@@ -0,0 +1,193 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metric
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/performance"
+)
+
+type collect struct {
+	*PerformanceFlag
+
+	n         int
+	kind      string
+	intervals string
+	instance  string
+	period    time.Duration
+	listen    string
+	format    string
+}
+
+func init() {
+	cli.Register("metric.collect", &collect{})
+}
+
+func (cmd *collect) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.PerformanceFlag, ctx = NewPerformanceFlag(ctx)
+	cmd.PerformanceFlag.Register(ctx, f)
+
+	f.IntVar(&cmd.n, "n", 1, "Number of collections, 0 to collect until interrupted")
+	f.StringVar(&cmd.kind, "type", "VirtualMachine,HostSystem", "Entity types to collect")
+	f.StringVar(&cmd.intervals, "intervals", "20", "Interval IDs to collect")
+	f.StringVar(&cmd.instance, "instance", "*", "Instance")
+	f.DurationVar(&cmd.period, "period", 20*time.Second, "Time between collections")
+	f.StringVar(&cmd.listen, "listen", "", "Serve metrics in OpenMetrics format on ADDR")
+	f.StringVar(&cmd.format, "format", "openmetrics", "Output format (openmetrics, csv, json)")
+}
+
+func (cmd *collect) Usage() string {
+	return "[PATH] NAME..."
+}
+
+func (cmd *collect) Description() string {
+	return `Collect metric NAME for entities within container PATH.
+
+PATH defaults to the root folder.  Entities of TYPE are collected for each interval ID in INTERVALS,
+such as "20,300" for the realtime and 5m intervals.
+The number of metrics per query is limited by the vCenter 'config.vpxd.stats.maxQueryMetrics' setting.
+Values are converted to base units, such as bytes, seconds or a ratio.
+
+If LISTEN is set, metrics are collected every PERIOD until interrupted and served via HTTP in the
+OpenMetrics text format.  Otherwise, N collections are written to stdout in the given FORMAT.
+
+Examples:
+  govc metric.collect cpu.usage.average mem.consumed.average
+  govc metric.collect -format csv -n 0 -period 1m host/cluster1 cpu.usage.average
+  govc metric.collect -type HostSystem -intervals 20,300 -format json cpu.usage.average
+  govc metric.collect -listen :9272 -type VirtualMachine,HostSystem cpu.usage.average net.bytesRx.average`
+}
+
+func (cmd *collect) Process(ctx context.Context) error {
+	if err := cmd.PerformanceFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *collect) write(samples []performance.Sample, header bool) error {
+	switch cmd.format {
+	case "csv":
+		return performance.WriteCSV(os.Stdout, samples, header)
+	case "json":
+		return performance.WriteJSON(os.Stdout, samples)
+	default:
+		return performance.WriteOpenMetrics(os.Stdout, samples)
+	}
+}
+
+func (cmd *collect) Run(ctx context.Context, f *flag.FlagSet) error {
+	switch cmd.format {
+	case "openmetrics", "csv", "json":
+	default:
+		return fmt.Errorf("unsupported format: %s", cmd.format)
+	}
+
+	m, err := cmd.Manager(ctx)
+	if err != nil {
+		return err
+	}
+
+	byName, err := m.CounterInfoByName(ctx)
+	if err != nil {
+		return err
+	}
+
+	var paths []string
+	var names []string
+
+	for _, arg := range f.Args() {
+		if _, ok := byName[arg]; ok {
+			names = append(names, arg)
+		} else {
+			paths = append(paths, arg)
+		}
+	}
+
+	if len(paths) > 1 || len(names) == 0 {
+		return flag.ErrHelp
+	}
+
+	c := performance.NewCollector(m)
+	c.Metrics = names
+	c.Types = strings.Split(cmd.kind, ",")
+	c.Period = cmd.period
+	c.Intervals = nil
+
+	if cmd.instance == "-" {
+		cmd.instance = ""
+	}
+	c.Instance = cmd.instance
+
+	for _, s := range strings.Split(cmd.intervals, ",") {
+		id, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid interval %q", s)
+		}
+		c.Intervals = append(c.Intervals, int32(id))
+	}
+
+	if len(paths) == 1 {
+		objs, err := cmd.ManagedObjects(ctx, paths)
+		if err != nil {
+			return err
+		}
+		c.Container = objs[0]
+	}
+
+	if cmd.listen != "" {
+		l, err := net.Listen("tcp", cmd.listen)
+		if err != nil {
+			return err
+		}
+
+		s := &http.Server{Handler: c}
+		go func() { _ = s.Serve(l) }()
+		defer s.Close()
+
+		return cmd.WithCancel(ctx, c.Run)
+	}
+
+	return cmd.WithCancel(ctx, func(wctx context.Context) error {
+		wctx, cancel := context.WithCancel(wctx)
+		defer cancel()
+
+		count := 0
+		c.Sample = func(samples []performance.Sample) error {
+			count++
+			if err := cmd.write(samples, count == 1); err != nil {
+				return err
+			}
+			if count == cmd.n {
+				cancel()
+			}
+			return nil
+		}
+
+		return c.Run(wctx)
+	})
+}
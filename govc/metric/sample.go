@@ -22,6 +22,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"os/exec"
 	"path"
@@ -38,11 +39,13 @@ import (
 type sample struct {
 	*PerformanceFlag
 
-	d        int
-	n        int
-	t        bool
-	plot     string
-	instance string
+	d         int
+	n         int
+	t         bool
+	plot      string
+	instance  string
+	aggregate bool
+	stat      string
 }
 
 func init() {
@@ -58,6 +61,8 @@ func (cmd *sample) Register(ctx context.Context, f *flag.FlagSet) {
 	f.StringVar(&cmd.plot, "plot", "", "Plot data using gnuplot")
 	f.BoolVar(&cmd.t, "t", false, "Include sample times")
 	f.StringVar(&cmd.instance, "instance", "*", "Instance")
+	f.BoolVar(&cmd.aggregate, "aggregate", false, "Combine instances into a single value per metric")
+	f.StringVar(&cmd.stat, "stat", "", "Output a single STAT of the samples (avg|sum|min|max|latest|pN|rollup)")
 }
 
 func (cmd *sample) Usage() string {
@@ -78,12 +83,19 @@ value, PLOT will pipe the script to gnuplot for you.  The value is also used to
 the gnuplot 'terminal' variable, unless the value is that of the DISPLAY env var.
 Only 1 metric NAME can be specified when the PLOT flag is set.
 
+If AGGREGATE is set, instances are combined into a single value per metric, using the
+aggregate counter if available, otherwise by summing the instance values (averaging percentages).
+If STAT is set, the samples are summarized by the given statistic, such as 'p95' for the 95th
+percentile.  A STAT value of 'rollup' uses the statistic implied by the counter rollup type.
+
 Examples:
   govc metric.sample host/cluster1/* cpu.usage.average
   govc metric.sample -plot .png host/cluster1/* cpu.usage.average | xargs open
   govc metric.sample vm/* net.bytesTx.average net.bytesTx.average
   govc metric.sample -instance vmnic0 vm/* net.bytesTx.average
-  govc metric.sample -instance - vm/* net.bytesTx.average`
+  govc metric.sample -instance - vm/* net.bytesTx.average
+  govc metric.sample -aggregate vm/* net.bytesTx.average
+  govc metric.sample -aggregate -stat p95 -n 180 vm/* cpu.usagemhz.average`
 }
 
 func (cmd *sample) Process(ctx context.Context) error {
@@ -246,8 +258,21 @@ func (r *sampleResult) Write(w io.Writer) error {
 				instance = "-"
 			}
 
+			value := v.ValueCSV()
+			if cmd.stat != "" {
+				kind := cmd.stat
+				if kind == "rollup" {
+					kind = "" // derived from the counter rollup type
+				}
+				stat, err := v.Stat(kind)
+				if err != nil {
+					return err
+				}
+				value = v.Format(int64(math.Round(stat)))
+			}
+
 			fmt.Fprintf(tw, "%s\t%s\t%s\t%v\t%s\t%s\n",
-				name, instance, v.Name, t, v.ValueCSV(), units)
+				name, instance, v.Name, t, value, units)
 		}
 	}
 
@@ -290,6 +315,12 @@ func (cmd *sample) Run(ctx context.Context, f *flag.FlagSet) error {
 		}
 	}
 
+	if cmd.stat != "" && cmd.stat != "rollup" {
+		if _, err = performance.Statistic(cmd.stat, []float64{0}); err != nil {
+			return err
+		}
+	}
+
 	objs, err := cmd.ManagedObjects(ctx, paths)
 	if err != nil {
 		return err
@@ -321,6 +352,12 @@ func (cmd *sample) Run(ctx context.Context, f *flag.FlagSet) error {
 		return err
 	}
 
+	if cmd.aggregate {
+		for i := range result {
+			result[i] = result[i].Aggregate()
+		}
+	}
+
 	counters, err := m.CounterInfoByName(ctx)
 	if err != nil {
 		return err
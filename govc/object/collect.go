@@ -52,6 +52,7 @@ type collect struct {
 
 	filter property.Filter
 	obj    string
+	multi  bool
 }
 
 func init() {
@@ -78,6 +79,7 @@ func (cmd *collect) Description() string {
 	return `Collect managed object properties.
 
 MOID can be an inventory path or ManagedObjectReference.
+If an inventory path matches multiple objects, which may be of different types, properties are collected for each object.
 MOID defaults to '-', an alias for 'ServiceInstance:ServiceInstance' or the root folder if a '-type' flag is given.
 
 If a '-type' flag is given, properties are collected using a ContainerView object where MOID is the root of the view.
@@ -95,6 +97,7 @@ Examples:
   govc object.collect -s /ha-datacenter/vm/foo overallStatus
   govc object.collect -s /ha-datacenter/vm/foo -guest.guestOperationsReady true # property filter
   govc object.collect -type m / name runtime.powerState # collect properties for multiple objects
+  govc object.collect '/dc1/host/*' name # collect properties for objects of mixed types
   govc object.collect -json -n=-1 EventManager:ha-eventmgr latestEvent | jq .
   govc object.collect -json -s $(govc object.collect -s - content.perfManager) description.counterType | jq .
   govc object.collect -R create-filter-request.xml # replay filter
@@ -109,7 +112,7 @@ type change struct {
 }
 
 func (pc *change) MarshalJSON() ([]byte, error) {
-	if len(pc.cmd.kind) == 0 {
+	if !pc.cmd.multi {
 		return json.Marshal(pc.Update.ChangeSet)
 	}
 
@@ -210,7 +213,7 @@ func (pc *change) Write(w io.Writer) error {
 			rtype = rval.Type()
 		}
 
-		if len(pc.cmd.kind) != 0 {
+		if pc.cmd.multi {
 			pc.cmd.obj = pc.Update.Obj.String()
 		}
 
@@ -306,6 +309,51 @@ func (cmd *collect) decodeFilter(filter *property.WaitFilter) error {
 	return dec.Decode(&env)
 }
 
+// retrieve collects the current property values of the given objects, which may be of mixed types,
+// without waiting for updates.
+func (cmd *collect) retrieve(ctx context.Context, p *property.Collector, refs []types.ManagedObjectReference, props []string) error {
+	ps := make(map[string][]string)
+	for _, ref := range refs {
+		ps[ref.Type] = props
+	}
+
+	var content []types.ObjectContent
+	if err := p.RetrieveByType(ctx, refs, ps, &content); err != nil {
+		return err
+	}
+
+	for _, o := range content {
+		update := types.ObjectUpdate{
+			Kind: types.ObjectUpdateKindEnter,
+			Obj:  o.Obj,
+		}
+
+		names := props
+		vals := make(map[string]types.AnyType)
+		for _, prop := range o.PropSet {
+			vals[prop.Name] = prop.Val
+			if len(props) == 0 {
+				names = append(names, prop.Name)
+			}
+		}
+
+		// unset properties are included with a nil value, as WaitForUpdates does
+		for _, name := range names {
+			update.ChangeSet = append(update.ChangeSet, types.PropertyChange{
+				Name: name,
+				Op:   types.PropertyChangeOpAssign,
+				Val:  vals[name],
+			})
+		}
+
+		if err := cmd.WriteResult(&change{cmd, update}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (cmd *collect) Run(ctx context.Context, f *flag.FlagSet) error {
 	client, err := cmd.Client()
 	if err != nil {
@@ -322,10 +370,12 @@ func (cmd *collect) Run(ctx context.Context, f *flag.FlagSet) error {
 
 	if cmd.raw == "" {
 		ref := vim25.ServiceInstance
+		refs := []types.ManagedObjectReference{ref}
 		arg := f.Arg(0)
 
 		if len(cmd.kind) != 0 {
 			ref = client.ServiceContent.RootFolder
+			refs[0] = ref
 		}
 
 		switch arg {
@@ -334,17 +384,22 @@ func (cmd *collect) Run(ctx context.Context, f *flag.FlagSet) error {
 			if !ref.FromString(arg) {
 				l, ferr := finder.ManagedObjectList(ctx, arg)
 				if ferr != nil {
-					return err
+					return ferr
 				}
 
-				switch len(l) {
-				case 0:
+				switch {
+				case len(l) == 0:
 					return fmt.Errorf("%s not found", arg)
-				case 1:
-					ref = l[0].Object.Reference()
-				default:
+				case len(l) > 1 && len(cmd.kind) != 0:
 					return flag.ErrHelp
 				}
+
+				refs = nil
+				for _, e := range l {
+					refs = append(refs, e.Object.Reference())
+				}
+			} else {
+				refs[0] = ref
 			}
 		}
 
@@ -359,12 +414,26 @@ func (cmd *collect) Run(ctx context.Context, f *flag.FlagSet) error {
 			return err
 		}
 
+		cmd.multi = len(cmd.kind) != 0 || len(refs) > 1
+
 		if len(cmd.kind) == 0 {
-			filter.Add(ref, ref.Type, props)
+			if cmd.n == 0 && cmd.wait == 0 && len(cmd.filter) == 0 && !cmd.dump {
+				return cmd.retrieve(ctx, p, refs, props)
+			}
+
+			kinds := make(map[string]bool)
+			for _, ref := range refs {
+				if kinds[ref.Type] {
+					filter.Spec.ObjectSet = append(filter.Spec.ObjectSet, types.ObjectSpec{Obj: ref})
+					continue
+				}
+				kinds[ref.Type] = true
+				filter.Add(ref, ref.Type, props)
+			}
 		} else {
 			m := view.NewManager(client)
 
-			v, cerr := m.CreateContainerView(ctx, ref, cmd.kind, true)
+			v, cerr := m.CreateContainerView(ctx, refs[0], cmd.kind, true)
 			if cerr != nil {
 				return cerr
 			}
@@ -382,7 +451,7 @@ func (cmd *collect) Run(ctx context.Context, f *flag.FlagSet) error {
 	}
 
 	if cmd.dump {
-		if !cmd.JSON {
+		if !cmd.All() {
 			cmd.Dump = true
 		}
 		return cmd.WriteResult(&dumpFilter{filter.CreateFilter})
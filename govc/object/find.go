@@ -40,6 +40,10 @@ type find struct {
 	kind     kinds
 	name     string
 	maxdepth int
+	where    predicates
+	sort     string
+	reverse  bool
+	limit    int
 }
 
 var alias = []struct {
@@ -110,6 +114,21 @@ func (e *kinds) wanted(kind string) bool {
 	return false
 }
 
+type predicates []*property.Predicate
+
+func (p *predicates) String() string {
+	return ""
+}
+
+func (p *predicates) Set(value string) error {
+	x, err := property.ParsePredicate(value)
+	if err != nil {
+		return err
+	}
+	*p = append(*p, x)
+	return nil
+}
+
 func init() {
 	cli.Register("find", &find{})
 }
@@ -122,6 +141,10 @@ func (cmd *find) Register(ctx context.Context, f *flag.FlagSet) {
 	f.StringVar(&cmd.name, "name", "*", "Resource name")
 	f.IntVar(&cmd.maxdepth, "maxdepth", -1, "Max depth")
 	f.BoolVar(&cmd.ref, "i", false, "Print the managed object reference")
+	f.Var(&cmd.where, "where", "Include only objects matching property predicate EXPR")
+	f.StringVar(&cmd.sort, "sort", "", "Sort results by property PATH")
+	f.BoolVar(&cmd.reverse, "r", false, "Reverse the sort order")
+	f.IntVar(&cmd.limit, "limit", 0, "Output at most N results")
 }
 
 func (cmd *find) Usage() string {
@@ -139,6 +162,14 @@ ROOT defaults to '.', an alias for the root folder or DC if set.
 Optional KEY VAL pairs can be used to filter results against object instance properties.
 Use the govc 'object.collect' command to view possible object property keys.
 
+The '-where' flag can be specified multiple times, all of which must match.  EXPR compares a
+property PATH with a value, using one of the operators: == != < <= > >= =~ (regexp) !~
+A PATH without an operator matches if the property is set to a non-zero value.  PATH may refer
+to fields of array properties, such as 'guest.net.ipAddress', matching if any element matches.
+Comparisons can be combined using && || ! and parentheses.  Values containing spaces can be quoted.
+
+The '-sort' flag orders results by the value of property PATH, where the '-r' flag reverses the order.
+
 The '-type' flag value can be a managed entity type or one of the following aliases:
 
 %s
@@ -150,27 +181,32 @@ Examples:
   govc find . -type m -runtime.powerState poweredOn
   govc find . -type m -datastore $(govc find -i datastore -name vsanDatastore)
   govc find . -type s -summary.type vsan
-  govc find . -type h -hardware.cpuInfo.numCpuCores 16`, atable)
+  govc find . -type h -hardware.cpuInfo.numCpuCores 16
+  govc find . -type m -where 'summary.quickStats.overallCpuUsage>1000' -sort summary.quickStats.overallCpuUsage -r
+  govc find . -type m -where 'runtime.powerState==poweredOn && !config.template'
+  govc find . -type m -where 'guest.net.ipAddress=~^10\.' -where 'name!=test-*'
+  govc find . -type s -where 'summary.freeSpace<10737418240' -sort name
+  govc find . -type h -sort summary.quickStats.overallMemoryUsage -r -limit 3`, atable)
 }
 
 // rootMatch returns true if the root object path should be printed
-func (cmd *find) rootMatch(ctx context.Context, root object.Reference, client *vim25.Client, filter property.Filter) bool {
+func (cmd *find) rootMatch(ctx context.Context, root object.Reference, client *vim25.Client, filter property.Filter, match *property.Predicate) bool {
 	ref := root.Reference()
 
 	if !cmd.kind.wanted(ref.Type) {
 		return false
 	}
 
-	if len(filter) == 1 && filter["name"] == "*" {
+	if len(filter) == 1 && filter["name"] == "*" && len(cmd.where) == 0 {
 		return true
 	}
 
 	var content []types.ObjectContent
 
 	pc := property.DefaultCollector(client)
-	_ = pc.RetrieveWithFilter(ctx, []types.ManagedObjectReference{ref}, filter.Keys(), &content, filter)
+	_ = pc.Retrieve(ctx, []types.ManagedObjectReference{ref}, match.Keys(ref.Type), &content)
 
-	return content != nil
+	return len(content) == 1 && match.Match(content[0].PropSet)
 }
 
 type findResult []string
@@ -211,10 +247,6 @@ func (cmd *find) Run(ctx context.Context, f *flag.FlagSet) error {
 		}
 	}
 
-	if len(props)%2 != 0 {
-		return flag.ErrHelp
-	}
-
 	dc, err := cmd.DatacenterIfSpecified()
 	if err != nil {
 		return err
@@ -259,10 +291,6 @@ func (cmd *find) Run(ctx context.Context, f *flag.FlagSet) error {
 
 	filter := property.Filter{}
 
-	if len(props)%2 != 0 {
-		return flag.ErrHelp
-	}
-
 	for i := 0; i < len(props); i++ {
 		key := props[i]
 		if !strings.HasPrefix(key, "-") {
@@ -270,10 +298,25 @@ func (cmd *find) Run(ctx context.Context, f *flag.FlagSet) error {
 		}
 
 		key = key[1:]
+		xf := f.Lookup(key)
+
+		if xf != nil {
+			if b, ok := xf.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+				// Boolean flags such as -r following the ROOT arg do not take a value
+				if err = xf.Value.Set("true"); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
 		i++
+		if i == len(props) {
+			return flag.ErrHelp
+		}
 		val := props[i]
 
-		if xf := f.Lookup(key); xf != nil {
+		if xf != nil {
 			// Support use of -flag following the ROOT arg (flag package does not do this)
 			if err = xf.Value.Set(val); err != nil {
 				return err
@@ -284,6 +327,8 @@ func (cmd *find) Run(ctx context.Context, f *flag.FlagSet) error {
 	}
 
 	filter["name"] = cmd.name
+	match := filter.Predicate().And(cmd.where...)
+
 	var paths findResult
 
 	printPath := func(o types.ManagedObjectReference, p string) {
@@ -307,7 +352,7 @@ func (cmd *find) Run(ctx context.Context, f *flag.FlagSet) error {
 		return flag.ErrHelp // TODO: ?
 	}
 
-	if cmd.rootMatch(ctx, root, client, filter) {
+	if cmd.rootMatch(ctx, root, client, filter, match) {
 		printPath(root, arg)
 	}
 
@@ -324,23 +369,36 @@ func (cmd *find) Run(ctx context.Context, f *flag.FlagSet) error {
 
 	defer v.Destroy(ctx)
 
-	objs, err := v.Find(ctx, cmd.kind, filter)
+	var sort []string
+	if cmd.sort != "" {
+		sort = append(sort, cmd.sort)
+	}
+
+	objs, err := v.FindWithPredicate(ctx, cmd.kind, match, sort...)
 	if err != nil {
 		return err
 	}
 
+	if cmd.sort != "" {
+		property.SortObjectContent(objs, cmd.sort, cmd.reverse)
+	}
+
 	for _, o := range objs {
+		if cmd.limit > 0 && len(paths) == cmd.limit {
+			break
+		}
+
 		var path string
 
 		if !cmd.ref {
-			e, err := finder.Element(ctx, o)
+			e, err := finder.Element(ctx, o.Obj)
 			if err != nil {
 				return err
 			}
 			path = e.Path
 		}
 
-		printPath(o, path)
+		printPath(o.Obj, path)
 	}
 
 	return cmd.WriteResult(paths)
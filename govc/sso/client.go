@@ -0,0 +1,64 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sso
+
+import (
+	"context"
+	"os"
+
+	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/sts"
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// Header returns a soap.Header with a SAML token, as required by the SSO admin and Lookup Service management APIs.
+// These servers have their own session managers, so the govc persisted session cookies cannot be used to authenticate.
+// There is no SSO token persistence in govc yet, so just use an env var for now.
+// If no GOVC_LOGIN_TOKEN is set, issue a new token.
+func Header(ctx context.Context, cmd *flags.ClientFlag) (soap.Header, error) {
+	vc, err := cmd.Client()
+	if err != nil {
+		return soap.Header{}, err
+	}
+
+	token := os.Getenv("GOVC_LOGIN_TOKEN")
+	header := soap.Header{
+		Security: &sts.Signer{
+			Certificate: vc.Certificate(),
+			Token:       token,
+		},
+	}
+
+	if token == "" {
+		tokens, err := sts.NewClient(ctx, vc)
+		if err != nil {
+			return header, err
+		}
+
+		req := sts.TokenRequest{
+			Certificate: vc.Certificate(),
+			Userinfo:    cmd.Userinfo(),
+		}
+
+		header.Security, err = tokens.Issue(ctx, req)
+		if err != nil {
+			return header, err
+		}
+	}
+
+	return header, nil
+}
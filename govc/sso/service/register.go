@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/govc/sso"
+	"github.com/vmware/govmomi/lookup"
+	"github.com/vmware/govmomi/lookup/types"
+)
+
+// withClient calls f with a lookup.Client and a context containing a SAML token header,
+// as required to modify the Lookup Service registrations.
+func withClient(ctx context.Context, cmd *flags.ClientFlag, f func(context.Context, *lookup.Client) error) error {
+	vc, err := cmd.Client()
+	if err != nil {
+		return err
+	}
+
+	c, err := lookup.NewClient(ctx, vc)
+	if err != nil {
+		return err
+	}
+
+	header, err := sso.Header(ctx, cmd)
+	if err != nil {
+		return err
+	}
+
+	return f(c.WithHeader(ctx, header), c)
+}
+
+type register struct {
+	*flags.ClientFlag
+
+	id string
+}
+
+func init() {
+	cli.Register("sso.service.register", &register{})
+}
+
+func (cmd *register) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	f.StringVar(&cmd.id, "id", "", "Service ID (defaults to a generated UUID)")
+}
+
+func (cmd *register) Description() string {
+	return `Register platform service.
+
+The service registration spec is read from STDIN in JSON format.
+The service ID is printed on success.
+
+Examples:
+  govc sso.service.register < spec.json
+  govc sso.service.register -id my-service-id < spec.json
+  govc sso.service.ls -t my-service-type -l`
+}
+
+func (cmd *register) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() != 0 {
+		return flag.ErrHelp
+	}
+
+	var spec types.LookupServiceRegistrationCreateSpec
+	if err := json.NewDecoder(os.Stdin).Decode(&spec); err != nil {
+		return err
+	}
+
+	id := cmd.id
+	if id == "" {
+		id = uuid.New().String()
+	}
+
+	return withClient(ctx, cmd.ClientFlag, func(ctx context.Context, c *lookup.Client) error {
+		if err := c.Create(ctx, id, spec); err != nil {
+			return err
+		}
+
+		fmt.Println(id)
+		return nil
+	})
+}
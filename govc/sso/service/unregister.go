@@ -0,0 +1,66 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"flag"
+
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/lookup"
+)
+
+type unregister struct {
+	*flags.ClientFlag
+}
+
+func init() {
+	cli.Register("sso.service.unregister", &unregister{})
+}
+
+func (cmd *unregister) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+}
+
+func (cmd *unregister) Usage() string {
+	return "ID..."
+}
+
+func (cmd *unregister) Description() string {
+	return `Unregister platform service ID.
+
+Examples:
+  govc sso.service.unregister my-service-id
+  govc sso.service.ls -t my-service-type -json | jq -r .[].ServiceId | xargs govc sso.service.unregister`
+}
+
+func (cmd *unregister) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() == 0 {
+		return flag.ErrHelp
+	}
+
+	return withClient(ctx, cmd.ClientFlag, func(ctx context.Context, c *lookup.Client) error {
+		for _, id := range f.Args() {
+			if err := c.Delete(ctx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,72 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/lookup"
+	"github.com/vmware/govmomi/lookup/types"
+)
+
+type update struct {
+	*flags.ClientFlag
+}
+
+func init() {
+	cli.Register("sso.service.update", &update{})
+}
+
+func (cmd *update) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+}
+
+func (cmd *update) Usage() string {
+	return "ID"
+}
+
+func (cmd *update) Description() string {
+	return `Update platform service ID.
+
+The service spec is read from STDIN in JSON format and replaces the service's mutable info,
+such as ServiceVersion, ServiceEndpoints and ServiceAttributes.
+
+Examples:
+  govc sso.service.update my-service-id < spec.json
+  govc sso.service.ls -json -t my-service-type | jq '.[0] | .ServiceVersion = "2.0"' | govc sso.service.update my-service-id`
+}
+
+func (cmd *update) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() != 1 {
+		return flag.ErrHelp
+	}
+
+	var spec types.LookupServiceRegistrationSetSpec
+	if err := json.NewDecoder(os.Stdin).Decode(&spec); err != nil {
+		return err
+	}
+
+	return withClient(ctx, cmd.ClientFlag, func(ctx context.Context, c *lookup.Client) error {
+		return c.Set(ctx, f.Arg(0), spec)
+	})
+}
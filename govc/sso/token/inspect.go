@@ -0,0 +1,130 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/sts"
+)
+
+type inspect struct {
+	*flags.OutputFlag
+}
+
+func init() {
+	cli.Register("sso.token.inspect", &inspect{})
+}
+
+func (cmd *inspect) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+}
+
+func (cmd *inspect) Usage() string {
+	return "TOKEN"
+}
+
+func (cmd *inspect) Description() string {
+	return `Inspect SAML TOKEN.
+
+Decodes the token's SAML assertion, printing the subject, lifetime, confirmation type and delegation details.
+If TOKEN is '-' or not specified, the token is read from STDIN.
+Note that the token signature is not validated.
+
+Examples:
+  govc sso.token.inspect "$(govc sso.token.issue)"
+  govc sso.token.issue -lifetime 1h | govc sso.token.inspect -
+  govc sso.token.inspect -json "$token" | jq -r .NotOnOrAfter`
+}
+
+// readToken returns the token given by arg, reading from STDIN if arg is empty or '-'.
+func readToken(arg string) (string, error) {
+	if arg != "" && arg != "-" {
+		return arg, nil
+	}
+
+	b, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
+
+type tokenResult struct {
+	Token string `json:",omitempty"`
+	*sts.TokenInfo
+
+	long bool
+}
+
+func (r *tokenResult) Write(w io.Writer) error {
+	if !r.long {
+		_, err := fmt.Fprintln(w, r.Token)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 2, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Subject:\t%s\n", r.Subject)
+	fmt.Fprintf(tw, "  ID:\t%s\n", r.ID)
+	fmt.Fprintf(tw, "  Issuer:\t%s\n", r.Issuer)
+	fmt.Fprintf(tw, "  Confirmation:\t%s\n", r.Confirmation)
+	fmt.Fprintf(tw, "  Not before:\t%s\n", r.NotBefore.Format(time.RFC3339))
+	fmt.Fprintf(tw, "  Not on or after:\t%s\n", r.NotOnOrAfter.Format(time.RFC3339))
+
+	status := "expired"
+	if r.Valid() {
+		status = fmt.Sprintf("valid (expires in %s)", time.Until(r.NotOnOrAfter).Round(time.Second))
+	}
+	fmt.Fprintf(tw, "  Status:\t%s\n", status)
+	fmt.Fprintf(tw, "  Renewable:\t%t\n", r.Renewable)
+	fmt.Fprintf(tw, "  Delegatable:\t%t\n", r.Delegatable)
+	fmt.Fprintf(tw, "  Delegates:\t%s\n", strings.Join(r.Delegates, ","))
+	fmt.Fprintf(tw, "  Groups:\t%s\n", strings.Join(r.Groups, ","))
+
+	return tw.Flush()
+}
+
+func (cmd *inspect) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() > 1 {
+		return flag.ErrHelp
+	}
+
+	token, err := readToken(f.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	info, err := sts.ParseToken(token)
+	if err != nil {
+		return err
+	}
+
+	return cmd.WriteResult(&tokenResult{TokenInfo: info, long: true})
+}
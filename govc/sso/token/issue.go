@@ -0,0 +1,102 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/sts"
+)
+
+type issue struct {
+	*flags.ClientFlag
+	*flags.OutputFlag
+
+	long bool
+	req  sts.TokenRequest
+}
+
+func init() {
+	cli.Register("sso.token.issue", &issue{})
+}
+
+func (cmd *issue) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
+	f.BoolVar(&cmd.long, "l", false, "Print token details rather than the token itself")
+	f.DurationVar(&cmd.req.Lifetime, "lifetime", time.Minute*10, "Token lifetime")
+	f.BoolVar(&cmd.req.Renewable, "renewable", true, "Issue a renewable token")
+	f.BoolVar(&cmd.req.Delegatable, "delegatable", false, "Issue a delegatable token")
+	f.StringVar(&cmd.req.Token, "token", "", "Issue an ActAs token on behalf of the subject of this token")
+}
+
+func (cmd *issue) Process(ctx context.Context) error {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	return cmd.OutputFlag.Process(ctx)
+}
+
+func (cmd *issue) Description() string {
+	return `Issue SAML token.
+
+A Holder-of-Key token is issued when a certificate is specified via -cert and -key,
+otherwise a Bearer token is issued using the username and password credentials.
+
+Examples:
+  govc sso.token.issue -delegatable > bearer.xml
+  govc sso.token.issue -cert user.crt -key user.key -lifetime 24h > hok.xml
+  govc sso.token.issue -cert user.crt -key user.key -token "$(cat bearer.xml)" -l
+  govc sso.token.issue -json | jq -r .Subject`
+}
+
+func (cmd *issue) Run(ctx context.Context, f *flag.FlagSet) error {
+	vc, err := cmd.Client()
+	if err != nil {
+		return err
+	}
+
+	c, err := sts.NewClient(ctx, vc)
+	if err != nil {
+		return err
+	}
+
+	req := cmd.req
+	req.Certificate = c.Certificate()
+	req.Userinfo = cmd.Userinfo()
+	req.ActAs = req.Token != ""
+
+	s, err := c.Issue(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	info, err := sts.ParseToken(s.Token)
+	if err != nil {
+		return err
+	}
+
+	return cmd.WriteResult(&tokenResult{s.Token, info, cmd.long})
+}
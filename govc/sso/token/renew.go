@@ -0,0 +1,114 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"time"
+
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/sts"
+)
+
+type renew struct {
+	*flags.ClientFlag
+	*flags.OutputFlag
+
+	long bool
+	req  sts.TokenRequest
+}
+
+func init() {
+	cli.Register("sso.token.renew", &renew{})
+}
+
+func (cmd *renew) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
+	f.BoolVar(&cmd.long, "l", false, "Print token details rather than the token itself")
+	f.DurationVar(&cmd.req.Lifetime, "lifetime", time.Minute*10, "Token lifetime")
+}
+
+func (cmd *renew) Process(ctx context.Context) error {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	return cmd.OutputFlag.Process(ctx)
+}
+
+func (cmd *renew) Usage() string {
+	return "TOKEN"
+}
+
+func (cmd *renew) Description() string {
+	return `Renew SAML TOKEN.
+
+The token must be renewable and the request signed using the certificate specified via -cert and -key.
+If TOKEN is '-' or not specified, the token is read from STDIN.
+
+Examples:
+  govc sso.token.renew -cert user.crt -key user.key -lifetime 24h "$(cat hok.xml)" > renewed.xml
+  govc sso.token.issue -cert user.crt -key user.key | govc sso.token.renew -cert user.crt -key user.key -l -`
+}
+
+func (cmd *renew) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() > 1 {
+		return flag.ErrHelp
+	}
+
+	token, err := readToken(f.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	vc, err := cmd.Client()
+	if err != nil {
+		return err
+	}
+
+	c, err := sts.NewClient(ctx, vc)
+	if err != nil {
+		return err
+	}
+
+	req := cmd.req
+	req.Certificate = c.Certificate()
+	req.Token = token
+
+	if req.Certificate == nil {
+		return errors.New("renew requires a certificate (-cert and -key flags)")
+	}
+
+	s, err := c.Renew(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	info, err := sts.ParseToken(s.Token)
+	if err != nil {
+		return err
+	}
+
+	return cmd.WriteResult(&tokenResult{s.Token, info, cmd.long})
+}
@@ -21,14 +21,12 @@ import (
 	"encoding/base64"
 	"encoding/pem"
 	"flag"
-	"os"
 
 	"github.com/vmware/govmomi/govc/cli"
 	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/govc/sso"
 	"github.com/vmware/govmomi/ssoadmin"
 	"github.com/vmware/govmomi/ssoadmin/types"
-	"github.com/vmware/govmomi/sts"
-	"github.com/vmware/govmomi/vim25/soap"
 )
 
 func withClient(ctx context.Context, cmd *flags.ClientFlag, f func(*ssoadmin.Client) error) error {
@@ -42,32 +40,9 @@ func withClient(ctx context.Context, cmd *flags.ClientFlag, f func(*ssoadmin.Cli
 		return err
 	}
 
-	// SSO admin server has its own session manager, so the govc persisted session cookies cannot
-	// be used to authenticate.  There is no SSO token persistence in govc yet, so just use an env
-	// var for now.  If no GOVC_LOGIN_TOKEN is set, issue a new token.
-	token := os.Getenv("GOVC_LOGIN_TOKEN")
-	header := soap.Header{
-		Security: &sts.Signer{
-			Certificate: vc.Certificate(),
-			Token:       token,
-		},
-	}
-
-	if token == "" {
-		tokens, cerr := sts.NewClient(ctx, vc)
-		if cerr != nil {
-			return cerr
-		}
-
-		req := sts.TokenRequest{
-			Certificate: vc.Certificate(),
-			Userinfo:    cmd.Userinfo(),
-		}
-
-		header.Security, cerr = tokens.Issue(ctx, req)
-		if cerr != nil {
-			return cerr
-		}
+	header, err := sso.Header(ctx, cmd)
+	if err != nil {
+		return err
 	}
 
 	if err = c.Login(c.WithHeader(ctx, header)); err != nil {
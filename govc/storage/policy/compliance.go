@@ -0,0 +1,164 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/pbm/types"
+	vim "github.com/vmware/govmomi/vim25/types"
+)
+
+type compliance struct {
+	*flags.DatacenterFlag
+	*flags.OutputFlag
+}
+
+func init() {
+	cli.Register("storage.policy.compliance", &compliance{})
+}
+
+func (cmd *compliance) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.DatacenterFlag, ctx = flags.NewDatacenterFlag(ctx)
+	cmd.DatacenterFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+}
+
+func (cmd *compliance) Process(ctx context.Context) error {
+	if err := cmd.DatacenterFlag.Process(ctx); err != nil {
+		return err
+	}
+	return cmd.OutputFlag.Process(ctx)
+}
+
+func (cmd *compliance) Usage() string {
+	return "VM..."
+}
+
+func (cmd *compliance) Description() string {
+	return `Storage policy compliance of VM home and virtual disks.
+
+Examples:
+  govc storage.policy.compliance my-vm
+  govc storage.policy.compliance -json my-vm | jq .`
+}
+
+type complianceStatus struct {
+	Name   string                    `json:"name"`
+	Policy string                    `json:"policy"`
+	Result types.PbmComplianceResult `json:"result"`
+}
+
+type complianceResult []complianceStatus
+
+func (r complianceResult) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 2, 0, 2, ' ', 0)
+
+	for _, s := range r {
+		policy := s.Policy
+		if policy == "" {
+			policy = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Name, policy, s.Result.ComplianceStatus)
+	}
+
+	return tw.Flush()
+}
+
+func (cmd *compliance) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() == 0 {
+		return flag.ErrHelp
+	}
+
+	c, err := cmd.PbmClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	finder, err := cmd.Finder()
+	if err != nil {
+		return err
+	}
+
+	profiles, err := policies(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]string)
+	for _, p := range profiles {
+		profile := p.GetPbmProfile()
+		names[profile.ProfileId.UniqueId] = profile.Name
+	}
+
+	var entities []types.PbmServerObjectRef
+	var res complianceResult
+
+	for _, arg := range f.Args() {
+		vms, err := finder.VirtualMachineList(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		for _, vm := range vms {
+			entities = append(entities, types.PbmServerObjectRef{
+				ObjectType: string(types.PbmObjectTypeVirtualMachine),
+				Key:        vm.Reference().Value,
+			})
+			res = append(res, complianceStatus{Name: vm.Name()})
+
+			devices, err := vm.Device(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, disk := range devices.SelectByType((*vim.VirtualDisk)(nil)) {
+				entities = append(entities, types.PbmServerObjectRef{
+					ObjectType: string(types.PbmObjectTypeVirtualDiskId),
+					Key:        fmt.Sprintf("%s:%d", vm.Reference().Value, disk.GetVirtualDevice().Key),
+				})
+				res = append(res, complianceStatus{Name: vm.Name() + "/" + devices.Name(disk)})
+			}
+		}
+	}
+
+	results, err := c.CheckCompliance(ctx, entities, nil)
+	if err != nil {
+		return err
+	}
+
+	for i, entity := range entities {
+		for _, result := range results {
+			if result.Entity.ObjectType == entity.ObjectType && result.Entity.Key == entity.Key {
+				res[i].Result = result
+				if result.Profile != nil {
+					res[i].Policy = names[result.Profile.UniqueId]
+				}
+			}
+		}
+	}
+
+	return cmd.WriteResult(res)
+}
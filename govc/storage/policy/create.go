@@ -0,0 +1,177 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/pbm"
+	"github.com/vmware/govmomi/pbm/types"
+)
+
+// rules implements flag.Value, collecting rules of the form NAMESPACE.ID=VALUE
+type rules []pbm.Capability
+
+func (r *rules) String() string {
+	return fmt.Sprintf("%v", *r)
+}
+
+// dataType infers the pbm.Property DataType of the given rule value.
+func dataType(val string) string {
+	if _, err := strconv.ParseInt(val, 10, 32); err == nil {
+		return "int"
+	}
+	if val == "true" || val == "false" {
+		return "bool"
+	}
+	if strings.Contains(val, ",") {
+		return "set"
+	}
+	return "string"
+}
+
+func (r *rules) Set(s string) error {
+	kv := strings.SplitN(s, "=", 2)
+	i := strings.LastIndex(kv[0], ".")
+	if len(kv) != 2 || i <= 0 || i == len(kv[0])-1 {
+		return fmt.Errorf("invalid rule %q, expected NAMESPACE.ID=VALUE", s)
+	}
+
+	ns, id := kv[0][:i], kv[0][i+1:]
+
+	*r = append(*r, pbm.Capability{
+		ID:        id,
+		Namespace: ns,
+		PropertyList: []pbm.Property{
+			{ID: id, Value: kv[1], DataType: dataType(kv[1])},
+		},
+	})
+
+	return nil
+}
+
+type create struct {
+	*flags.ClientFlag
+
+	spec  pbm.CapabilityProfileCreateSpec
+	rules rules
+	file  string
+}
+
+func init() {
+	cli.Register("storage.policy.create", &create{})
+}
+
+func (cmd *create) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	f.StringVar(&cmd.spec.Description, "d", "", "Description")
+	f.Var(&cmd.rules, "rule", "Rule of the form NAMESPACE.ID=VALUE")
+	f.StringVar(&cmd.file, "spec", "", "JSON policy spec file ('-' for STDIN)")
+}
+
+func (cmd *create) Usage() string {
+	return "NAME"
+}
+
+func (cmd *create) Description() string {
+	return `Create storage policy NAME.
+
+Rule VALUE types are inferred as: int, bool (true|false), set (comma separated list) or string.
+Alternatively, a JSON spec can be used to create a policy, where NAME and any -d or -rule flags
+take precedence over the spec:
+  {"Name": "...", "Description": "...", "CapabilityList": [{"Namespace": "VSAN", "ID": "stripeWidth",
+   "PropertyList": [{"ID": "stripeWidth", "Value": "2", "DataType": "int"}]}]}
+
+Examples:
+  govc storage.policy.create -rule VSAN.hostFailuresToTolerate=1 -rule VSAN.stripeWidth=2 my-vsan-policy
+  govc storage.policy.create -d "Gold tier" -rule com.example.tier=gold,platinum my-policy
+  govc storage.policy.info my-vsan-policy
+  govc storage.policy.create -spec policy.json`
+}
+
+// readSpec decodes the given JSON spec file into spec.
+func readSpec(name string, spec *pbm.CapabilityProfileCreateSpec) error {
+	var r io.Reader = os.Stdin
+
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	return json.NewDecoder(r).Decode(spec)
+}
+
+func (cmd *create) Run(ctx context.Context, f *flag.FlagSet) error {
+	spec := cmd.spec
+
+	if cmd.file != "" {
+		if err := readSpec(cmd.file, &spec); err != nil {
+			return err
+		}
+		if cmd.spec.Description != "" {
+			spec.Description = cmd.spec.Description
+		}
+	}
+
+	if f.NArg() == 1 {
+		spec.Name = f.Arg(0)
+	}
+
+	if f.NArg() > 1 || spec.Name == "" {
+		return flag.ErrHelp
+	}
+
+	if spec.Category == "" {
+		spec.Category = string(types.PbmProfileCategoryEnumREQUIREMENT)
+	}
+
+	spec.CapabilityList = append(spec.CapabilityList, cmd.rules...)
+
+	c, err := cmd.PbmClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	pspec, err := pbm.CreateCapabilityProfileSpec(spec)
+	if err != nil {
+		return err
+	}
+
+	id, err := c.CreateProfile(ctx, *pspec)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(id.UniqueId)
+
+	return nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/pbm/types"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/mo"
+	vim "github.com/vmware/govmomi/vim25/types"
+)
+
+type datastores struct {
+	*flags.ClientFlag
+	*flags.OutputFlag
+
+	non bool
+}
+
+func init() {
+	cli.Register("storage.policy.datastores", &datastores{})
+}
+
+func (cmd *datastores) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
+	f.BoolVar(&cmd.non, "non", false, "List datastores that are not compatible")
+}
+
+func (cmd *datastores) Process(ctx context.Context) error {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	return cmd.OutputFlag.Process(ctx)
+}
+
+func (cmd *datastores) Usage() string {
+	return "NAME"
+}
+
+func (cmd *datastores) Description() string {
+	return `List datastores compatible with storage policy NAME.
+
+Examples:
+  govc storage.policy.datastores "vSAN Default Storage Policy"
+  govc storage.policy.datastores -non "vSAN Default Storage Policy"`
+}
+
+type datastoresResult []mo.Datastore
+
+func (r datastoresResult) Write(w io.Writer) error {
+	for _, ds := range r {
+		fmt.Fprintln(w, ds.Name)
+	}
+	return nil
+}
+
+func (cmd *datastores) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() != 1 {
+		return flag.ErrHelp
+	}
+
+	vc, err := cmd.Client()
+	if err != nil {
+		return err
+	}
+
+	c, err := cmd.PbmClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := policies(ctx, c, f.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	req := []types.BasePbmPlacementRequirement{
+		&types.PbmPlacementCapabilityProfileRequirement{
+			ProfileId: profiles[0].GetPbmProfile().ProfileId,
+		},
+	}
+
+	res, err := c.CheckRequirements(ctx, nil, nil, req)
+	if err != nil {
+		return err
+	}
+
+	hubs := res.CompatibleDatastores()
+	if cmd.non {
+		hubs = res.NonCompatibleDatastores()
+	}
+
+	var refs []vim.ManagedObjectReference
+	for _, hub := range hubs {
+		refs = append(refs, vim.ManagedObjectReference{Type: hub.HubType, Value: hub.HubId})
+	}
+
+	var result datastoresResult
+
+	if len(refs) != 0 {
+		pc := property.DefaultCollector(vc)
+		if err = pc.Retrieve(ctx, refs, []string{"name"}, &result); err != nil {
+			return err
+		}
+	}
+
+	return cmd.WriteResult(result)
+}
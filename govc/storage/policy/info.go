@@ -0,0 +1,159 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/pbm/types"
+)
+
+type info struct {
+	*flags.ClientFlag
+	*flags.OutputFlag
+}
+
+func init() {
+	cli.Register("storage.policy.info", &info{})
+}
+
+func (cmd *info) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+}
+
+func (cmd *info) Process(ctx context.Context) error {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	return cmd.OutputFlag.Process(ctx)
+}
+
+func (cmd *info) Usage() string {
+	return "[NAME]..."
+}
+
+func (cmd *info) Description() string {
+	return `Storage policy info.
+
+Examples:
+  govc storage.policy.info
+  govc storage.policy.info "vSAN Default Storage Policy"
+  govc storage.policy.info -json "vSAN Default Storage Policy" | jq .`
+}
+
+// ruleValue formats a capability property value.
+func ruleValue(val interface{}) string {
+	if set, ok := val.(*types.PbmCapabilityDiscreteSet); ok {
+		var values []string
+		for _, v := range set.Values {
+			values = append(values, fmt.Sprintf("%v", v))
+		}
+		return strings.Join(values, ",")
+	}
+	if set, ok := val.(types.PbmCapabilityDiscreteSet); ok {
+		return ruleValue(&set)
+	}
+	return fmt.Sprintf("%v", val)
+}
+
+// ruleList returns the rules of the given profile in NAMESPACE.ID=VALUE form.
+func ruleList(p types.BasePbmProfile) []string {
+	var rules []string
+
+	profile, ok := p.(*types.PbmCapabilityProfile)
+	if !ok {
+		return nil
+	}
+
+	constraints, ok := profile.Constraints.(*types.PbmCapabilitySubProfileConstraints)
+	if !ok {
+		return nil
+	}
+
+	for _, sub := range constraints.SubProfiles {
+		for _, capability := range sub.Capability {
+			for _, constraint := range capability.Constraint {
+				for _, prop := range constraint.PropertyInstance {
+					id := capability.Id.Id
+					if prop.Id != id {
+						id += "." + prop.Id
+					}
+					rules = append(rules, fmt.Sprintf("%s.%s=%s", capability.Id.Namespace, id, ruleValue(prop.Value)))
+				}
+			}
+		}
+	}
+
+	return rules
+}
+
+type infoResult struct {
+	Profile []types.BasePbmProfile
+}
+
+func (r *infoResult) Dump() interface{} {
+	return r.Profile
+}
+
+func (r *infoResult) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 2, 0, 2, ' ', 0)
+
+	for _, p := range r.Profile {
+		profile := p.GetPbmProfile()
+
+		fmt.Fprintf(tw, "Name:\t%s\n", profile.Name)
+		fmt.Fprintf(tw, "  ID:\t%s\n", profile.ProfileId.UniqueId)
+		fmt.Fprintf(tw, "  Description:\t%s\n", profile.Description)
+
+		if c, ok := p.(*types.PbmCapabilityProfile); ok {
+			fmt.Fprintf(tw, "  Category:\t%s\n", c.ProfileCategory)
+			fmt.Fprintf(tw, "  Default:\t%t\n", c.IsDefault)
+		}
+
+		fmt.Fprintf(tw, "  Rules:\n")
+		for _, rule := range ruleList(p) {
+			fmt.Fprintf(tw, "    %s\n", rule)
+		}
+	}
+
+	return tw.Flush()
+}
+
+func (cmd *info) Run(ctx context.Context, f *flag.FlagSet) error {
+	c, err := cmd.PbmClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := policies(ctx, c, f.Args()...)
+	if err != nil {
+		return err
+	}
+
+	return cmd.WriteResult(&infoResult{profiles})
+}
@@ -0,0 +1,155 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/pbm"
+	"github.com/vmware/govmomi/pbm/types"
+)
+
+type ls struct {
+	*flags.ClientFlag
+	*flags.OutputFlag
+
+	id bool
+}
+
+func init() {
+	cli.Register("storage.policy.ls", &ls{})
+}
+
+func (cmd *ls) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
+	f.BoolVar(&cmd.id, "i", false, "List policy ID only")
+}
+
+func (cmd *ls) Process(ctx context.Context) error {
+	if err := cmd.ClientFlag.Process(ctx); err != nil {
+		return err
+	}
+	return cmd.OutputFlag.Process(ctx)
+}
+
+func (cmd *ls) Usage() string {
+	return "[NAME]..."
+}
+
+func (cmd *ls) Description() string {
+	return `List storage policies.
+
+Examples:
+  govc storage.policy.ls
+  govc storage.policy.ls "vSAN Default Storage Policy"
+  govc storage.policy.ls -i "vSAN Default Storage Policy"`
+}
+
+// policies returns the storage policies matching the given names or IDs, or all policies if no names are given.
+func policies(ctx context.Context, c *pbm.Client, names ...string) ([]types.BasePbmProfile, error) {
+	rtype := types.PbmProfileResourceType{
+		ResourceType: string(types.PbmProfileResourceTypeEnumSTORAGE),
+	}
+
+	category := types.PbmProfileCategoryEnumREQUIREMENT
+
+	ids, err := c.QueryProfile(ctx, rtype, string(category))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		if len(names) != 0 {
+			return nil, fmt.Errorf("storage policy %q not found", names[0])
+		}
+		return nil, nil
+	}
+
+	profiles, err := c.RetrieveContent(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		return profiles, nil
+	}
+
+	var res []types.BasePbmProfile
+
+	for _, name := range names {
+		found := false
+
+		for _, p := range profiles {
+			profile := p.GetPbmProfile()
+			if profile.Name == name || profile.ProfileId.UniqueId == name {
+				res = append(res, p)
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("storage policy %q not found", name)
+		}
+	}
+
+	return res, nil
+}
+
+type lsResult struct {
+	Profile []types.BasePbmProfile
+	cmd     *ls
+}
+
+func (r *lsResult) Dump() interface{} {
+	return r.Profile
+}
+
+func (r *lsResult) Write(w io.Writer) error {
+	for _, p := range r.Profile {
+		profile := p.GetPbmProfile()
+		if r.cmd.id {
+			fmt.Fprintln(w, profile.ProfileId.UniqueId)
+		} else {
+			fmt.Fprintln(w, profile.Name)
+		}
+	}
+	return nil
+}
+
+func (cmd *ls) Run(ctx context.Context, f *flag.FlagSet) error {
+	c, err := cmd.PbmClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := policies(ctx, c, f.Args()...)
+	if err != nil {
+		return err
+	}
+
+	return cmd.WriteResult(&lsResult{profiles, cmd})
+}
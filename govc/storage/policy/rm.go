@@ -0,0 +1,100 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"reflect"
+
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/pbm/types"
+)
+
+type rm struct {
+	*flags.ClientFlag
+}
+
+func init() {
+	cli.Register("storage.policy.rm", &rm{})
+}
+
+func (cmd *rm) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+}
+
+func (cmd *rm) Usage() string {
+	return "NAME..."
+}
+
+func (cmd *rm) Description() string {
+	return `Remove storage policies.
+
+NAME can be a policy name or ID.
+
+Examples:
+  govc storage.policy.rm my-vsan-policy
+  govc storage.policy.rm $(govc storage.policy.ls -i my-vsan-policy)`
+}
+
+func (cmd *rm) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() == 0 {
+		return flag.ErrHelp
+	}
+
+	c, err := cmd.PbmClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := policies(ctx, c, f.Args()...)
+	if err != nil {
+		return err
+	}
+
+	var ids []types.PbmProfileId
+	names := make(map[string]string)
+
+	for _, p := range profiles {
+		profile := p.GetPbmProfile()
+		ids = append(ids, profile.ProfileId)
+		names[profile.ProfileId.UniqueId] = profile.Name
+	}
+
+	res, err := c.DeleteProfile(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, outcome := range res {
+		if outcome.Fault == nil {
+			continue
+		}
+
+		msg := outcome.Fault.LocalizedMessage
+		if msg == "" && outcome.Fault.Fault != nil {
+			msg = reflect.TypeOf(outcome.Fault.Fault).Elem().Name()
+		}
+
+		return fmt.Errorf("failed to remove %q: %s", names[outcome.ProfileId.UniqueId], msg)
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"flag"
+
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/pbm"
+	"github.com/vmware/govmomi/pbm/types"
+)
+
+type update struct {
+	*flags.ClientFlag
+
+	spec  types.PbmCapabilityProfileUpdateSpec
+	rules rules
+}
+
+func init() {
+	cli.Register("storage.policy.update", &update{})
+}
+
+func (cmd *update) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.ClientFlag, ctx = flags.NewClientFlag(ctx)
+	cmd.ClientFlag.Register(ctx, f)
+
+	f.StringVar(&cmd.spec.Name, "name", "", "New name")
+	f.StringVar(&cmd.spec.Description, "d", "", "Description")
+	f.Var(&cmd.rules, "rule", "Rule of the form NAMESPACE.ID=VALUE")
+}
+
+func (cmd *update) Usage() string {
+	return "NAME"
+}
+
+func (cmd *update) Description() string {
+	return `Update storage policy NAME.
+
+If any -rule flags are specified, the existing rules of the policy are replaced.
+
+Examples:
+  govc storage.policy.update -d "vSAN policy with striping" my-vsan-policy
+  govc storage.policy.update -rule VSAN.hostFailuresToTolerate=2 -rule VSAN.stripeWidth=4 my-vsan-policy
+  govc storage.policy.update -name my-renamed-policy my-vsan-policy`
+}
+
+func (cmd *update) Run(ctx context.Context, f *flag.FlagSet) error {
+	if f.NArg() != 1 {
+		return flag.ErrHelp
+	}
+
+	c, err := cmd.PbmClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := policies(ctx, c, f.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if len(cmd.rules) != 0 {
+		spec, err := pbm.CreateCapabilityProfileSpec(pbm.CapabilityProfileCreateSpec{CapabilityList: cmd.rules})
+		if err != nil {
+			return err
+		}
+		cmd.spec.Constraints = spec.Constraints
+	}
+
+	for _, p := range profiles {
+		err = c.UpdateProfile(ctx, p.GetPbmProfile().ProfileId, cmd.spec)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
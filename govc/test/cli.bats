@@ -151,3 +151,64 @@ load test_helper
   run govc vm.create -enoent
   assert_failure
 }
+
+@test "govc output format" {
+  vcsim_env
+
+  run govc vm.info -output.fields name,config.hardware.numCPU /DC0/vm/DC0_H0_VM0
+  assert_success
+  assert_line 0 "NAME CONFIG.HARDWARE.NUMCPU"
+  assert_line 1 "DC0_H0_VM0 1"
+
+  run govc vm.info -output.format csv -output.fields name,runtime.powerState '/DC0/vm/*'
+  assert_success
+  [ ${#lines[@]} -eq 5 ]
+  assert_line "DC0_H0_VM0,poweredOn"
+
+  run govc vm.info -output.format json -output.fields name /DC0/vm/DC0_H0_VM0
+  assert_success '[{"name":"DC0_H0_VM0"}]'
+
+  run govc host.info -output.format yaml -output.fields name,summary.hardware.numCpuCores /DC0/host/DC0_C0/DC0_C0_H0
+  assert_success
+  assert_line 0 "- name: DC0_C0_H0"
+  assert_line 1 "summary.hardware.numCpuCores: 2"
+
+  run govc datastore.info -output.format xml -output.fields name
+  assert_success
+  assert_matches "<name>LocalDS_0</name>"
+
+  run govc vm.info -output.template '{{.Name}}:{{.Config.Hardware.NumCPU}}' '/DC0/vm/*'
+  assert_success
+  assert_line "DC0_H0_VM0:1"
+
+  run govc ls -l -output.format table /DC0/vm
+  assert_success
+  assert_matches "^PATH +OBJECT.SELF.TYPE"
+  assert_matches "/DC0/vm/DC0_H0_VM0 +VirtualMachine"
+
+  run govc vm.info -format csv /DC0/vm/DC0_H0_VM0
+  assert_success
+  assert_line 0 "name,runtime.powerState,config.hardware.numCPU,config.hardware.memoryMB,guest.ipAddress"
+  assert_line 1 "DC0_H0_VM0,poweredOn,1,32,"
+
+  run govc host.info -format csv -fields name /DC0/host/DC0_C0/DC0_C0_H0
+  assert_success "name
+DC0_C0_H0"
+
+  run govc vm.info -template '{{.Name}}' /DC0/vm/DC0_H0_VM0
+  assert_success "DC0_H0_VM0"
+
+  run govc vm.info -output.format enoent /DC0/vm/DC0_H0_VM0
+  assert_failure
+
+  run govc vm.info -output.template '{{.Enoent}}' /DC0/vm/DC0_H0_VM0
+  assert_failure
+
+  run govc vm.clone -h
+  assert_success
+  assert_matches "Create a Template"
+  assert_matches "output.template"
+
+  run govc events -format jsonl -n 1 /DC0/vm/DC0_H0_VM0
+  assert_success
+}
@@ -86,3 +86,83 @@ load test_helper
   govc events 'vm/*'
   govc events -json 'vm/*' | jq .
 }
+
+@test "events filters" {
+  vcsim_env
+
+  vm=/DC0/vm/DC0_H0_VM0
+
+  run govc events -type VmCreatedEvent -category info $vm
+  assert_success
+  [ ${#lines[@]} -ge 1 ]
+
+  run govc events -type VmCreatedEvent -category error $vm
+  assert_success
+  [ ${#lines[@]} -eq 0 ]
+
+  run govc events -since 1h -until 2000-01-01T00:00:00Z $vm
+  assert_success
+  [ ${#lines[@]} -eq 0 ]
+
+  run govc events -since 2000-01-01T00:00:00Z $vm
+  assert_success
+  [ ${#lines[@]} -ge 1 ]
+
+  run govc events -since invalid $vm
+  assert_failure
+
+  run govc events -user enoent -type UserLoginSessionEvent
+  assert_success
+  [ ${#lines[@]} -eq 0 ]
+}
+
+@test "events checkpoint" {
+  vcsim_env
+
+  vm=/DC0/vm/DC0_H0_VM0
+  checkpoint=$BATS_TMPDIR/events-checkpoint.json
+  rm -f "$checkpoint"
+
+  run govc events -checkpoint "$checkpoint" $vm
+  assert_success
+  [ ${#lines[@]} -ge 1 ]
+
+  run govc events -checkpoint "$checkpoint" $vm
+  assert_success
+  [ ${#lines[@]} -eq 0 ]
+
+  run govc vm.power -off $vm
+  assert_success
+
+  run govc events -checkpoint "$checkpoint" $vm
+  assert_success
+  assert_matches "powered off"
+
+  rm -f "$checkpoint"
+}
+
+@test "events export" {
+  vcsim_env
+
+  vm=/DC0/vm/DC0_H0_VM0
+
+  run govc events -format jsonl -type VmCreatedEvent $vm
+  assert_success
+  [ "$(jq -r .entity <<<"$output")" = "$vm" ]
+  [ "$(jq -r .category <<<"$output")" = "info" ]
+
+  file=$BATS_TMPDIR/events.jsonl
+  rm -f "$file"
+
+  run govc events -format jsonl -sink "$file" $vm
+  assert_success
+  [ -z "$output" ]
+  [ "$(wc -l < "$file")" -ge 1 ]
+  rm -f "$file"
+
+  run govc events -format xml $vm
+  assert_failure
+
+  run govc events -sink "$file" $vm
+  assert_failure
+}
@@ -3,7 +3,7 @@
 load test_helper
 
 @test "extension" {
-  vcsim_env_todo
+  vcsim_env -tunnel 0
 
   govc extension.info | grep Name: | grep govc-test | awk '{print $2}' | $xargs -r govc extension.unregister
 
@@ -52,18 +52,17 @@ EOS
   assert_success
 
   # test client certificate authentication
-  (
-    # remove password from env, set user to extension id and turn of session cache
-    govc_url_to_vars
-    unset GOVC_PASSWORD
-    GOVC_USERNAME=$id
-    export GOVC_PERSIST_SESSION=false
-    run govc about -cert "${id}.crt" -key "${id}.key"
-    assert_success
-  )
+  run govc session.login -extension $id -cert "${id}.crt" -key "${id}.key"
+  assert_success
+
+  run govc extension.setcert -cert-pem ++ $id-other # generate a cert that is not registered
+  assert_success
+
+  run govc session.login -extension $id -cert "${id}-other.crt" -key "${id}-other.key"
+  assert_failure # thumbprint mismatch
 
   # remove generated cert and key
-  rm ${id}.{crt,key}
+  rm ${id}.{crt,key} ${id}-other.{crt,key}
 
   run govc extension.unregister $id
   assert_success
@@ -100,3 +100,51 @@ load test_helper
 
   govc object.collect -json "$moid" | jq .
 }
+
+@test "metric.collect" {
+  vcsim_env
+
+  run govc metric.collect -type HostSystem cpu.usage.average mem.consumed.average
+  assert_success
+  assert_matches "# TYPE vsphere_cpu_usage_average_ratio gauge"
+  assert_matches "vsphere_mem_consumed_average_bytes{entity="
+  assert_matches "# EOF"
+
+  run govc metric.collect -format csv -type HostSystem -intervals 20,300 host/DC0_C0 cpu.usage.average
+  assert_success
+  [ ${#lines[@]} -eq 7 ] # header + 3 hosts * 2 intervals
+
+  run govc metric.collect -format json -n 2 -period 10ms -type VirtualMachine vm cpu.usage.average
+  assert_success
+  [ ${#lines[@]} -eq 8 ] # 4 vms * 2 collections
+
+  run govc metric.collect -format xml cpu.usage.average
+  assert_failure
+
+  run govc metric.collect -intervals x cpu.usage.average
+  assert_failure
+}
+
+@test "metric.sample aggregate" {
+  vcsim_env
+
+  host=$(govc ls -t HostSystem host/DC0_C0 | head -n 1)
+
+  run govc metric.sample -aggregate "$host" cpu.usagemhz.average
+  assert_success
+  [ ${#lines[@]} -eq 1 ]
+  assert_matches " - "
+
+  run govc metric.sample -aggregate -stat p95 -n 30 "$host" cpu.usage.average mem.usage.average
+  assert_success
+  [ ${#lines[@]} -eq 2 ]
+
+  run govc metric.sample -stat rollup "$host" cpu.usage.average
+  assert_success
+
+  run govc metric.sample -stat p0 "$host" cpu.usage.average
+  assert_failure
+
+  run govc metric.sample -stat median "$host" cpu.usage.average
+  assert_failure
+}
@@ -139,6 +139,15 @@ load test_helper
   run govc object.collect -s -type ClusterComputeResource / configStatus
   assert_success green
 
+  run govc object.collect -s "/DC0/host/*" name # mixed types
+  assert_success
+  assert_equal 2 ${#lines[@]}
+
+  run govc object.collect -json "/DC0/host/*" name
+  assert_success
+  grep -q '"Type":"ComputeResource"' <<<"$output"
+  grep -q '"Type":"ClusterComputeResource"' <<<"$output"
+
   run govc object.collect -s -type ClusterComputeResource / effectiveRole # []int32 -> ArrayOfInt
   assert_number
 
@@ -490,6 +499,42 @@ EOF
   assert_failure # without Datacenter specified, there are 0 "vm" folders relative to the root folder
 }
 
+@test "object.find predicates" {
+  vcsim_env
+
+  run govc vm.power -off /DC0/vm/DC0_H0_VM1
+  assert_success
+
+  run govc find -type m -where runtime.powerState==poweredOff
+  assert_output "./vm/DC0_H0_VM1"
+
+  run govc find vm -type m -where '!(runtime.powerState==poweredOff) && summary.config.numCpu>=1'
+  assert_success
+  [ ${#lines[@]} -eq 3 ]
+
+  run govc find / -where 'name=~^DC0_C0_H[12]$' -where 'name!=*H2'
+  assert_output "/DC0/host/DC0_C0/DC0_C0_H1"
+
+  run govc find -type m -where "config.hardware.device.deviceInfo.label=='IDE 0'"
+  assert_success
+  [ ${#lines[@]} -eq 4 ]
+
+  run govc find -type m -where guest.net.ipAddress
+  assert_output ""
+
+  run govc find vm -type m -sort name -r -limit 2
+  assert_success "$(printf "vm/DC0_H0_VM1\nvm/DC0_H0_VM0")"
+
+  run govc find vm -type m -sort name -limit 1
+  assert_output "vm/DC0_C0_RP0_VM0"
+
+  run govc find -where 'name=~('
+  assert_failure
+
+  run govc find -where 'name==x)'
+  assert_failure
+}
+
 @test "object.method" {
   vcsim_env_todo
 
@@ -105,7 +105,19 @@ load test_helper
   run govc extension.setcert -cert-pem ++ "$id" # generate a cert for testing
   assert_success
 
-  # vcsim will login if any certificate is provided
+  run govc session.login -extension com.vmware.vsan.health -cert "$id.crt" -key "$id.key"
+  assert_failure # extension is not registered
+
+  run govc extension.register <<<'{"Key": "com.vmware.vsan.health", "Description": {"Label": "vsan", "Summary": "vSAN health"}, "Version": "1.0"}'
+  assert_success
+
+  run govc session.login -extension com.vmware.vsan.health -cert "$id.crt" -key "$id.key"
+  assert_failure # certificate is not set
+
+  run govc extension.setcert -cert-pem "$(cat "$id.crt")" com.vmware.vsan.health
+  assert_success
+
+  # vcsim will login if the certificate thumbprint matches that of the registered extension
   run govc session.login -extension com.vmware.vsan.health -cert "$id.crt" -key "$id.key"
   assert_success
 
@@ -33,3 +33,150 @@ load test_helper
   govc sso.service.ls -P vmomi | grep vcenterserver | grep -v https:
   govc sso.service.ls -P vmomi -l | grep https:
 }
+
+@test "sso.service.register" {
+  vcsim_env
+  govc_url_to_vars
+
+  spec='{"ServiceVersion": "1.0", "OwnerId": "govc@vsphere.local",
+    "ServiceType": {"Product": "com.example", "Type": "govc"},
+    "ServiceEndpoints": [{"Url": "https://127.0.0.1/govc", "EndpointType": {"Protocol": "http", "Type": "com.example.govc"}}]}'
+
+  run govc sso.service.register <<<"$spec"
+  assert_success
+  id="$output"
+
+  run govc sso.service.register -id "$id" <<<"$spec"
+  assert_failure # already exists
+
+  run govc sso.service.ls -t govc -U
+  assert_success "https://127.0.0.1/govc"
+
+  govc sso.service.ls -json -t govc | jq '.[0] | .ServiceVersion = "2.0"' | govc sso.service.update "$id"
+
+  run govc sso.service.ls -json -t govc
+  assert_success
+  assert_matches '"ServiceVersion":"2.0"'
+
+  run govc sso.service.update enoent <<<"$spec"
+  assert_failure # not found
+
+  run govc sso.service.unregister "$id"
+  assert_success
+
+  run govc sso.service.unregister "$id"
+  assert_failure # not found
+
+  [ -z "$(govc sso.service.ls -t govc)" ]
+}
+
+@test "sso.user" {
+  vcsim_env
+  govc_url_to_vars
+
+  run govc sso.user.id
+  assert_success
+  assert_matches "person=user@vsphere.local"
+
+  run govc sso.user.ls
+  assert_success
+  assert_matches Administrator
+
+  run govc sso.user.create govmomi
+  assert_failure # -p is required for person users
+
+  run govc sso.user.create -p password -d "govmomi test" govmomi
+  assert_success
+
+  run govc sso.user.create -p password govmomi
+  assert_failure # already exists
+
+  run govc sso.user.ls govmomi
+  assert_success "govmomi  govmomi test"
+
+  run govc sso.user.update -d "govmomi updated" -p changed govmomi
+  assert_success
+
+  run govc sso.user.ls govmomi
+  assert_success "govmomi  govmomi updated"
+
+  run env GOVC_USERNAME=govmomi GOVC_PASSWORD=password govc sso.user.id
+  assert_failure # password was changed
+
+  run env GOVC_USERNAME=govmomi GOVC_PASSWORD=changed govc session.login -issue
+  assert_success
+
+  id=$(new_id)
+  run govc extension.setcert -cert-pem ++ "$id" # generate a cert for testing
+  assert_success
+
+  run govc sso.user.create -C "$(cat "$id".crt)" -A -R Administrator govmomi-solution
+  assert_success
+
+  run govc sso.user.ls -s
+  assert_success
+  assert_matches govmomi-solution
+
+  run govc sso.user.id govmomi-solution
+  assert_success
+  assert_matches "solution=govmomi-solution@vsphere.local"
+
+  run govc sso.user.rm govmomi
+  assert_success
+
+  run govc sso.user.rm govmomi
+  assert_failure # not found
+
+  rm "$id".{crt,key}
+}
+
+@test "sso.token" {
+  vcsim_env
+  govc_url_to_vars
+
+  run govc sso.token.issue -delegatable
+  assert_success
+  bearer="$output"
+
+  run govc sso.token.inspect "$bearer"
+  assert_success
+  assert_matches "Subject: *user@VSPHERE.LOCAL"
+  assert_matches "Confirmation: *bearer"
+  assert_matches "Delegatable: *true"
+
+  govc sso.token.inspect -json - <<<"$bearer" | jq -r .Renewable | grep true
+
+  run govc sso.token.inspect invalid
+  assert_failure
+
+  run govc sso.token.renew "$bearer"
+  assert_failure # -cert is required
+
+  id=$(new_id)
+  run govc extension.setcert -cert-pem ++ "$id" # generate a cert for testing
+  assert_success
+
+  run govc sso.user.create -C "$(cat "$id".crt)" -A -R Administrator govmomi-solution
+  assert_success
+
+  run govc sso.token.issue -cert "$id.crt" -key "$id.key" -token "$bearer" -l
+  assert_success
+  assert_matches "Subject: *user@VSPHERE.LOCAL"
+  assert_matches "Confirmation: *holder-of-key"
+  assert_matches "Delegates: *govmomi-solution@VSPHERE.LOCAL"
+
+  run govc sso.token.issue -cert "$id.crt" -key "$id.key" -renewable=false
+  assert_success
+
+  run govc sso.token.renew -cert "$id.crt" -key "$id.key" "$output"
+  assert_failure # not renewable
+
+  hok=$(govc sso.token.issue -cert "$id.crt" -key "$id.key")
+
+  run govc sso.token.renew -cert "$id.crt" -key "$id.key" -lifetime 24h -l "$hok"
+  assert_success
+  assert_matches "Subject: *govmomi-solution@VSPHERE.LOCAL"
+  assert_matches "expires in 24h"
+
+  rm "$id".{crt,key}
+}
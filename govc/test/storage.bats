@@ -0,0 +1,85 @@
+#!/usr/bin/env bats
+
+load test_helper
+
+@test "storage.policy" {
+  vcsim_env
+  local output
+
+  run govc storage.policy.ls
+  assert_success
+
+  run govc storage.policy.info "vSAN Default Storage Policy"
+  assert_success
+  assert_matches "VSAN.hostFailuresToTolerate=1"
+
+  run govc storage.policy.ls enoent
+  assert_failure
+
+  run govc storage.policy.create -rule VSAN.hostFailuresToTolerate=2 -rule VSAN.stripeWidth=2 my-vsan-policy
+  assert_success
+
+  id="$output"
+  run govc storage.policy.ls -i my-vsan-policy
+  assert_output "$id"
+
+  run govc storage.policy.create my-vsan-policy
+  assert_failure # duplicate name
+
+  run govc storage.policy.create -rule VSAN.stripeWidth invalid-rule
+  assert_failure
+
+  run govc storage.policy.update -d "striped" -rule VSAN.stripeWidth=4 my-vsan-policy
+  assert_success
+
+  run govc storage.policy.info my-vsan-policy
+  assert_success
+  assert_matches "striped"
+  assert_matches "VSAN.stripeWidth=4"
+
+  run govc storage.policy.datastores my-vsan-policy
+  assert_success
+  assert_output "" # no vSAN datastores
+
+  run govc storage.policy.datastores -non my-vsan-policy
+  assert_success
+  assert_matches LocalDS_0
+
+  spec='{"CapabilityList": [{"Namespace": "com.example", "ID": "tier", "PropertyList": [{"ID": "tier", "Value": "gold", "DataType": "string"}]}]}'
+  run govc storage.policy.create -spec - my-policy <<<"$spec"
+  assert_success
+
+  run govc storage.policy.datastores my-policy
+  assert_success
+  assert_matches LocalDS_0
+
+  run govc vm.create -disk 1GB -policy my-policy -on=false my-vm
+  assert_success
+
+  run govc vm.disk.create -vm my-vm -name my-vm/disk2 -size 1G -policy my-policy
+  assert_success
+
+  run govc vm.disk.create -vm my-vm -name my-vm/disk3 -size 1G -policy enoent
+  assert_failure
+
+  run govc storage.policy.compliance my-vm
+  assert_success
+  assert_line "my-vm my-policy compliant"
+  assert_line "my-vm/disk-202-1 my-policy compliant"
+
+  run govc vm.create -disk 1GB -policy my-vsan-policy -on=false my-vsan-vm
+  assert_success
+
+  run govc storage.policy.compliance my-vsan-vm
+  assert_success
+  assert_line "my-vsan-vm my-vsan-policy nonCompliant" # no vSAN datastores
+
+  run govc storage.policy.rm "vSAN Default Storage Policy"
+  assert_failure # default policy
+
+  run govc storage.policy.rm my-vsan-policy "$(govc storage.policy.ls -i my-policy)"
+  assert_success
+
+  run govc storage.policy.ls my-vsan-policy
+  assert_failure
+}
@@ -221,6 +221,81 @@ load test_helper
   assert_failure
 }
 
+@test "vm.power multiple" {
+  vcsim_env -autostart=false
+
+  run govc vm.power -on DC0_H0_VM0
+  assert_success
+
+  # DC0_H0_VM0 is already on, the task error is ignored with -force
+  run govc vm.power -on DC0_H0_VM1 DC0_H0_VM0
+  assert_failure
+
+  run govc vm.power -off DC0_H0_VM1
+  assert_success
+
+  run govc vm.power -on -force DC0_H0_VM1 DC0_H0_VM0
+  assert_success
+  grep -q InvalidPowerState <<<"$output"
+
+  run govc vm.power -off DC0_H0_VM0 DC0_H0_VM1
+  assert_success
+
+  run govc vm.markastemplate DC0_C0_RP0_VM1
+  assert_success
+
+  # Failure to start powering on the template is not ignored with -force,
+  # the VM started before it is still waited on and the VM after it is not started
+  run govc vm.power -on -force DC0_H0_VM0 DC0_C0_RP0_VM1 DC0_H0_VM1
+  assert_failure
+
+  run govc find / -type m -runtime.powerState poweredOn
+  assert_success /DC0/vm/DC0_H0_VM0
+}
+
+@test "vm.power -parallel" {
+  vcsim_env -autostart=false
+
+  run govc vm.power -on -parallel 4 'DC0_H0_VM*'
+  assert_success
+
+  run govc find / -type m -name 'DC0_H0_VM*' -runtime.powerState poweredOff
+  assert_success ""
+
+  # DC0_H0_VM0 is already on, the task error is reported in the summary
+  run govc vm.power -on -parallel 2 -json DC0_H0_VM0
+  assert_failure
+  [ "$(jq -r '.results[0].status' <<<"$output")" = "failed" ]
+
+  # and ignored with -force
+  run govc vm.power -on -force -parallel 2 -json DC0_H0_VM0
+  assert_success
+  [ "$(jq -r '.results[0].status' <<<"$output")" = "ignored" ]
+  grep -q InvalidPowerState <<<"$output"
+
+  run govc vm.clone -vm DC0_H0_VM0 -on=false -parallel 2 clone-a clone-b
+  assert_success
+
+  # clone-a exists, clone-c is still created with -keep-going
+  run govc vm.clone -vm DC0_H0_VM0 -on=false -keep-going clone-a clone-c
+  assert_failure
+  grep -q "clone-a *failed" <<<"$output"
+
+  run govc vm.info clone-c
+  assert_success
+
+  run govc snapshot.create -vm 'clone-*' -parallel 3 -json snap
+  assert_success
+  [ "$(jq -r '[.results[].status] | unique | join(",")' <<<"$output")" = "ok" ]
+
+  run govc vm.destroy -parallel 3 -json 'clone-*'
+  assert_success
+  [ "$(jq '.results | length' <<<"$output")" = "3" ]
+
+  run govc ls vm/clone-*
+  assert_success ""
+}
+
 @test "vm.create pvscsi" {
   esx_env
 
@@ -805,3 +880,64 @@ load test_helper
   run govc vm.power -on "$id"
   assert_failure
 }
+
+@test "vm.apply" {
+  vcsim_env
+
+  id=$(new_id)
+  spec=$BATS_TMPDIR/$id.yaml
+
+  cat > "$spec" <<EOS
+name: $id
+cpus: 2
+memoryMB: 64
+disks:
+- size: 1GB
+networks:
+- network: VM Network
+cdroms:
+- iso: "[LocalDS_0] foo.iso"
+extraConfig:
+  foo: bar
+EOS
+
+  run govc vm.apply -f "$spec" -dry-run
+  assert_success
+  assert_line "add disk 1.0GB"
+
+  run govc vm.info "$id"
+  assert_success ""
+
+  run govc vm.apply -f "$spec"
+  assert_success
+
+  run govc device.info -vm "$id" disk-*
+  assert_success
+
+  run govc vm.apply -f "$spec" -dry-run
+  assert_success ""
+
+  sed -i -e 's/size: 1GB/size: 2GB/' -e 's/cpus: 2/cpus: 1/' -e 's/foo: bar/foo: baz/' "$spec"
+
+  run govc vm.apply -f "$spec" -dry-run -json
+  assert_success
+  [ "$(jq -r .action <<<"$output")" = "reconfigure" ]
+  [ "$(jq -r '.changes | length' <<<"$output")" = "3" ]
+
+  run govc vm.apply -f "$spec"
+  assert_success
+
+  run govc vm.info -r "$id"
+  assert_success
+  assert_line "CPU: 1 vCPU(s)"
+
+  sed -i -e 's/size: 2GB/size: 1GB/' "$spec"
+
+  run govc vm.apply -f "$spec"
+  assert_failure
+
+  run govc vm.apply -f "$spec" "$id"
+  assert_failure
+
+  rm "$spec"
+}
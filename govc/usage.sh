@@ -8,10 +8,16 @@ common_opts=$(cat <<EOF
   -cert=                    Certificate [GOVC_CERTIFICATE]
   -debug=false              Store debug logs [GOVC_DEBUG]
   -dump=false               Enable output dump
+  -fields=                  Alias for -output.fields
+  -format=                  Alias for -output.format
   -json=false               Enable JSON output
   -k=false                  Skip verification of server certificate [GOVC_INSECURE]
   -key=                     Private key [GOVC_PRIVATE_KEY]
+  -output.fields=           Output only the given comma separated FIELDS (e.g. name,config.hardware.numCPU)
+  -output.format=           Output format (json, yaml, xml, table, csv)
+  -output.template=         Output using the given Go text/template
   -persist-session=true     Persist session to disk [GOVC_PERSIST_SESSION]
+  -template=                Alias for -output.template
   -tls-ca-certs=            TLS CA certificates file [GOVC_TLS_CA_CERTS]
   -tls-known-hosts=         TLS known hosts file [GOVC_TLS_KNOWN_HOSTS]
   -u=                       ESX or vCenter URL [GOVC_URL]
@@ -45,8 +51,9 @@ printf "%s\n\`\`\`\n\n" "${common_opts}"
 
 cmds=($(govc -h | grep -v Usage))
 
-opts=($(cut -s -d= -f1 <<<"$common_opts" | xargs -n1 | sed -e 's/^/\\/'))
-filter=$(printf "|%s=" "${opts[@]}")
+# aliases are filtered by usage, as commands may define their own flags of the same name (e.g. events -format)
+opts=($(grep -v "Alias for" <<<"$common_opts" | cut -s -d= -f1 | xargs -n1 | sed -e 's/^/\\/'))
+filter=$(printf "|%s=" "${opts[@]}")"|Alias for -output\\."
 
 printf "<details><summary>Contents</summary>\n\n"
 for cmd in "${cmds[@]}" ; do
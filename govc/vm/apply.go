@@ -0,0 +1,492 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vm
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"text/tabwriter"
+
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/govc/cli"
+	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/units"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type apply struct {
+	*flags.DatacenterFlag
+	*flags.DatastoreFlag
+	*flags.ResourcePoolFlag
+	*flags.HostSystemFlag
+	*flags.FolderFlag
+	*flags.OutputFlag
+
+	file   string
+	dryRun bool
+}
+
+func init() {
+	cli.Register("vm.apply", &apply{})
+}
+
+func (cmd *apply) Register(ctx context.Context, f *flag.FlagSet) {
+	cmd.DatacenterFlag, ctx = flags.NewDatacenterFlag(ctx)
+	cmd.DatacenterFlag.Register(ctx, f)
+
+	cmd.DatastoreFlag, ctx = flags.NewDatastoreFlag(ctx)
+	cmd.DatastoreFlag.Register(ctx, f)
+
+	cmd.ResourcePoolFlag, ctx = flags.NewResourcePoolFlag(ctx)
+	cmd.ResourcePoolFlag.Register(ctx, f)
+
+	cmd.HostSystemFlag, ctx = flags.NewHostSystemFlag(ctx)
+	cmd.HostSystemFlag.Register(ctx, f)
+
+	cmd.FolderFlag, ctx = flags.NewFolderFlag(ctx)
+	cmd.FolderFlag.Register(ctx, f)
+
+	cmd.OutputFlag, ctx = flags.NewOutputFlag(ctx)
+	cmd.OutputFlag.Register(ctx, f)
+
+	f.StringVar(&cmd.file, "f", "", "VM spec file in YAML or JSON format, '-' for STDIN")
+	f.BoolVar(&cmd.dryRun, "dry-run", false, "Print the planned changes without applying them")
+}
+
+func (cmd *apply) Process(ctx context.Context) error {
+	if err := cmd.DatacenterFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.DatastoreFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.ResourcePoolFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.HostSystemFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.FolderFlag.Process(ctx); err != nil {
+		return err
+	}
+	if err := cmd.OutputFlag.Process(ctx); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cmd *apply) Description() string {
+	return `Apply the VM spec given by -f, creating or reconfiguring the VM as needed.
+
+The VM is created if it does not exist, using the placement fields to choose its folder, pool, host
+and datastore, which default to the -folder, -pool, -host and -ds flags. Otherwise, the spec is compared
+with the VM's current config and a single reconfigure task applies the difference.
+
+Only the fields given in the spec are managed. The disks, networks and cdroms lists, when given,
+describe the VM's complete set of those devices, such that an empty list removes them all. Existing
+disks can be grown but not shrunk. Disks removed from the spec are detached, leaving their files in
+place. ExtraConfig keys not listed in the spec are left as-is.
+
+If customization is given, the named customization spec is applied after the VM is created or
+reconfigured. If powerState is given, the VM is powered off before or powered on after the changes.
+
+Example spec:
+
+  name: my-vm
+  guestId: otherLinux64Guest
+  cpus: 2
+  memoryMB: 2048
+  disks:
+  - size: 20GB
+  - size: 100GB
+    controller: pvscsi
+    thin: false
+  networks:
+  - network: VM Network
+    adapter: vmxnet3
+  cdroms:
+  - iso: "[datastore1] iso/linux.iso"
+  extraConfig:
+    disk.enableUUID: "TRUE"
+  placement:
+    folder: vm/linux
+    pool: cluster1/Resources
+    datastore: datastore1
+  powerState: poweredOn
+
+Examples:
+  govc vm.apply -f my-vm.yaml -dry-run
+  govc vm.apply -f my-vm.yaml
+  cat my-vm.json | govc vm.apply -f -`
+}
+
+func (cmd *apply) spec() (*object.VirtualMachineSpec, error) {
+	var r io.Reader = os.Stdin
+
+	if cmd.file != "-" {
+		f, err := os.Open(cmd.file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := new(object.VirtualMachineSpec)
+	if err = flags.DecodeYAML(data, spec); err != nil {
+		return nil, fmt.Errorf("%s: %s", cmd.file, err)
+	}
+
+	if spec.Name == "" {
+		return nil, fmt.Errorf("%s: name is required", cmd.file)
+	}
+
+	switch types.VirtualMachinePowerState(spec.PowerState) {
+	case "", types.VirtualMachinePowerStatePoweredOn, types.VirtualMachinePowerStatePoweredOff:
+	default:
+		return nil, fmt.Errorf("%s: invalid powerState %q", cmd.file, spec.PowerState)
+	}
+
+	return spec, nil
+}
+
+func (cmd *apply) Run(ctx context.Context, f *flag.FlagSet) error {
+	if cmd.file == "" || f.NArg() != 0 {
+		return flag.ErrHelp
+	}
+
+	spec, err := cmd.spec()
+	if err != nil {
+		return err
+	}
+
+	finder, err := cmd.Finder()
+	if err != nil {
+		return err
+	}
+
+	for i, n := range spec.Networks {
+		net, err := finder.Network(ctx, n.Network)
+		if err != nil {
+			return err
+		}
+
+		spec.Networks[i].Backing, err = net.EthernetCardBackingInfo(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	vm, err := finder.VirtualMachine(ctx, path.Join(spec.Placement.Folder, spec.Name))
+	if err != nil {
+		if _, ok := err.(*find.NotFoundError); !ok {
+			return err
+		}
+	}
+
+	res := &applyResult{Name: spec.Name}
+
+	var config *types.VirtualMachineConfigInfo
+	var state types.VirtualMachinePowerState
+
+	if vm == nil {
+		res.Action = "create"
+		state = types.VirtualMachinePowerStatePoweredOff
+	} else {
+		var o mo.VirtualMachine
+		err = vm.Properties(ctx, vm.Reference(), []string{"config", "runtime.powerState"}, &o)
+		if err != nil {
+			return err
+		}
+		if o.Config == nil {
+			return fmt.Errorf("%s: config is not available", spec.Name)
+		}
+
+		res.Action = "reconfigure"
+		config = o.Config
+		state = o.Runtime.PowerState
+	}
+
+	res.Spec, err = spec.ConfigSpec(config)
+	if err != nil {
+		return err
+	}
+
+	res.changes(config, res.Spec)
+
+	if res.Spec == nil {
+		res.Action = "none"
+	} else if spec.Customization != "" {
+		res.Changes = append(res.Changes, applyChange{"customize", spec.Name, spec.Customization})
+	}
+
+	if spec.PowerState != "" && types.VirtualMachinePowerState(spec.PowerState) != state {
+		res.Changes = append(res.Changes, applyChange{"power", spec.Name, spec.PowerState})
+	}
+
+	if cmd.dryRun {
+		return cmd.WriteResult(res)
+	}
+
+	if state == types.VirtualMachinePowerStatePoweredOn && spec.PowerState == string(types.VirtualMachinePowerStatePoweredOff) {
+		if err = cmd.wait(ctx, "Powering off "+spec.Name+"... ", vm.PowerOff); err != nil {
+			return err
+		}
+	}
+
+	if res.Spec != nil {
+		if vm == nil {
+			vm, err = cmd.create(ctx, finder, spec, res.Spec)
+		} else {
+			err = cmd.wait(ctx, "Reconfiguring "+spec.Name+"... ", func(ctx context.Context) (*object.Task, error) {
+				return vm.Reconfigure(ctx, *res.Spec)
+			})
+		}
+		if err != nil {
+			return err
+		}
+
+		if spec.Customization != "" {
+			if err = cmd.customize(ctx, vm, spec.Customization); err != nil {
+				return err
+			}
+		}
+	}
+
+	if state == types.VirtualMachinePowerStatePoweredOff && spec.PowerState == string(types.VirtualMachinePowerStatePoweredOn) {
+		if err = cmd.wait(ctx, "Powering on "+spec.Name+"... ", vm.PowerOn); err != nil {
+			return err
+		}
+	}
+
+	return cmd.WriteResult(res)
+}
+
+func (cmd *apply) wait(ctx context.Context, msg string, f func(context.Context) (*object.Task, error)) error {
+	task, err := f(ctx)
+	if err != nil {
+		return err
+	}
+
+	logger := cmd.ProgressLogger(msg)
+	defer logger.Wait()
+
+	_, err = task.WaitForResult(ctx, logger)
+	return err
+}
+
+func (cmd *apply) create(ctx context.Context, finder *find.Finder, spec *object.VirtualMachineSpec, config *types.VirtualMachineConfigSpec) (*object.VirtualMachine, error) {
+	var folder *object.Folder
+	var host *object.HostSystem
+	var pool *object.ResourcePool
+	var datastore *object.Datastore
+	var err error
+
+	if spec.Placement.Folder != "" {
+		folder, err = finder.Folder(ctx, spec.Placement.Folder)
+	} else {
+		folder, err = cmd.FolderOrDefault("vm")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if spec.Placement.Host != "" {
+		host, err = finder.HostSystem(ctx, spec.Placement.Host)
+	} else {
+		host, err = cmd.HostSystemIfSpecified()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case spec.Placement.Pool != "":
+		pool, err = finder.ResourcePool(ctx, spec.Placement.Pool)
+	case host != nil:
+		pool, err = host.ResourcePool(ctx)
+	default:
+		pool, err = cmd.ResourcePool()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if spec.Placement.Datastore != "" {
+		datastore, err = finder.Datastore(ctx, spec.Placement.Datastore)
+	} else {
+		datastore, err = cmd.Datastore()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config.Files = &types.VirtualMachineFileInfo{
+		VmPathName: fmt.Sprintf("[%s]", datastore.Name()),
+	}
+
+	task, err := folder.CreateVM(ctx, *config, pool, host)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := cmd.ProgressLogger(fmt.Sprintf("Creating VM %s... ", spec.Name))
+	defer logger.Wait()
+
+	info, err := task.WaitForResult(ctx, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return object.NewVirtualMachine(folder.Client(), info.Result.(types.ManagedObjectReference)), nil
+}
+
+func (cmd *apply) customize(ctx context.Context, vm *object.VirtualMachine, name string) error {
+	item, err := object.NewCustomizationSpecManager(vm.Client()).GetCustomizationSpec(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	return cmd.wait(ctx, "Customizing "+vm.Name()+"... ", func(ctx context.Context) (*object.Task, error) {
+		return vm.Customize(ctx, item.Spec)
+	})
+}
+
+type applyChange struct {
+	Operation string `json:"operation"`
+	Target    string `json:"target"`
+	Value     string `json:"value"`
+}
+
+type applyResult struct {
+	Name    string                          `json:"name"`
+	Action  string                          `json:"action"`
+	Changes []applyChange                   `json:"changes"`
+	Spec    *types.VirtualMachineConfigSpec `json:"spec,omitempty"`
+}
+
+// changes describes the config spec changes in human-readable form.
+func (r *applyResult) changes(config *types.VirtualMachineConfigInfo, spec *types.VirtualMachineConfigSpec) {
+	if spec == nil {
+		return
+	}
+
+	set := func(name string, val interface{}) {
+		r.Changes = append(r.Changes, applyChange{"set", name, fmt.Sprint(val)})
+	}
+
+	if spec.Name != "" {
+		set("name", spec.Name)
+	}
+	if spec.GuestId != "" {
+		set("guestId", spec.GuestId)
+	}
+	if spec.NumCPUs != 0 {
+		set("cpus", spec.NumCPUs)
+	}
+	if spec.NumCoresPerSocket != 0 {
+		set("coresPerSocket", spec.NumCoresPerSocket)
+	}
+	if spec.MemoryMB != 0 {
+		set("memoryMB", spec.MemoryMB)
+	}
+	if spec.Firmware != "" {
+		set("firmware", spec.Firmware)
+	}
+	if spec.Annotation != "" {
+		set("annotation", spec.Annotation)
+	}
+	for _, o := range spec.ExtraConfig {
+		opt := o.GetOptionValue()
+		set("extraConfig."+opt.Key, opt.Value)
+	}
+
+	var current object.VirtualDeviceList
+	if config != nil {
+		current = object.VirtualDeviceList(config.Hardware.Device)
+	}
+
+	for _, c := range spec.DeviceChange {
+		dc := c.GetVirtualDeviceConfigSpec()
+		device := dc.Device
+		name := current.Type(device)
+		if dc.Operation != types.VirtualDeviceConfigSpecOperationAdd {
+			name = current.Name(device)
+		}
+
+		value := describeDevice(device)
+		if dc.Operation == types.VirtualDeviceConfigSpecOperationEdit {
+			if old := current.FindByKey(device.GetVirtualDevice().Key); old != nil {
+				value = describeDevice(old) + " -> " + value
+			}
+		}
+
+		r.Changes = append(r.Changes, applyChange{string(dc.Operation), name, value})
+	}
+}
+
+func describeDevice(device types.BaseVirtualDevice) string {
+	switch d := device.(type) {
+	case *types.VirtualDisk:
+		return units.ByteSize(d.CapacityInKB * 1024).String()
+	case *types.VirtualCdrom:
+		if iso, ok := d.Backing.(*types.VirtualCdromIsoBackingInfo); ok {
+			return iso.FileName
+		}
+		return "(empty)"
+	case types.BaseVirtualEthernetCard:
+		card := d.GetVirtualEthernetCard()
+		var summary string
+		switch b := card.Backing.(type) {
+		case *types.VirtualEthernetCardNetworkBackingInfo:
+			summary = b.DeviceName
+		case *types.VirtualEthernetCardDistributedVirtualPortBackingInfo:
+			summary = b.Port.PortgroupKey
+		case *types.VirtualEthernetCardOpaqueNetworkBackingInfo:
+			summary = b.OpaqueNetworkId
+		}
+		summary = object.VirtualDeviceList{}.TypeName(device) + " " + summary
+		if card.MacAddress != "" {
+			summary += " " + card.MacAddress
+		}
+		return summary
+	default:
+		return object.VirtualDeviceList{}.TypeName(device)
+	}
+}
+
+func (r *applyResult) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 2, 0, 2, ' ', 0)
+
+	for _, c := range r.Changes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Operation, c.Target, c.Value)
+	}
+
+	return tw.Flush()
+}
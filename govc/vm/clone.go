@@ -27,6 +27,7 @@ import (
 	"github.com/vmware/govmomi/property"
 	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/progress"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -40,8 +41,8 @@ type clone struct {
 	*flags.NetworkFlag
 	*flags.FolderFlag
 	*flags.VirtualMachineFlag
+	*flags.BatchFlag
 
-	name          string
 	memory        int
 	cpus          int
 	on            bool
@@ -95,6 +96,9 @@ func (cmd *clone) Register(ctx context.Context, f *flag.FlagSet) {
 	cmd.VirtualMachineFlag, ctx = flags.NewVirtualMachineFlag(ctx)
 	cmd.VirtualMachineFlag.Register(ctx, f)
 
+	cmd.BatchFlag, ctx = flags.NewBatchFlag(ctx)
+	cmd.BatchFlag.Register(ctx, f)
+
 	f.IntVar(&cmd.memory, "m", 0, "Size in MB of memory")
 	f.IntVar(&cmd.cpus, "c", 0, "Number of CPUs")
 	f.BoolVar(&cmd.on, "on", true, "Power on VM")
@@ -108,14 +112,17 @@ func (cmd *clone) Register(ctx context.Context, f *flag.FlagSet) {
 }
 
 func (cmd *clone) Usage() string {
-	return "NAME"
+	return "NAME..."
 }
 
 func (cmd *clone) Description() string {
 	return `Clone VM to NAME.
 
+Multiple NAME arguments create a clone for each, one at a time or in parallel with -parallel.
+
 Examples:
   govc vm.clone -vm template-vm new-vm
+  govc vm.clone -vm template-vm -parallel 10 -keep-going web-1 web-2 web-3
   govc vm.clone -vm template-vm -link new-vm
   govc vm.clone -vm template-vm -snapshot s-name new-vm
   govc vm.clone -vm template-vm -link -snapshot s-name new-vm
@@ -150,6 +157,9 @@ func (cmd *clone) Process(ctx context.Context) error {
 	if err := cmd.VirtualMachineFlag.Process(ctx); err != nil {
 		return err
 	}
+	if err := cmd.BatchFlag.Process(ctx); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -157,13 +167,14 @@ func (cmd *clone) Process(ctx context.Context) error {
 func (cmd *clone) Run(ctx context.Context, f *flag.FlagSet) error {
 	var err error
 
-	if len(f.Args()) != 1 {
+	if f.NArg() == 0 {
 		return flag.ErrHelp
 	}
 
-	cmd.name = f.Arg(0)
-	if cmd.name == "" {
-		return flag.ErrHelp
+	for _, name := range f.Args() {
+		if name == "" {
+			return flag.ErrHelp
+		}
 	}
 
 	cmd.Client, err = cmd.ClientFlag.Client()
@@ -216,7 +227,26 @@ func (cmd *clone) Run(ctx context.Context, f *flag.FlagSet) error {
 		return flag.ErrHelp
 	}
 
-	vm, err := cmd.cloneVM(ctx)
+	items := make([]flags.BatchItem, f.NArg())
+
+	for i, name := range f.Args() {
+		name := name
+
+		items[i] = flags.BatchItem{
+			Name:    name,
+			Message: fmt.Sprintf("Cloning %s to %s...", cmd.VirtualMachine.InventoryPath, name),
+			Run: func(ctx context.Context, s progress.Sinker) error {
+				return cmd.clone(ctx, name, s)
+			},
+		}
+	}
+
+	return cmd.RunBatch(ctx, items)
+}
+
+// clone creates the VM NAME, then applies the reconfigure and power flags.
+func (cmd *clone) clone(ctx context.Context, name string, s progress.Sinker) error {
+	vm, err := cmd.cloneVM(ctx, name, s)
 	if err != nil {
 		return err
 	}
@@ -234,7 +264,7 @@ func (cmd *clone) Run(ctx context.Context, f *flag.FlagSet) error {
 		if err != nil {
 			return err
 		}
-		_, err = task.WaitForResult(ctx, nil)
+		_, err = cmd.WaitForResult(ctx, task, nil)
 		if err != nil {
 			return err
 		}
@@ -246,7 +276,7 @@ func (cmd *clone) Run(ctx context.Context, f *flag.FlagSet) error {
 			return err
 		}
 
-		_, err = task.WaitForResult(ctx, nil)
+		_, err = cmd.WaitForResult(ctx, task, nil)
 		if err != nil {
 			return err
 		}
@@ -262,7 +292,7 @@ func (cmd *clone) Run(ctx context.Context, f *flag.FlagSet) error {
 	return nil
 }
 
-func (cmd *clone) cloneVM(ctx context.Context) (*object.VirtualMachine, error) {
+func (cmd *clone) cloneVM(ctx context.Context, name string, s progress.Sinker) (*object.VirtualMachine, error) {
 	devices, err := cmd.VirtualMachine.Device(ctx)
 	if err != nil {
 		return nil, err
@@ -349,7 +379,7 @@ func (cmd *clone) cloneVM(ctx context.Context) (*object.VirtualMachine, error) {
 		storagePlacementSpec := types.StoragePlacementSpec{
 			Folder:           &folderref,
 			Vm:               &vmref,
-			CloneName:        cmd.name,
+			CloneName:        name,
 			CloneSpec:        cloneSpec,
 			PodSelectionSpec: podSelectionSpec,
 			Type:             string(types.StoragePlacementSpecPlacementTypeClone),
@@ -381,7 +411,7 @@ func (cmd *clone) cloneVM(ctx context.Context) (*object.VirtualMachine, error) {
 
 	// Check if vmx already exists
 	if !cmd.force {
-		vmxPath := fmt.Sprintf("%s/%s.vmx", cmd.name, cmd.name)
+		vmxPath := fmt.Sprintf("%s/%s.vmx", name, name)
 
 		var mds mo.Datastore
 		err = property.DefaultCollector(cmd.Client).RetrieveOne(ctx, datastoreref, []string{"name"}, &mds)
@@ -421,15 +451,12 @@ func (cmd *clone) cloneVM(ctx context.Context) (*object.VirtualMachine, error) {
 		cloneSpec.Customization = &customSpec
 	}
 
-	task, err := cmd.VirtualMachine.Clone(ctx, cmd.Folder, cmd.name, *cloneSpec)
+	task, err := cmd.VirtualMachine.Clone(ctx, cmd.Folder, name, *cloneSpec)
 	if err != nil {
 		return nil, err
 	}
 
-	logger := cmd.ProgressLogger(fmt.Sprintf("Cloning %s to %s...", cmd.VirtualMachine.InventoryPath, cmd.name))
-	defer logger.Wait()
-
-	info, err := task.WaitForResult(ctx, logger)
+	info, err := cmd.WaitForResult(ctx, task, s)
 	if err != nil {
 		return nil, err
 	}
@@ -52,6 +52,7 @@ type create struct {
 	controller string
 	annotation string
 	firmware   string
+	policy     string
 
 	iso              string
 	isoDatastoreFlag *flags.DatastoreFlag
@@ -65,6 +66,8 @@ type create struct {
 	// doesn't exist yet and should be created
 	diskByteSize int64
 
+	profile []types.BaseVirtualMachineProfileSpec
+
 	Client       *vim25.Client
 	Datacenter   *object.Datacenter
 	Datastore    *object.Datastore
@@ -111,6 +114,7 @@ func (cmd *create) Register(ctx context.Context, f *flag.FlagSet) {
 	f.BoolVar(&cmd.force, "force", false, "Create VM if vmx already exists")
 	f.StringVar(&cmd.controller, "disk.controller", "scsi", "Disk controller type")
 	f.StringVar(&cmd.annotation, "annotation", "", "VM description")
+	f.StringVar(&cmd.policy, "policy", "", "Storage policy name")
 
 	firmwareTypes := []string{
 		string(types.GuestOsDescriptorFirmwareTypeBios),
@@ -178,7 +182,8 @@ http://pubs.vmware.com/vsphere-6-5/topic/com.vmware.wssdk.apiref.doc/vim.vm.Gues
 
 Examples:
   govc vm.create vm-name
-  govc vm.create -m 2048 -c 2 -g freebsd64Guest -net.adapter vmxnet3 -disk.controller pvscsi vm-name`
+  govc vm.create -m 2048 -c 2 -g freebsd64Guest -net.adapter vmxnet3 -disk.controller pvscsi vm-name
+  govc vm.create -disk 20GB -policy "vSAN Default Storage Policy" vm-name`
 }
 
 func (cmd *create) Run(ctx context.Context, f *flag.FlagSet) error {
@@ -235,6 +240,22 @@ func (cmd *create) Run(ctx context.Context, f *flag.FlagSet) error {
 		return err
 	}
 
+	if cmd.policy != "" {
+		c, err := cmd.PbmClient(ctx)
+		if err != nil {
+			return err
+		}
+
+		id, err := c.ProfileIDByName(ctx, cmd.policy)
+		if err != nil {
+			return err
+		}
+
+		cmd.profile = []types.BaseVirtualMachineProfileSpec{
+			&types.VirtualMachineDefinedProfileSpec{ProfileId: id},
+		}
+	}
+
 	// Verify ISO exists
 	if cmd.iso != "" {
 		_, err = cmd.isoDatastoreFlag.Stat(ctx, cmd.iso)
@@ -324,7 +345,14 @@ func (cmd *create) createVM(ctx context.Context) (*object.Task, error) {
 		return nil, err
 	}
 
+	for _, change := range deviceChange {
+		if change.GetVirtualDeviceConfigSpec().FileOperation == types.VirtualDeviceConfigSpecFileOperationCreate {
+			change.GetVirtualDeviceConfigSpec().Profile = cmd.profile
+		}
+	}
+
 	spec.DeviceChange = deviceChange
+	spec.VmProfile = cmd.profile
 
 	var datastore *object.Datastore
 
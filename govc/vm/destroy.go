@@ -22,11 +22,14 @@ import (
 
 	"github.com/vmware/govmomi/govc/cli"
 	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/progress"
 )
 
 type destroy struct {
 	*flags.ClientFlag
 	*flags.SearchFlag
+	*flags.BatchFlag
 }
 
 func init() {
@@ -39,6 +42,9 @@ func (cmd *destroy) Register(ctx context.Context, f *flag.FlagSet) {
 
 	cmd.SearchFlag, ctx = flags.NewSearchFlag(ctx, flags.SearchVirtualMachines)
 	cmd.SearchFlag.Register(ctx, f)
+
+	cmd.BatchFlag, ctx = flags.NewBatchFlag(ctx)
+	cmd.BatchFlag.Register(ctx, f)
 }
 
 func (cmd *destroy) Process(ctx context.Context) error {
@@ -48,6 +54,9 @@ func (cmd *destroy) Process(ctx context.Context) error {
 	if err := cmd.SearchFlag.Process(ctx); err != nil {
 		return err
 	}
+	if err := cmd.BatchFlag.Process(ctx); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -57,26 +66,45 @@ func (cmd *destroy) Run(ctx context.Context, f *flag.FlagSet) error {
 		return err
 	}
 
-	for _, vm := range vms {
-		task, err := vm.PowerOff(ctx)
-		if err != nil {
-			return err
-		}
+	items := make([]flags.BatchItem, len(vms))
 
-		// Ignore error since the VM may already been in powered off state.
-		// vm.Destroy will fail if the VM is still powered on.
-		_ = task.Wait(ctx)
+	for i := range vms {
+		vm := vms[i]
 
-		task, err = vm.Destroy(ctx)
-		if err != nil {
-			return err
+		items[i] = flags.BatchItem{
+			Name: vmName(vm),
+			Run: func(ctx context.Context, s progress.Sinker) error {
+				return cmd.destroyVM(ctx, vm, s)
+			},
 		}
+	}
 
-		err = task.Wait(ctx)
-		if err != nil {
-			return err
-		}
+	return cmd.RunBatch(ctx, items)
+}
+
+func (cmd *destroy) destroyVM(ctx context.Context, vm *object.VirtualMachine, s progress.Sinker) error {
+	task, err := vm.PowerOff(ctx)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	// Ignore error since the VM may already been in powered off state.
+	// vm.Destroy will fail if the VM is still powered on.
+	_, _ = cmd.WaitForResult(ctx, task, nil)
+
+	task, err = vm.Destroy(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = cmd.WaitForResult(ctx, task, s)
+	return err
+}
+
+// vmName returns the inventory path of vm, if known, otherwise its reference.
+func vmName(vm *object.VirtualMachine) string {
+	if vm.InventoryPath != "" {
+		return vm.InventoryPath
+	}
+	return vm.Reference().String()
 }
@@ -41,6 +41,7 @@ type create struct {
 	Eager      bool
 	DiskMode   string
 	Sharing    string
+	Policy     string
 }
 
 var vdmTypes = []string{
@@ -81,6 +82,7 @@ func (cmd *create) Register(ctx context.Context, f *flag.FlagSet) {
 	f.BoolVar(&cmd.Eager, "eager", false, "Eagerly scrub new disk")
 	f.StringVar(&cmd.DiskMode, "mode", vdmTypes[0], fmt.Sprintf("Disk mode (%s)", strings.Join(vdmTypes, "|")))
 	f.StringVar(&cmd.Sharing, "sharing", "", fmt.Sprintf("Sharing (%s)", strings.Join(sharing, "|")))
+	f.StringVar(&cmd.Policy, "policy", "", "Storage policy name")
 }
 
 func (cmd *create) Process(ctx context.Context) error {
@@ -101,7 +103,8 @@ func (cmd *create) Description() string {
 
 Examples:
   govc vm.disk.create -vm $name -name $name/disk1 -size 10G
-  govc vm.disk.create -vm $name -name $name/disk2 -size 10G -eager -thick -sharing sharingMultiWriter`
+  govc vm.disk.create -vm $name -name $name/disk2 -size 10G -eager -thick -sharing sharingMultiWriter
+  govc vm.disk.create -vm $name -name $name/disk3 -size 10G -policy "vSAN Default Storage Policy"`
 }
 
 func (cmd *create) Run(ctx context.Context, f *flag.FlagSet) error {
@@ -162,7 +165,44 @@ func (cmd *create) Run(ctx context.Context, f *flag.FlagSet) error {
 	backing.DiskMode = cmd.DiskMode
 	backing.Sharing = cmd.Sharing
 
+	var profile []types.BaseVirtualMachineProfileSpec
+
+	if cmd.Policy != "" {
+		c, err := cmd.PbmClient(ctx)
+		if err != nil {
+			return err
+		}
+
+		id, err := c.ProfileIDByName(ctx, cmd.Policy)
+		if err != nil {
+			return err
+		}
+
+		profile = append(profile, &types.VirtualMachineDefinedProfileSpec{ProfileId: id})
+	}
+
 	cmd.Log("Creating disk\n")
 	disk.CapacityInKB = int64(cmd.Bytes) / 1024
-	return vm.AddDevice(ctx, disk)
+
+	if profile == nil {
+		return vm.AddDevice(ctx, disk)
+	}
+
+	spec := types.VirtualMachineConfigSpec{
+		DeviceChange: []types.BaseVirtualDeviceConfigSpec{
+			&types.VirtualDeviceConfigSpec{
+				Operation:     types.VirtualDeviceConfigSpecOperationAdd,
+				FileOperation: types.VirtualDeviceConfigSpecFileOperationCreate,
+				Device:        disk,
+				Profile:       profile,
+			},
+		},
+	}
+
+	task, err := vm.Reconfigure(ctx, spec)
+	if err != nil {
+		return err
+	}
+
+	return task.Wait(ctx)
 }
@@ -283,6 +283,10 @@ func (r *infoResult) entityNames(refs []types.ManagedObjectReference) string {
 	return strings.Join(names, ", ")
 }
 
+func (r *infoResult) Columns() []string {
+	return []string{"name", "runtime.powerState", "config.hardware.numCPU", "config.hardware.memoryMB", "guest.ipAddress"}
+}
+
 func (r *infoResult) Write(w io.Writer) error {
 	// Maintain order via r.objects as Property collector does not always return results in order.
 	objects := make(map[types.ManagedObjectReference]mo.VirtualMachine, len(r.VirtualMachines))
@@ -24,6 +24,7 @@ import (
 	"github.com/vmware/govmomi/govc/cli"
 	"github.com/vmware/govmomi/govc/flags"
 	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/progress"
 	"github.com/vmware/govmomi/vim25/soap"
 	"github.com/vmware/govmomi/vim25/types"
 )
@@ -31,6 +32,7 @@ import (
 type power struct {
 	*flags.ClientFlag
 	*flags.SearchFlag
+	*flags.BatchFlag
 
 	On       bool
 	Off      bool
@@ -53,6 +55,9 @@ func (cmd *power) Register(ctx context.Context, f *flag.FlagSet) {
 	cmd.SearchFlag, ctx = flags.NewSearchFlag(ctx, flags.SearchVirtualMachines)
 	cmd.SearchFlag.Register(ctx, f)
 
+	cmd.BatchFlag, ctx = flags.NewBatchFlag(ctx)
+	cmd.BatchFlag.Register(ctx, f)
+
 	f.BoolVar(&cmd.On, "on", false, "Power on")
 	f.BoolVar(&cmd.Off, "off", false, "Power off")
 	f.BoolVar(&cmd.Reset, "reset", false, "Power reset")
@@ -70,6 +75,9 @@ func (cmd *power) Process(ctx context.Context) error {
 	if err := cmd.SearchFlag.Process(ctx); err != nil {
 		return err
 	}
+	if err := cmd.BatchFlag.Process(ctx); err != nil {
+		return err
+	}
 	opts := []bool{cmd.On, cmd.Off, cmd.Reset, cmd.Suspend, cmd.Reboot, cmd.Shutdown}
 	selected := false
 
@@ -141,57 +149,90 @@ func (cmd *power) Run(ctx context.Context, f *flag.FlagSet) error {
 		return err
 	}
 
-	for _, vm := range vms {
-		var task *object.Task
+	items := make([]flags.BatchItem, len(vms))
 
-		switch {
-		case cmd.On:
-			fmt.Fprintf(cmd, "Powering on %s... ", vm.Reference())
-			task, err = vm.PowerOn(ctx)
-		case cmd.Off:
-			fmt.Fprintf(cmd, "Powering off %s... ", vm.Reference())
-			task, err = vm.PowerOff(ctx)
-		case cmd.Reset:
-			fmt.Fprintf(cmd, "Reset %s... ", vm.Reference())
-			task, err = vm.Reset(ctx)
-		case cmd.Suspend:
-			fmt.Fprintf(cmd, "Suspend %s... ", vm.Reference())
-			task, err = vm.Suspend(ctx)
-		case cmd.Reboot:
-			fmt.Fprintf(cmd, "Reboot guest %s... ", vm.Reference())
-			err = vm.RebootGuest(ctx)
-
-			if err != nil && cmd.Force && isToolsUnavailable(err) {
-				task, err = vm.Reset(ctx)
-			}
-		case cmd.Shutdown:
-			fmt.Fprintf(cmd, "Shutdown guest %s... ", vm.Reference())
-			err = vm.ShutdownGuest(ctx)
+	for i := range vms {
+		vm := vms[i]
 
-			if err != nil && cmd.Force && isToolsUnavailable(err) {
-				task, err = vm.PowerOff(ctx)
-			}
+		items[i] = flags.BatchItem{
+			Name: vmName(vm),
+			Run: func(ctx context.Context, s progress.Sinker) error {
+				return cmd.power(ctx, vm, s)
+			},
 		}
+	}
 
-		if err != nil {
-			return err
-		}
+	return cmd.RunBatch(ctx, items)
+}
 
-		if task != nil {
-			err = task.Wait(ctx)
-		}
-		if err == nil {
+// power runs the selected power operation for vm, logging the result when not in batch mode.
+// A failure to start the operation is returned even with -force, but a task error is ignored.
+func (cmd *power) power(ctx context.Context, vm *object.VirtualMachine, s progress.Sinker) error {
+	batch := cmd.Batch()
+
+	msg, task, err := cmd.start(ctx, vm)
+	if !batch {
+		fmt.Fprint(cmd, msg)
+	}
+	if err != nil {
+		return err
+	}
+
+	if task != nil {
+		_, err = cmd.WaitForResult(ctx, task, s)
+	}
+
+	if err == nil {
+		if !batch {
 			fmt.Fprintf(cmd, "OK\n")
-			continue
 		}
+		return nil
+	}
 
-		if cmd.Force {
+	if cmd.Force {
+		if !batch {
 			fmt.Fprintf(cmd, "Error: %s\n", err)
-			continue
 		}
+		return &flags.IgnoredError{Err: err}
+	}
 
-		return err
+	return err
+}
+
+// start begins the selected power operation for vm, returning a task if the operation has one.
+func (cmd *power) start(ctx context.Context, vm *object.VirtualMachine) (string, *object.Task, error) {
+	var task *object.Task
+	var msg string
+	var err error
+
+	switch {
+	case cmd.On:
+		msg = fmt.Sprintf("Powering on %s... ", vm.Reference())
+		task, err = vm.PowerOn(ctx)
+	case cmd.Off:
+		msg = fmt.Sprintf("Powering off %s... ", vm.Reference())
+		task, err = vm.PowerOff(ctx)
+	case cmd.Reset:
+		msg = fmt.Sprintf("Reset %s... ", vm.Reference())
+		task, err = vm.Reset(ctx)
+	case cmd.Suspend:
+		msg = fmt.Sprintf("Suspend %s... ", vm.Reference())
+		task, err = vm.Suspend(ctx)
+	case cmd.Reboot:
+		msg = fmt.Sprintf("Reboot guest %s... ", vm.Reference())
+		err = vm.RebootGuest(ctx)
+
+		if err != nil && cmd.Force && isToolsUnavailable(err) {
+			task, err = vm.Reset(ctx)
+		}
+	case cmd.Shutdown:
+		msg = fmt.Sprintf("Shutdown guest %s... ", vm.Reference())
+		err = vm.ShutdownGuest(ctx)
+
+		if err != nil && cmd.Force && isToolsUnavailable(err) {
+			task, err = vm.PowerOff(ctx)
+		}
 	}
 
-	return nil
+	return msg, task, err
 }
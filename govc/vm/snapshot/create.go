@@ -22,10 +22,12 @@ import (
 
 	"github.com/vmware/govmomi/govc/cli"
 	"github.com/vmware/govmomi/govc/flags"
+	"github.com/vmware/govmomi/vim25/progress"
 )
 
 type create struct {
 	*flags.VirtualMachineFlag
+	*flags.BatchFlag
 
 	description string
 	memory      bool
@@ -40,6 +42,9 @@ func (cmd *create) Register(ctx context.Context, f *flag.FlagSet) {
 	cmd.VirtualMachineFlag, ctx = flags.NewVirtualMachineFlag(ctx)
 	cmd.VirtualMachineFlag.Register(ctx, f)
 
+	cmd.BatchFlag, ctx = flags.NewBatchFlag(ctx)
+	cmd.BatchFlag.Register(ctx, f)
+
 	f.BoolVar(&cmd.memory, "m", true, "Include memory state")
 	f.BoolVar(&cmd.quiesce, "q", false, "Quiesce guest file system")
 	f.StringVar(&cmd.description, "d", "", "Snapshot description")
@@ -52,14 +57,20 @@ func (cmd *create) Usage() string {
 func (cmd *create) Description() string {
 	return `Create snapshot of VM with NAME.
 
+The -vm flag may match multiple VMs, each of which is snapshot in turn, or in parallel with -parallel.
+
 Examples:
-  govc snapshot.create -vm my-vm happy-vm-state`
+  govc snapshot.create -vm my-vm happy-vm-state
+  govc snapshot.create -vm 'web-*' -parallel 10 -keep-going before-upgrade`
 }
 
 func (cmd *create) Process(ctx context.Context) error {
 	if err := cmd.VirtualMachineFlag.Process(ctx); err != nil {
 		return err
 	}
+	if err := cmd.BatchFlag.Process(ctx); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -68,19 +79,38 @@ func (cmd *create) Run(ctx context.Context, f *flag.FlagSet) error {
 		return flag.ErrHelp
 	}
 
-	vm, err := cmd.VirtualMachine()
+	vms, err := cmd.VirtualMachineList()
 	if err != nil {
 		return err
 	}
 
-	if vm == nil {
+	if len(vms) == 0 {
 		return flag.ErrHelp
 	}
 
-	task, err := vm.CreateSnapshot(ctx, f.Arg(0), cmd.description, cmd.memory, cmd.quiesce)
-	if err != nil {
-		return err
+	items := make([]flags.BatchItem, len(vms))
+
+	for i := range vms {
+		vm := vms[i]
+
+		name := vm.InventoryPath
+		if name == "" {
+			name = vm.Reference().String()
+		}
+
+		items[i] = flags.BatchItem{
+			Name: name,
+			Run: func(ctx context.Context, s progress.Sinker) error {
+				task, err := vm.CreateSnapshot(ctx, f.Arg(0), cmd.description, cmd.memory, cmd.quiesce)
+				if err != nil {
+					return err
+				}
+
+				_, err = cmd.WaitForResult(ctx, task, s)
+				return err
+			},
+		}
 	}
 
-	return task.Wait(ctx)
+	return cmd.RunBatch(ctx, items)
 }
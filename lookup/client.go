@@ -92,6 +92,58 @@ func (c *Client) SiteID(ctx context.Context) (string, error) {
 	return res.Returnval, nil
 }
 
+// Create registers a service with the given ID and spec.
+// Note that the request must be signed with a SAML token, see sts.Signer.
+func (c *Client) Create(ctx context.Context, id string, spec types.LookupServiceRegistrationCreateSpec) error {
+	req := types.Create{
+		This:       *c.ServiceContent.ServiceRegistration,
+		ServiceId:  id,
+		CreateSpec: spec,
+	}
+
+	_, err := methods.Create(ctx, c, &req)
+	return err
+}
+
+// Set updates the mutable info of the service with the given ID.
+// Note that the request must be signed with a SAML token, see sts.Signer.
+func (c *Client) Set(ctx context.Context, id string, spec types.LookupServiceRegistrationSetSpec) error {
+	req := types.Set{
+		This:        *c.ServiceContent.ServiceRegistration,
+		ServiceId:   id,
+		ServiceSpec: spec,
+	}
+
+	_, err := methods.Set(ctx, c, &req)
+	return err
+}
+
+// Delete unregisters the service with the given ID.
+// Note that the request must be signed with a SAML token, see sts.Signer.
+func (c *Client) Delete(ctx context.Context, id string) error {
+	req := types.Delete{
+		This:      *c.ServiceContent.ServiceRegistration,
+		ServiceId: id,
+	}
+
+	_, err := methods.Delete(ctx, c, &req)
+	return err
+}
+
+// Get returns the registration info of the service with the given ID.
+func (c *Client) Get(ctx context.Context, id string) (*types.LookupServiceRegistrationInfo, error) {
+	req := types.Get{
+		This:      *c.ServiceContent.ServiceRegistration,
+		ServiceId: id,
+	}
+
+	res, err := methods.Get(ctx, c, &req)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Returnval, nil
+}
+
 // EndpointURL uses the Lookup Service to find the endpoint URL and thumbprint for the given filter.
 // If the endpoint is found, its TLS certificate is also added to the vim25.Client's trusted host thumbprints.
 // If the Lookup Service is not available, the given path is returned as the default.
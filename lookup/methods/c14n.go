@@ -0,0 +1,49 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package methods
+
+import (
+	"bytes"
+
+	"github.com/vmware/govmomi/vim25/xml"
+)
+
+// The ServiceRegistration Create, Set and Delete methods require a request signed with a SAML token.
+// Their C14N methods return the canonicalized form of the request, for use by sts.Signer
+
+func c14n(name string, req interface{}) string {
+	var buf bytes.Buffer
+
+	start := xml.StartElement{Name: xml.Name{Space: "urn:lookup", Local: name}}
+	if err := xml.NewEncoder(&buf).EncodeElement(req, start); err != nil {
+		panic(err)
+	}
+
+	return buf.String()
+}
+
+func (b *CreateBody) C14N() string {
+	return c14n("Create", b.Req)
+}
+
+func (b *SetBody) C14N() string {
+	return c14n("Set", b.Req)
+}
+
+func (b *DeleteBody) C14N() string {
+	return c14n("Delete", b.Req)
+}
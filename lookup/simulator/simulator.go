@@ -17,6 +17,8 @@ limitations under the License.
 package simulator
 
 import (
+	"fmt"
+
 	"github.com/vmware/govmomi/lookup"
 	"github.com/vmware/govmomi/lookup/methods"
 	"github.com/vmware/govmomi/lookup/types"
@@ -156,3 +158,107 @@ func (s *ServiceRegistration) List(req *types.List) soap.HasFault {
 
 	return body
 }
+
+// authenticated returns true if the request header contains a SAML token with a Subject.
+// Create, Set and Delete requests must be signed by a token.
+func authenticated(ctx *simulator.Context) bool {
+	var subject struct {
+		ID string `xml:"Assertion>Subject>NameID"`
+	}
+
+	if e, ok := ctx.Header.Security.(*simulator.Element); ok {
+		_ = e.Decode(&subject)
+	}
+
+	return subject.ID != ""
+}
+
+func (s *ServiceRegistration) find(id string) int {
+	for i := range s.Info {
+		if s.Info[i].ServiceId == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func notFound(id string) *soap.Fault {
+	return simulator.Fault(fmt.Sprintf("service %q not found", id), &types.LookupFaultEntryNotFoundFault{Name: id})
+}
+
+func (s *ServiceRegistration) Create(ctx *simulator.Context, req *types.Create) soap.HasFault {
+	body := new(methods.CreateBody)
+
+	if !authenticated(ctx) {
+		body.Fault_ = simulator.Fault("", new(vim.NotAuthenticated))
+		return body
+	}
+
+	if s.find(req.ServiceId) != -1 {
+		msg := fmt.Sprintf("service %q already exists", req.ServiceId)
+		body.Fault_ = simulator.Fault(msg, &types.LookupFaultEntryExistsFault{Name: req.ServiceId})
+		return body
+	}
+
+	s.Info = append(s.Info, types.LookupServiceRegistrationInfo{
+		LookupServiceRegistrationCommonServiceInfo: req.CreateSpec.LookupServiceRegistrationCommonServiceInfo,
+		ServiceId: req.ServiceId,
+		SiteId:    siteID,
+	})
+
+	body.Res = new(types.CreateResponse)
+	return body
+}
+
+func (s *ServiceRegistration) Set(ctx *simulator.Context, req *types.Set) soap.HasFault {
+	body := new(methods.SetBody)
+
+	if !authenticated(ctx) {
+		body.Fault_ = simulator.Fault("", new(vim.NotAuthenticated))
+		return body
+	}
+
+	i := s.find(req.ServiceId)
+	if i == -1 {
+		body.Fault_ = notFound(req.ServiceId)
+		return body
+	}
+
+	s.Info[i].LookupServiceRegistrationMutableServiceInfo = req.ServiceSpec.LookupServiceRegistrationMutableServiceInfo
+
+	body.Res = new(types.SetResponse)
+	return body
+}
+
+func (s *ServiceRegistration) Delete(ctx *simulator.Context, req *types.Delete) soap.HasFault {
+	body := new(methods.DeleteBody)
+
+	if !authenticated(ctx) {
+		body.Fault_ = simulator.Fault("", new(vim.NotAuthenticated))
+		return body
+	}
+
+	i := s.find(req.ServiceId)
+	if i == -1 {
+		body.Fault_ = notFound(req.ServiceId)
+		return body
+	}
+
+	s.Info = append(s.Info[:i], s.Info[i+1:]...)
+
+	body.Res = new(types.DeleteResponse)
+	return body
+}
+
+func (s *ServiceRegistration) Get(req *types.Get) soap.HasFault {
+	body := new(methods.GetBody)
+
+	i := s.find(req.ServiceId)
+	if i == -1 {
+		body.Fault_ = notFound(req.ServiceId)
+		return body
+	}
+
+	body.Res = &types.GetResponse{Returnval: s.Info[i]}
+	return body
+}
@@ -25,6 +25,8 @@ import (
 	"github.com/vmware/govmomi/lookup"
 	"github.com/vmware/govmomi/lookup/types"
 	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/sts"
+	"github.com/vmware/govmomi/vim25/soap"
 )
 
 func TestClient(t *testing.T) {
@@ -141,3 +143,123 @@ func TestClient(t *testing.T) {
 		}
 	}
 }
+
+func TestRegistration(t *testing.T) {
+	ctx := context.Background()
+
+	model := simulator.VPX()
+
+	defer model.Remove()
+	err := model.Create()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	s := model.Service.NewServer()
+	defer s.Close()
+
+	model.Service.RegisterSDK(New())
+
+	vc, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := lookup.NewClient(ctx, vc.Client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := "govmomi-test"
+	spec := types.LookupServiceRegistrationCreateSpec{
+		LookupServiceRegistrationCommonServiceInfo: types.LookupServiceRegistrationCommonServiceInfo{
+			LookupServiceRegistrationMutableServiceInfo: types.LookupServiceRegistrationMutableServiceInfo{
+				ServiceVersion: "1.0",
+				ServiceEndpoints: []types.LookupServiceRegistrationEndpoint{
+					{
+						Url: "https://127.0.0.1/govmomi",
+						EndpointType: types.LookupServiceRegistrationEndpointType{
+							Protocol: "http",
+							Type:     "com.example.govmomi",
+						},
+					},
+				},
+			},
+			OwnerId: "govmomi-test@vsphere.local",
+			ServiceType: types.LookupServiceRegistrationServiceType{
+				Product: "com.example",
+				Type:    "govmomi",
+			},
+		},
+	}
+
+	if err = c.Create(ctx, id, spec); err == nil {
+		t.Error("expected error") // request must be signed
+	}
+
+	header := soap.Header{
+		Security: &sts.Signer{Token: "<Assertion><Subject><NameID>govmomi-test@vsphere.local</NameID></Subject></Assertion>"},
+	}
+	signed := c.WithHeader(ctx, header)
+
+	if err = c.Create(signed, id, spec); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = c.Create(signed, id, spec); err == nil {
+		t.Error("expected error") // EntryExists
+	}
+
+	info, err := c.Get(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.SiteId != siteID || info.ServiceType.Type != "govmomi" || len(info.ServiceEndpoints) != 1 {
+		t.Errorf("info=%#v", info)
+	}
+
+	filter := &types.LookupServiceRegistrationFilter{
+		ServiceType: &types.LookupServiceRegistrationServiceType{Type: "govmomi"},
+	}
+
+	list, err := c.List(ctx, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ServiceId != id {
+		t.Errorf("list=%#v", list)
+	}
+
+	update := types.LookupServiceRegistrationSetSpec{
+		LookupServiceRegistrationMutableServiceInfo: spec.LookupServiceRegistrationMutableServiceInfo,
+	}
+	update.ServiceVersion = "2.0"
+
+	if err = c.Set(signed, id, update); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = c.Set(signed, "enoent", update); err == nil {
+		t.Error("expected error") // EntryNotFound
+	}
+
+	info, err = c.Get(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.ServiceVersion != "2.0" || info.OwnerId != spec.OwnerId {
+		t.Errorf("info=%#v", info)
+	}
+
+	if err = c.Delete(ctx, id); err == nil {
+		t.Error("expected error") // request must be signed
+	}
+
+	if err = c.Delete(signed, id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.Get(ctx, id); err == nil {
+		t.Error("expected error") // EntryNotFound
+	}
+}
@@ -100,8 +100,12 @@ func (c Common) ObjectName(ctx context.Context) (string, error) {
 	return n.Name, nil
 }
 
+// Properties retrieves the given properties of r into dst.
+// As with property.Collector.RetrieveByType, dst can be a pointer to a typed struct or slice, a []interface{},
+// or a map of type name to typed slice pointer.
 func (c Common) Properties(ctx context.Context, r types.ManagedObjectReference, ps []string, dst interface{}) error {
-	return property.DefaultCollector(c.c).RetrieveOne(ctx, r, ps, dst)
+	refs := []types.ManagedObjectReference{r}
+	return property.DefaultCollector(c.c).RetrieveByType(ctx, refs, map[string][]string{r.Type: ps}, dst)
 }
 
 func (c Common) Destroy(ctx context.Context) (*Task, error) {
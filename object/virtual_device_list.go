@@ -933,3 +933,189 @@ func (l VirtualDeviceList) ConfigSpec(op types.VirtualDeviceConfigSpecOperation)
 
 	return res, nil
 }
+
+// backingID returns a string identifying the file, device or network of the given backing,
+// or an empty string if the backing does not refer to one.
+func backingID(backing types.BaseVirtualDeviceBackingInfo) string {
+	switch b := backing.(type) {
+	case *types.VirtualEthernetCardDistributedVirtualPortBackingInfo:
+		return b.Port.SwitchUuid + "/" + b.Port.PortgroupKey
+	case *types.VirtualEthernetCardOpaqueNetworkBackingInfo:
+		return b.OpaqueNetworkType + "/" + b.OpaqueNetworkId
+	case types.BaseVirtualDeviceFileBackingInfo:
+		return b.GetVirtualDeviceFileBackingInfo().FileName
+	case types.BaseVirtualDeviceDeviceBackingInfo:
+		return b.GetVirtualDeviceDeviceBackingInfo().DeviceName
+	case types.BaseVirtualDeviceURIBackingInfo:
+		return b.GetVirtualDeviceURIBackingInfo().ServiceURI
+	case types.BaseVirtualDevicePipeBackingInfo:
+		return b.GetVirtualDevicePipeBackingInfo().PipeName
+	}
+
+	return ""
+}
+
+// copyDevice returns a shallow copy of device, such that changes to its fields are not seen by the original.
+func copyDevice(device types.BaseVirtualDevice) types.BaseVirtualDevice {
+	val := reflect.ValueOf(device).Elem()
+	c := reflect.New(val.Type())
+	c.Elem().Set(val)
+	return c.Interface().(types.BaseVirtualDevice)
+}
+
+// match returns the device in l that corresponds to the given device, or nil if there is none.
+// Devices are matched by key if the key is in use by a device of the same type,
+// otherwise by type and backing.
+func (l VirtualDeviceList) match(device types.BaseVirtualDevice, matched map[int32]bool) types.BaseVirtualDevice {
+	d := device.GetVirtualDevice()
+	name := l.TypeName(device)
+
+	if d.Key > 0 && !matched[d.Key] {
+		if c := l.FindByKey(d.Key); c != nil && l.TypeName(c) == name {
+			return c
+		}
+	}
+
+	id := backingID(d.Backing)
+	if id == "" {
+		return nil
+	}
+
+	for _, c := range l {
+		key := c.GetVirtualDevice().Key
+		if !matched[key] && l.TypeName(c) == name && backingID(c.GetVirtualDevice().Backing) == id {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// Diff returns the device changes needed to reconfigure a virtual machine with the current device list l
+// to the desired device list. Desired devices are matched with current devices by key, or by type and
+// backing, such that a desired list may be built from scratch or from a modified copy of the current list.
+// Matched devices that differ are edited and current devices without a match are removed, leaving any
+// backing files in place. Desired devices without a match are added, with the same file operation as
+// ConfigSpec. Added devices are given negative keys, with any ControllerKey referring to the key of an
+// added controller updated to match. Added devices without a UnitNumber are assigned to their controller
+// via AssignController. Disks and cdroms without a ControllerKey are assigned to an available disk or IDE
+// controller. The devices in the desired list are not modified.
+func (l VirtualDeviceList) Diff(desired VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	var remove, edit, add []types.BaseVirtualDeviceConfigSpec
+	var added VirtualDeviceList
+
+	matched := make(map[int32]bool)
+
+	for _, device := range desired {
+		current := l.match(device, matched)
+		if current == nil {
+			added = append(added, copyDevice(device))
+			continue
+		}
+
+		c := current.GetVirtualDevice()
+		matched[c.Key] = true
+
+		device = copyDevice(device)
+		d := device.GetVirtualDevice()
+		d.Key = c.Key
+		if d.ControllerKey == 0 {
+			d.ControllerKey = c.ControllerKey
+		}
+		if d.UnitNumber == nil {
+			d.UnitNumber = c.UnitNumber
+		}
+		if d.DeviceInfo == nil {
+			d.DeviceInfo = c.DeviceInfo
+		}
+
+		if !reflect.DeepEqual(current, device) {
+			edit = append(edit, &types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationEdit,
+				Device:    device,
+			})
+		}
+	}
+
+	for _, device := range l {
+		if !matched[device.GetVirtualDevice().Key] {
+			remove = append(remove, &types.VirtualDeviceConfigSpec{
+				Operation: types.VirtualDeviceConfigSpecOperationRemove,
+				Device:    device,
+			})
+		}
+	}
+
+	// Controllers are added first, as other devices may refer to them
+	sort.SliceStable(added, func(i, j int) bool {
+		_, ci := added[i].(types.BaseVirtualController)
+		_, cj := added[j].(types.BaseVirtualController)
+		return ci && !cj
+	})
+
+	// Devices being removed are included when choosing keys and unit numbers,
+	// as the order in which changes are applied is not defined.
+	all := append(VirtualDeviceList{}, l...)
+	keys := make(map[int32]int32)
+
+	for _, device := range added {
+		d := device.GetVirtualDevice()
+
+		if d.Key >= -1 || all.FindByKey(d.Key) != nil {
+			key := all.NewKey()
+			if d.Key != 0 && d.Key != -1 {
+				keys[d.Key] = key
+			}
+			d.Key = key
+		}
+
+		all = append(all, device)
+	}
+
+	// Devices are appended to the list once assigned, as AssignController must not see the device itself
+	devices := append(VirtualDeviceList{}, l...)
+
+	for _, device := range added {
+		d := device.GetVirtualDevice()
+
+		if key, ok := keys[d.ControllerKey]; ok {
+			d.ControllerKey = key
+		}
+
+		if d.ControllerKey == 0 {
+			var c types.BaseVirtualController
+			var err error
+
+			switch device.(type) {
+			case *types.VirtualDisk:
+				c, err = devices.FindDiskController("")
+			case *types.VirtualCdrom:
+				c, err = devices.FindIDEController("")
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", l.TypeName(device), err)
+			}
+			if c != nil {
+				d.ControllerKey = c.GetVirtualController().Key
+			}
+		}
+
+		if d.ControllerKey != 0 && d.UnitNumber == nil {
+			c, ok := devices.FindByKey(d.ControllerKey).(types.BaseVirtualController)
+			if !ok {
+				return nil, fmt.Errorf("%s: controller %d not found", l.TypeName(device), d.ControllerKey)
+			}
+			devices.AssignController(device, c)
+		}
+
+		devices = append(devices, device)
+
+		spec, err := VirtualDeviceList{device}.ConfigSpec(types.VirtualDeviceConfigSpecOperationAdd)
+		if err != nil {
+			return nil, err
+		}
+		add = append(add, spec...)
+	}
+
+	return append(append(remove, edit...), add...), nil
+}
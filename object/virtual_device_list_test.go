@@ -875,3 +875,106 @@ func TestChildDisk(t *testing.T) {
 		}
 	}
 }
+
+func TestDiff(t *testing.T) {
+	changes, err := devices.Diff(devices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("changes=%d", len(changes))
+	}
+
+	var desired VirtualDeviceList
+
+	for _, device := range devices {
+		switch device.GetVirtualDevice().Key {
+		case 3000:
+			disk := *device.(*types.VirtualDisk)
+			disk.CapacityInKB *= 2
+			device = &disk
+		case 3001:
+			continue // remove cdrom
+		case 4000:
+			// match by backing rather than key
+			device = copyDevice(device)
+			device.GetVirtualDevice().Key = 0
+			device.GetVirtualDevice().DeviceInfo = nil
+		}
+		desired = append(desired, device)
+	}
+
+	pvscsi := &types.ParaVirtualSCSIController{}
+	pvscsi.Key = 42
+	pvscsi.ScsiCtlrUnitNumber = 7
+
+	disk := func(controller int32, file string, size int64) *types.VirtualDisk {
+		d := &types.VirtualDisk{CapacityInKB: size}
+		d.ControllerKey = controller
+		d.Backing = &types.VirtualDiskFlatVer2BackingInfo{
+			VirtualDeviceFileBackingInfo: types.VirtualDeviceFileBackingInfo{FileName: file},
+		}
+		return d
+	}
+
+	desired = append(desired,
+		disk(42, "", 1024),
+		pvscsi,
+		disk(0, "", 2048),
+		disk(0, "[datastore1] baz.vmdk", 0),
+	)
+
+	changes, err = devices.Diff(desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []struct {
+		op         string
+		device     string
+		key        int32
+		controller int32
+		unit       int32
+	}{
+		{"remove", "VirtualCdrom", 3001, 200, 1},
+		{"edit", "VirtualDisk", 3000, 200, 0},
+		{"add", "ParaVirtualSCSIController", -201, 0, -1},
+		{"add/create", "VirtualDisk", -202, -201, 0},
+		{"add/create", "VirtualDisk", -203, 1000, 0},
+		{"add", "VirtualDisk", -204, 1000, 1},
+	}
+
+	if len(changes) != len(expect) {
+		t.Fatalf("changes=%d", len(changes))
+	}
+
+	for i, e := range expect {
+		spec := changes[i].GetVirtualDeviceConfigSpec()
+		d := spec.Device.GetVirtualDevice()
+
+		op := string(spec.Operation)
+		if spec.FileOperation != "" {
+			op += "/" + string(spec.FileOperation)
+		}
+
+		unit := int32(-1)
+		if d.UnitNumber != nil {
+			unit = *d.UnitNumber
+		}
+
+		if op != e.op || devices.TypeName(spec.Device) != e.device ||
+			d.Key != e.key || d.ControllerKey != e.controller || unit != e.unit {
+			t.Errorf("%d: %s %s key=%d controller=%d unit=%d", i, op, devices.TypeName(spec.Device), d.Key, d.ControllerKey, unit)
+		}
+	}
+
+	// the desired devices are not modified
+	if pvscsi.Key != 42 || desired[len(desired)-4].GetVirtualDevice().UnitNumber != nil {
+		t.Error("desired device was modified")
+	}
+
+	_, err = devices.Diff(append(desired, disk(99, "", 1024)))
+	if err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,356 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/vmware/govmomi/units"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// VirtualMachineSpec is a declarative description of a virtual machine's desired state.
+// Fields left empty are not managed. The Disks, Networks and CDROMs lists, when non-nil,
+// describe the complete set of those devices in the order they appear in the device list.
+type VirtualMachineSpec struct {
+	Name           string            `json:"name"`
+	GuestID        string            `json:"guestId,omitempty"`
+	CPUs           int32             `json:"cpus,omitempty"`
+	CoresPerSocket int32             `json:"coresPerSocket,omitempty"`
+	MemoryMB       int64             `json:"memoryMB,omitempty"`
+	Firmware       string            `json:"firmware,omitempty"`
+	Annotation     string            `json:"annotation,omitempty"`
+	Disks          []DiskSpec        `json:"disks,omitempty"`
+	Networks       []NetworkSpec     `json:"networks,omitempty"`
+	CDROMs         []CdromSpec       `json:"cdroms,omitempty"`
+	ExtraConfig    map[string]string `json:"extraConfig,omitempty"`
+	Placement      PlacementSpec     `json:"placement,omitempty"`
+	Customization  string            `json:"customization,omitempty"`
+	PowerState     string            `json:"powerState,omitempty"`
+}
+
+// DiskSpec describes a virtual disk.
+// Size is a byte value such as "10GB". Controller is a controller type (scsi, ide, nvme or a
+// SCSI controller type such as pvscsi) or device name, used only when the disk is added.
+type DiskSpec struct {
+	Size       string `json:"size"`
+	Controller string `json:"controller,omitempty"`
+	Thin       *bool  `json:"thin,omitempty"`
+	Mode       string `json:"mode,omitempty"`
+}
+
+// NetworkSpec describes an ethernet card.
+// Backing must be resolved from Network by the caller, see Network.EthernetCardBackingInfo.
+type NetworkSpec struct {
+	Network   string `json:"network"`
+	Adapter   string `json:"adapter,omitempty"`
+	MAC       string `json:"mac,omitempty"`
+	Connected *bool  `json:"connected,omitempty"`
+
+	Backing types.BaseVirtualDeviceBackingInfo `json:"-"`
+}
+
+// CdromSpec describes a cdrom device, with an optional ISO file backing.
+type CdromSpec struct {
+	ISO string `json:"iso,omitempty"`
+}
+
+// PlacementSpec names the inventory objects used when the virtual machine is created.
+type PlacementSpec struct {
+	Folder    string `json:"folder,omitempty"`
+	Pool      string `json:"pool,omitempty"`
+	Host      string `json:"host,omitempty"`
+	Datastore string `json:"datastore,omitempty"`
+}
+
+// ConfigSpec returns the minimal VirtualMachineConfigSpec needed to move a virtual machine
+// from its current config to the desired state, or nil if there are no changes.
+// If config is nil, a spec for creating the virtual machine is returned, in which case the
+// caller must still set the Files field.
+func (s *VirtualMachineSpec) ConfigSpec(config *types.VirtualMachineConfigInfo) (*types.VirtualMachineConfigSpec, error) {
+	spec := new(types.VirtualMachineConfigSpec)
+
+	if config == nil {
+		if s.Name == "" {
+			return nil, errors.New("name is required")
+		}
+		spec.Name = s.Name
+		spec.GuestId = string(types.VirtualMachineGuestOsIdentifierOtherGuest)
+		config = new(types.VirtualMachineConfigInfo)
+	}
+
+	if s.GuestID != "" && s.GuestID != config.GuestId {
+		spec.GuestId = s.GuestID
+	}
+	if s.CPUs != 0 && s.CPUs != config.Hardware.NumCPU {
+		spec.NumCPUs = s.CPUs
+	}
+	if s.CoresPerSocket != 0 && s.CoresPerSocket != config.Hardware.NumCoresPerSocket {
+		spec.NumCoresPerSocket = s.CoresPerSocket
+	}
+	if s.MemoryMB != 0 && s.MemoryMB != int64(config.Hardware.MemoryMB) {
+		spec.MemoryMB = s.MemoryMB
+	}
+	if s.Firmware != "" && s.Firmware != config.Firmware {
+		spec.Firmware = s.Firmware
+	}
+	if s.Annotation != "" && s.Annotation != config.Annotation {
+		spec.Annotation = s.Annotation
+	}
+
+	spec.ExtraConfig = s.extraConfig(config.ExtraConfig)
+
+	changes, err := s.deviceChange(VirtualDeviceList(config.Hardware.Device))
+	if err != nil {
+		return nil, err
+	}
+	spec.DeviceChange = changes
+
+	if reflect.DeepEqual(*spec, types.VirtualMachineConfigSpec{}) {
+		return nil, nil
+	}
+
+	return spec, nil
+}
+
+// extraConfig returns the options in s.ExtraConfig that differ from the current values.
+// Keys not listed in the spec are left as-is.
+func (s *VirtualMachineSpec) extraConfig(current []types.BaseOptionValue) []types.BaseOptionValue {
+	values := make(map[string]string, len(current))
+	for _, o := range current {
+		opt := o.GetOptionValue()
+		values[opt.Key] = fmt.Sprint(opt.Value)
+	}
+
+	var keys []string
+	for key := range s.ExtraConfig {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var options []types.BaseOptionValue
+	for _, key := range keys {
+		val := s.ExtraConfig[key]
+		if cur, ok := values[key]; ok && cur == val {
+			continue
+		}
+		options = append(options, &types.OptionValue{Key: key, Value: val})
+	}
+
+	return options
+}
+
+// controller returns an existing controller of the given kind with an available slot,
+// appending a new controller to the desired device list if there is none.
+func controller(desired *VirtualDeviceList, kind string) (types.BaseVirtualController, error) {
+	if c, err := desired.FindDiskController(kind); err == nil {
+		return c, nil
+	}
+
+	var c types.BaseVirtualDevice
+	var err error
+
+	switch kind {
+	case "ide":
+		c, err = desired.CreateIDEController()
+	case "nvme":
+		c, err = desired.CreateNVMEController()
+	default:
+		if desired.Find(kind) != nil {
+			return nil, fmt.Errorf("%s is not a valid controller", kind)
+		}
+		c, err = desired.CreateSCSIController(kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	*desired = append(*desired, c)
+
+	return c.(types.BaseVirtualController), nil
+}
+
+// deviceChange builds the desired device list from the current list and the spec,
+// returning the difference between the two.
+func (s *VirtualMachineSpec) deviceChange(current VirtualDeviceList) ([]types.BaseVirtualDeviceConfigSpec, error) {
+	disks := current.SelectByType((*types.VirtualDisk)(nil))
+	nics := current.SelectByType((*types.VirtualEthernetCard)(nil))
+	cdroms := current.SelectByType((*types.VirtualCdrom)(nil))
+
+	// Devices of the kinds managed by the spec are appended by the functions below
+	desired := current.Select(func(device types.BaseVirtualDevice) bool {
+		switch device.(type) {
+		case *types.VirtualDisk:
+			return s.Disks == nil
+		case types.BaseVirtualEthernetCard:
+			return s.Networks == nil
+		case *types.VirtualCdrom:
+			return s.CDROMs == nil
+		default:
+			return true
+		}
+	})
+
+	if err := s.desiredDisks(&desired, disks); err != nil {
+		return nil, err
+	}
+
+	if err := s.desiredNetworks(&desired, nics); err != nil {
+		return nil, err
+	}
+
+	if err := s.desiredCdroms(&desired, cdroms); err != nil {
+		return nil, err
+	}
+
+	return current.Diff(desired)
+}
+
+func (s *VirtualMachineSpec) desiredDisks(desired *VirtualDeviceList, disks VirtualDeviceList) error {
+	for i, spec := range s.Disks {
+		var size units.ByteSize
+		if err := size.Set(spec.Size); err != nil {
+			return fmt.Errorf("disk %d: size %q: %s", i, spec.Size, err)
+		}
+		kb := int64(size) / 1024
+
+		if i < len(disks) {
+			disk := *disks[i].(*types.VirtualDisk)
+			switch {
+			case kb < disk.CapacityInKB:
+				return fmt.Errorf("disk %d: cannot shrink %s from %s to %s", i, disks.Name(disks[i]),
+					units.ByteSize(disk.CapacityInKB*1024), size)
+			case kb > disk.CapacityInKB:
+				disk.CapacityInKB = kb
+				if disk.CapacityInBytes != 0 {
+					disk.CapacityInBytes = kb * 1024
+				}
+			}
+			*desired = append(*desired, &disk)
+			continue
+		}
+
+		c, err := controller(desired, spec.Controller)
+		if err != nil {
+			return fmt.Errorf("disk %d: %s", i, err)
+		}
+
+		mode := spec.Mode
+		if mode == "" {
+			mode = string(types.VirtualDiskModePersistent)
+		}
+		thin := spec.Thin == nil || *spec.Thin
+
+		// Diff assigns the key and unit number
+		*desired = append(*desired, &types.VirtualDisk{
+			VirtualDevice: types.VirtualDevice{
+				ControllerKey: c.GetVirtualController().Key,
+				Backing: &types.VirtualDiskFlatVer2BackingInfo{
+					DiskMode:        mode,
+					ThinProvisioned: types.NewBool(thin),
+				},
+			},
+			CapacityInKB: kb,
+		})
+	}
+
+	return nil
+}
+
+func (s *VirtualMachineSpec) desiredNetworks(desired *VirtualDeviceList, nics VirtualDeviceList) error {
+	for i, spec := range s.Networks {
+		if spec.Backing == nil {
+			return fmt.Errorf("network %d: backing for %q is not set", i, spec.Network)
+		}
+
+		var card *types.VirtualEthernetCard
+
+		if i < len(nics) && (spec.Adapter == "" || spec.Adapter == nics.deviceName(nics[i])) {
+			device := copyDevice(nics[i])
+			card = device.(types.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+			if backingID(card.Backing) != backingID(spec.Backing) {
+				card.Backing = spec.Backing
+			}
+			*desired = append(*desired, device)
+		} else {
+			// The adapter type cannot be changed in place, the current card is removed
+			device, err := desired.CreateEthernetCard(spec.Adapter, spec.Backing)
+			if err != nil {
+				return fmt.Errorf("network %d: %s", i, err)
+			}
+			card = device.(types.BaseVirtualEthernetCard).GetVirtualEthernetCard()
+			*desired = append(*desired, device)
+		}
+
+		if spec.MAC != "" && spec.MAC != card.MacAddress {
+			card.AddressType = string(types.VirtualEthernetCardMacTypeManual)
+			card.MacAddress = spec.MAC
+		}
+
+		if spec.Connected != nil && (card.Connectable == nil || card.Connectable.StartConnected != *spec.Connected) {
+			connect := types.VirtualDeviceConnectInfo{AllowGuestControl: true}
+			if card.Connectable != nil {
+				connect = *card.Connectable
+			}
+			connect.StartConnected = *spec.Connected
+			connect.Connected = *spec.Connected
+			card.Connectable = &connect
+		}
+	}
+
+	return nil
+}
+
+func (s *VirtualMachineSpec) desiredCdroms(desired *VirtualDeviceList, cdroms VirtualDeviceList) error {
+	for i, spec := range s.CDROMs {
+		if i < len(cdroms) {
+			cdrom := *cdroms[i].(*types.VirtualCdrom)
+			iso, ok := cdrom.Backing.(*types.VirtualCdromIsoBackingInfo)
+
+			switch {
+			case spec.ISO != "" && (!ok || iso.FileName != spec.ISO):
+				desired.InsertIso(&cdrom, spec.ISO)
+			case spec.ISO == "" && ok:
+				desired.EjectIso(&cdrom)
+			}
+			*desired = append(*desired, &cdrom)
+			continue
+		}
+
+		c, err := controller(desired, "ide")
+		if err != nil {
+			return fmt.Errorf("cdrom %d: %s", i, err)
+		}
+
+		cdrom, err := desired.CreateCdrom(c.(*types.VirtualIDEController))
+		if err != nil {
+			return fmt.Errorf("cdrom %d: %s", i, err)
+		}
+		cdrom.Key = 0 // assigned by Diff
+
+		if spec.ISO != "" {
+			desired.InsertIso(cdrom, spec.ISO)
+		}
+
+		*desired = append(*desired, cdrom)
+	}
+
+	return nil
+}
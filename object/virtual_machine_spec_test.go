@@ -0,0 +1,197 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package object
+
+import (
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func specOps(spec *types.VirtualMachineConfigSpec) []string {
+	var ops []string
+	for _, c := range spec.DeviceChange {
+		dc := c.GetVirtualDeviceConfigSpec()
+		op := string(dc.Operation)
+		if dc.FileOperation != "" {
+			op += "/" + string(dc.FileOperation)
+		}
+		ops = append(ops, op+" "+devices.TypeName(dc.Device))
+	}
+	return ops
+}
+
+func TestVirtualMachineSpec(t *testing.T) {
+	network := func(name string) types.BaseVirtualDeviceBackingInfo {
+		return &types.VirtualEthernetCardNetworkBackingInfo{
+			VirtualDeviceDeviceBackingInfo: types.VirtualDeviceDeviceBackingInfo{DeviceName: name},
+		}
+	}
+
+	config := &types.VirtualMachineConfigInfo{
+		GuestId: "otherGuest",
+		Hardware: types.VirtualHardware{
+			NumCPU:   1,
+			MemoryMB: 1024,
+			Device:   devices,
+		},
+		ExtraConfig: []types.BaseOptionValue{&types.OptionValue{Key: "foo", Value: "bar"}},
+	}
+
+	current := VirtualMachineSpec{
+		GuestID:     "otherGuest",
+		CPUs:        1,
+		MemoryMB:    1024,
+		Disks:       []DiskSpec{{Size: "30MB"}, {Size: "10000000KB"}},
+		Networks:    []NetworkSpec{{Network: "VM Network", Adapter: "e1000", Backing: network("VM Network")}},
+		CDROMs:      []CdromSpec{{ISO: "[datastore1] foo.iso"}},
+		ExtraConfig: map[string]string{"foo": "bar"},
+	}
+
+	spec, err := current.ConfigSpec(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec != nil {
+		t.Errorf("expected no changes, got %#v", spec)
+	}
+
+	// nil device lists are not managed
+	unmanaged := current
+	unmanaged.Disks, unmanaged.Networks, unmanaged.CDROMs = nil, nil, nil
+	spec, err = unmanaged.ConfigSpec(config)
+	if err != nil || spec != nil {
+		t.Errorf("spec=%#v, err=%v", spec, err)
+	}
+
+	desired := current
+	desired.CPUs = 2
+	desired.Disks = []DiskSpec{{Size: "1GB"}, {Size: "10000000KB"}, {Size: "2GB", Controller: "scsi"}}
+	desired.Networks = []NetworkSpec{{Network: "DC0_DVPG0", Adapter: "vmxnet3", Backing: network("DC0_DVPG0")}}
+	desired.CDROMs = []CdromSpec{{}}
+	desired.ExtraConfig = map[string]string{"foo": "bar", "baz": "1"}
+
+	spec, err = desired.ConfigSpec(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if spec.NumCPUs != 2 || spec.MemoryMB != 0 || spec.GuestId != "" {
+		t.Errorf("hardware=%d/%d/%q", spec.NumCPUs, spec.MemoryMB, spec.GuestId)
+	}
+
+	if len(spec.ExtraConfig) != 1 || spec.ExtraConfig[0].GetOptionValue().Key != "baz" {
+		t.Errorf("extraConfig=%#v", spec.ExtraConfig)
+	}
+
+	expect := []string{
+		"remove VirtualE1000",
+		"edit VirtualDisk",
+		"edit VirtualCdrom",
+		"add/create VirtualDisk",
+		"add VirtualVmxnet3",
+	}
+	ops := specOps(spec)
+	if len(ops) != len(expect) {
+		t.Fatalf("ops=%v", ops)
+	}
+	for i := range expect {
+		if ops[i] != expect[i] {
+			t.Errorf("%d: %s != %s", i, ops[i], expect[i])
+		}
+	}
+
+	disk := spec.DeviceChange[1].GetVirtualDeviceConfigSpec().Device.(*types.VirtualDisk)
+	if disk.CapacityInKB != 1024*1024 {
+		t.Errorf("capacity=%d", disk.CapacityInKB)
+	}
+
+	disk = spec.DeviceChange[3].GetVirtualDeviceConfigSpec().Device.(*types.VirtualDisk)
+	if disk.Key >= 0 || disk.ControllerKey != 1000 || disk.UnitNumber == nil {
+		t.Errorf("key=%d controller=%d", disk.Key, disk.ControllerKey)
+	}
+
+	// the current config must be left as-is
+	if devices.FindByKey(3000).(*types.VirtualDisk).CapacityInKB != 30720 {
+		t.Error("current disk was modified")
+	}
+	if _, ok := devices.FindByKey(3001).(*types.VirtualCdrom).Backing.(*types.VirtualCdromIsoBackingInfo); !ok {
+		t.Error("current cdrom was modified")
+	}
+
+	desired.Disks = []DiskSpec{{Size: "1MB"}}
+	_, err = desired.ConfigSpec(config)
+	if err == nil {
+		t.Error("expected shrink error")
+	}
+
+	desired.Disks = nil
+	desired.Networks = []NetworkSpec{{Network: "VM Network"}}
+	_, err = desired.ConfigSpec(config)
+	if err == nil {
+		t.Error("expected backing error")
+	}
+}
+
+func TestVirtualMachineSpecCreate(t *testing.T) {
+	vm := VirtualMachineSpec{
+		Name:     "foo",
+		MemoryMB: 64,
+		Disks:    []DiskSpec{{Size: "10GB"}, {Size: "1GB", Mode: "independent_persistent"}},
+		Networks: []NetworkSpec{{Network: "VM Network", Backing: &types.VirtualEthernetCardNetworkBackingInfo{}}},
+		CDROMs:   []CdromSpec{{ISO: "[datastore1] foo.iso"}},
+	}
+
+	spec, err := vm.ConfigSpec(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if spec.Name != "foo" || spec.GuestId != "otherGuest" || spec.MemoryMB != 64 {
+		t.Errorf("spec=%#v", spec)
+	}
+
+	expect := []string{
+		"add VirtualLsiLogicController",
+		"add VirtualIDEController",
+		"add/create VirtualDisk",
+		"add/create VirtualDisk",
+		"add VirtualE1000",
+		"add VirtualCdrom",
+	}
+	ops := specOps(spec)
+	if len(ops) != len(expect) {
+		t.Fatalf("ops=%v", ops)
+	}
+
+	keys := make(map[int32]bool)
+	for i, c := range spec.DeviceChange {
+		if ops[i] != expect[i] {
+			t.Errorf("%d: %s != %s", i, ops[i], expect[i])
+		}
+		key := c.GetVirtualDeviceConfigSpec().Device.GetVirtualDevice().Key
+		if key >= 0 || keys[key] {
+			t.Errorf("%d: invalid key %d", i, key)
+		}
+		keys[key] = true
+	}
+
+	second := spec.DeviceChange[3].GetVirtualDeviceConfigSpec().Device.GetVirtualDevice()
+	if *second.UnitNumber != 1 {
+		t.Errorf("unit=%d", *second.UnitNumber)
+	}
+}
@@ -201,6 +201,35 @@ func (c *Client) QueryAssociatedEntities(ctx context.Context, ids []types.PbmPro
 	return res.Returnval, nil
 }
 
+func (c *Client) QueryAssociatedProfile(ctx context.Context, entity types.PbmServerObjectRef) ([]types.PbmProfileId, error) {
+	req := types.PbmQueryAssociatedProfile{
+		This:   c.ServiceContent.ProfileManager,
+		Entity: entity,
+	}
+
+	res, err := methods.PbmQueryAssociatedProfile(ctx, c, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Returnval, nil
+}
+
+func (c *Client) CheckCompliance(ctx context.Context, entities []types.PbmServerObjectRef, profile *types.PbmProfileId) ([]types.PbmComplianceResult, error) {
+	req := types.PbmCheckCompliance{
+		This:     c.ServiceContent.ComplianceManager,
+		Entities: entities,
+		Profile:  profile,
+	}
+
+	res, err := methods.PbmCheckCompliance(ctx, c, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Returnval, nil
+}
+
 func (c *Client) ProfileIDByName(ctx context.Context, profileName string) (string, error) {
 	resourceType := types.PbmProfileResourceType{
 		ResourceType: string(types.PbmProfileResourceTypeEnumSTORAGE),
@@ -0,0 +1,523 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/pbm"
+	"github.com/vmware/govmomi/pbm/methods"
+	"github.com/vmware/govmomi/pbm/types"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vim25/soap"
+	vim "github.com/vmware/govmomi/vim25/types"
+)
+
+var content = types.PbmServiceInstanceContent{
+	AboutInfo: types.PbmAboutInfo{
+		Name:         "PBM",
+		Version:      "2.0",
+		InstanceUuid: "df09f335-be97-4f33-8c27-315faaaad6fc",
+	},
+	SessionManager:            vim.ManagedObjectReference{Type: "PbmSessionManager", Value: "SessionManager"},
+	CapabilityMetadataManager: vim.ManagedObjectReference{Type: "PbmCapabilityMetadataManager", Value: "CapabilityMetadataManager"},
+	ProfileManager:            vim.ManagedObjectReference{Type: "PbmProfileProfileManager", Value: "ProfileManager"},
+	ComplianceManager:         vim.ManagedObjectReference{Type: "PbmComplianceManager", Value: "complianceManager"},
+	PlacementSolver:           vim.ManagedObjectReference{Type: "PbmPlacementSolver", Value: "SolverService"},
+}
+
+// vsanNamespace is the capability namespace of vSAN storage policy rules.
+const vsanNamespace = "VSAN"
+
+func New() *simulator.Registry {
+	r := simulator.NewRegistry()
+	r.Namespace = pbm.Namespace
+	r.Path = pbm.Path
+
+	r.Put(&ServiceInstance{
+		ManagedObjectReference: pbm.ServiceInstance,
+		Content:                content,
+	})
+
+	profiles := &ProfileManager{
+		ManagedObjectReference: content.ProfileManager,
+		profile:                defaultProfiles(),
+	}
+	r.Put(profiles)
+
+	r.Put(&ComplianceManager{
+		ManagedObjectReference: content.ComplianceManager,
+		profiles:               profiles,
+	})
+
+	r.Put(&PlacementSolver{
+		ManagedObjectReference: content.PlacementSolver,
+		profiles:               profiles,
+	})
+
+	return r
+}
+
+func defaultProfiles() []*types.PbmCapabilityProfile {
+	now := time.Now()
+
+	vsan := func(id string, value interface{}) types.PbmCapabilityInstance {
+		return types.PbmCapabilityInstance{
+			Id: types.PbmCapabilityMetadataUniqueId{Namespace: vsanNamespace, Id: id},
+			Constraint: []types.PbmCapabilityConstraintInstance{{
+				PropertyInstance: []types.PbmCapabilityPropertyInstance{{Id: id, Value: value}},
+			}},
+		}
+	}
+
+	return []*types.PbmCapabilityProfile{
+		{
+			PbmProfile: types.PbmProfile{
+				ProfileId:       types.PbmProfileId{UniqueId: "aa6d5a82-1c88-45da-85d3-3d74b91a5bad"},
+				Name:            "vSAN Default Storage Policy",
+				Description:     "Storage policy used as default for vSAN datastores",
+				CreationTime:    now,
+				CreatedBy:       "Temporary user handle",
+				LastUpdatedTime: now,
+				LastUpdatedBy:   "Temporary user handle",
+			},
+			ProfileCategory: string(types.PbmProfileCategoryEnumREQUIREMENT),
+			ResourceType:    types.PbmProfileResourceType{ResourceType: string(types.PbmProfileResourceTypeEnumSTORAGE)},
+			Constraints: &types.PbmCapabilitySubProfileConstraints{
+				SubProfiles: []types.PbmCapabilitySubProfile{{
+					Name: "VSAN sub-profile",
+					Capability: []types.PbmCapabilityInstance{
+						vsan("hostFailuresToTolerate", int32(1)),
+						vsan("stripeWidth", int32(1)),
+						vsan("forceProvisioning", false),
+						vsan("proportionalCapacity", int32(0)),
+						vsan("cacheReservation", int32(0)),
+					},
+				}},
+			},
+			IsDefault:                true,
+			SystemCreatedProfileType: "VsanDefaultProfile",
+		},
+		{
+			PbmProfile: types.PbmProfile{
+				ProfileId:       types.PbmProfileId{UniqueId: "f4e5bade-15a2-4805-bf8e-52318c4ce443"},
+				Name:            "VVol No Requirements Policy",
+				Description:     "Allow the datastore to determine the best placement strategy for storage objects",
+				CreationTime:    now,
+				CreatedBy:       "Temporary user handle",
+				LastUpdatedTime: now,
+				LastUpdatedBy:   "Temporary user handle",
+			},
+			ProfileCategory:          string(types.PbmProfileCategoryEnumREQUIREMENT),
+			ResourceType:             types.PbmProfileResourceType{ResourceType: string(types.PbmProfileResourceTypeEnumSTORAGE)},
+			Constraints:              &types.PbmCapabilitySubProfileConstraints{},
+			IsDefault:                true,
+			SystemCreatedProfileType: "VVolDefaultProfile",
+		},
+	}
+}
+
+type ServiceInstance struct {
+	vim.ManagedObjectReference
+
+	Content types.PbmServiceInstanceContent
+}
+
+func (s *ServiceInstance) PbmRetrieveServiceContent(_ *types.PbmRetrieveServiceContent) soap.HasFault {
+	return &methods.PbmRetrieveServiceContentBody{
+		Res: &types.PbmRetrieveServiceContentResponse{
+			Returnval: s.Content,
+		},
+	}
+}
+
+type ProfileManager struct {
+	vim.ManagedObjectReference
+
+	profile []*types.PbmCapabilityProfile
+}
+
+// entity returns the VM and device key (-1 for the VM home) of the given PBM object.
+func entity(ref types.PbmServerObjectRef) (*simulator.VirtualMachine, int32) {
+	key := int32(-1)
+	id := ref.Key
+
+	switch types.PbmObjectType(ref.ObjectType) {
+	case types.PbmObjectTypeVirtualMachine:
+	case types.PbmObjectTypeVirtualDiskId:
+		parts := strings.SplitN(ref.Key, ":", 2)
+		if len(parts) != 2 {
+			return nil, 0
+		}
+		n, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, 0
+		}
+		id, key = parts[0], int32(n)
+	default:
+		return nil, 0
+	}
+
+	vm, _ := simulator.Map.Get(vim.ManagedObjectReference{Type: "VirtualMachine", Value: id}).(*simulator.VirtualMachine)
+	return vm, key
+}
+
+// associated returns the profiles associated with the given entity,
+// as recorded by the VM's create and reconfigure specs.
+func (m *ProfileManager) associated(ref types.PbmServerObjectRef) []types.PbmProfileId {
+	vm, key := entity(ref)
+	if vm == nil {
+		return nil
+	}
+
+	var ids []types.PbmProfileId
+
+	simulator.Map.WithLock(vm, func() {
+		for _, id := range vm.StorageProfile(key) {
+			ids = append(ids, types.PbmProfileId{UniqueId: id})
+		}
+	})
+
+	return ids
+}
+
+func (m *ProfileManager) find(id types.PbmProfileId) (int, *types.PbmCapabilityProfile) {
+	for i, p := range m.profile {
+		if p.ProfileId.UniqueId == id.UniqueId {
+			return i, p
+		}
+	}
+	return -1, nil
+}
+
+func invalidProfile(id types.PbmProfileId) *soap.Fault {
+	return simulator.Fault("profile not found: "+id.UniqueId, &vim.InvalidArgument{InvalidProperty: "profileId"})
+}
+
+func (m *ProfileManager) PbmQueryProfile(req *types.PbmQueryProfile) soap.HasFault {
+	body := new(methods.PbmQueryProfileBody)
+	body.Res = new(types.PbmQueryProfileResponse)
+
+	for _, p := range m.profile {
+		if p.ResourceType.ResourceType != req.ResourceType.ResourceType {
+			continue
+		}
+		if req.ProfileCategory != "" && p.ProfileCategory != req.ProfileCategory {
+			continue
+		}
+		body.Res.Returnval = append(body.Res.Returnval, p.ProfileId)
+	}
+
+	return body
+}
+
+func (m *ProfileManager) PbmRetrieveContent(req *types.PbmRetrieveContent) soap.HasFault {
+	body := new(methods.PbmRetrieveContentBody)
+	var res []types.BasePbmProfile
+
+	for _, id := range req.ProfileIds {
+		_, p := m.find(id)
+		if p == nil {
+			body.Fault_ = invalidProfile(id)
+			return body
+		}
+		res = append(res, p)
+	}
+
+	body.Res = &types.PbmRetrieveContentResponse{Returnval: res}
+
+	return body
+}
+
+func (m *ProfileManager) PbmCreate(ctx *simulator.Context, req *types.PbmCreate) soap.HasFault {
+	body := new(methods.PbmCreateBody)
+	spec := req.CreateSpec
+
+	for _, p := range m.profile {
+		if p.Name == spec.Name {
+			body.Fault_ = simulator.Fault("", &types.PbmDuplicateName{Name: spec.Name})
+			return body
+		}
+	}
+
+	now := time.Now()
+	p := &types.PbmCapabilityProfile{
+		PbmProfile: types.PbmProfile{
+			ProfileId:       types.PbmProfileId{UniqueId: uuid.New().String()},
+			Name:            spec.Name,
+			Description:     spec.Description,
+			CreationTime:    now,
+			CreatedBy:       ctx.Session.UserName,
+			LastUpdatedTime: now,
+			LastUpdatedBy:   ctx.Session.UserName,
+		},
+		ProfileCategory: spec.Category,
+		ResourceType:    spec.ResourceType,
+		Constraints:     spec.Constraints,
+	}
+
+	if p.ProfileCategory == "" {
+		p.ProfileCategory = string(types.PbmProfileCategoryEnumREQUIREMENT)
+	}
+
+	m.profile = append(m.profile, p)
+
+	body.Res = &types.PbmCreateResponse{Returnval: p.ProfileId}
+
+	return body
+}
+
+func (m *ProfileManager) PbmUpdate(ctx *simulator.Context, req *types.PbmUpdate) soap.HasFault {
+	body := new(methods.PbmUpdateBody)
+	spec := req.UpdateSpec
+
+	_, p := m.find(req.ProfileId)
+	if p == nil {
+		body.Fault_ = invalidProfile(req.ProfileId)
+		return body
+	}
+
+	if spec.Name != "" {
+		p.Name = spec.Name
+	}
+	if spec.Description != "" {
+		p.Description = spec.Description
+	}
+	if spec.Constraints != nil {
+		p.Constraints = spec.Constraints
+	}
+
+	p.GenerationId++
+	p.LastUpdatedTime = time.Now()
+	p.LastUpdatedBy = ctx.Session.UserName
+
+	body.Res = new(types.PbmUpdateResponse)
+
+	return body
+}
+
+func (m *ProfileManager) PbmDelete(req *types.PbmDelete) soap.HasFault {
+	body := new(methods.PbmDeleteBody)
+	body.Res = new(types.PbmDeleteResponse)
+
+	for _, id := range req.ProfileId {
+		i, p := m.find(id)
+
+		var fault vim.LocalizedMethodFault
+		switch {
+		case p == nil:
+			fault.Fault = &vim.InvalidArgument{InvalidProperty: "profileId"}
+			fault.LocalizedMessage = "Profile not found"
+		case p.IsDefault:
+			fault.Fault = new(types.PbmFaultProfileStorageFault)
+			fault.LocalizedMessage = "Cannot delete a default profile"
+		default:
+			m.profile = append(m.profile[:i], m.profile[i+1:]...)
+			continue
+		}
+
+		body.Res.Returnval = append(body.Res.Returnval, types.PbmProfileOperationOutcome{
+			ProfileId: id,
+			Fault:     &fault,
+		})
+	}
+
+	return body
+}
+
+func (m *ProfileManager) PbmQueryAssociatedProfile(req *types.PbmQueryAssociatedProfile) soap.HasFault {
+	return &methods.PbmQueryAssociatedProfileBody{
+		Res: &types.PbmQueryAssociatedProfileResponse{
+			Returnval: m.associated(req.Entity),
+		},
+	}
+}
+
+func (m *ProfileManager) PbmQueryAssociatedProfiles(req *types.PbmQueryAssociatedProfiles) soap.HasFault {
+	body := new(methods.PbmQueryAssociatedProfilesBody)
+	body.Res = new(types.PbmQueryAssociatedProfilesResponse)
+
+	for _, ref := range req.Entities {
+		body.Res.Returnval = append(body.Res.Returnval, types.PbmQueryProfileResult{
+			Object:    ref,
+			ProfileId: m.associated(ref),
+		})
+	}
+
+	return body
+}
+
+type ComplianceManager struct {
+	vim.ManagedObjectReference
+
+	profiles *ProfileManager
+}
+
+func (m *ComplianceManager) PbmCheckCompliance(req *types.PbmCheckCompliance) soap.HasFault {
+	body := new(methods.PbmCheckComplianceBody)
+	body.Res = new(types.PbmCheckComplianceResponse)
+	now := time.Now()
+
+	for _, ref := range req.Entities {
+		res := types.PbmComplianceResult{
+			CheckTime:        now,
+			Entity:           ref,
+			Profile:          req.Profile,
+			ComplianceStatus: string(types.PbmComplianceStatusNotApplicable),
+		}
+
+		if res.Profile == nil {
+			if ids := m.profiles.associated(ref); len(ids) != 0 {
+				res.Profile = &ids[0]
+			}
+		}
+
+		if res.Profile != nil {
+			res.ComplianceStatus = string(m.status(ref, *res.Profile))
+		}
+
+		body.Res.Returnval = append(body.Res.Returnval, res)
+	}
+
+	return body
+}
+
+// status checks the given profile against the datastore backing the entity.
+func (m *ComplianceManager) status(ref types.PbmServerObjectRef, id types.PbmProfileId) types.PbmComplianceStatus {
+	_, p := m.profiles.find(id)
+	vm, key := entity(ref)
+	if p == nil || vm == nil {
+		return types.PbmComplianceStatusUnknown
+	}
+
+	var ds *vim.ManagedObjectReference
+
+	simulator.Map.WithLock(vm, func() {
+		if key == -1 {
+			if len(vm.Datastore) != 0 {
+				ds = &vm.Datastore[0]
+			}
+			return
+		}
+
+		device := object.VirtualDeviceList(vm.Config.Hardware.Device).FindByKey(key)
+		if disk, ok := device.(*vim.VirtualDisk); ok {
+			if b, ok := disk.Backing.(vim.BaseVirtualDeviceFileBackingInfo); ok {
+				ds = b.GetVirtualDeviceFileBackingInfo().Datastore
+			}
+		}
+	})
+
+	if ds == nil {
+		return types.PbmComplianceStatusUnknown
+	}
+
+	if compatible(p, *ds) {
+		return types.PbmComplianceStatusCompliant
+	}
+
+	return types.PbmComplianceStatusNonCompliant
+}
+
+type PlacementSolver struct {
+	vim.ManagedObjectReference
+
+	profiles *ProfileManager
+}
+
+// namespaces returns the capability namespaces referenced by the given profile.
+func namespaces(p *types.PbmCapabilityProfile) map[string]bool {
+	ns := make(map[string]bool)
+
+	if c, ok := p.Constraints.(*types.PbmCapabilitySubProfileConstraints); ok {
+		for _, sub := range c.SubProfiles {
+			for _, capability := range sub.Capability {
+				ns[capability.Id.Namespace] = true
+			}
+		}
+	}
+
+	return ns
+}
+
+// compatible considers all datastores compatible with the given profile,
+// with the exception of profiles that include vSAN rules, which require a vSAN datastore.
+func compatible(p *types.PbmCapabilityProfile, ref vim.ManagedObjectReference) bool {
+	if !namespaces(p)[vsanNamespace] {
+		return true
+	}
+
+	ds, ok := simulator.Map.Get(ref).(*simulator.Datastore)
+	if !ok {
+		return false
+	}
+
+	return ds.Summary.Type == string(vim.HostFileSystemVolumeFileSystemTypeVsan)
+}
+
+// PbmCheckRequirements returns the datastores compatible with all of the required profiles.
+func (s *PlacementSolver) PbmCheckRequirements(req *types.PbmCheckRequirements) soap.HasFault {
+	body := new(methods.PbmCheckRequirementsBody)
+
+	var profiles []*types.PbmCapabilityProfile
+	for _, r := range req.PlacementSubjectRequirement {
+		if profile, ok := r.(*types.PbmPlacementCapabilityProfileRequirement); ok {
+			_, p := s.profiles.find(profile.ProfileId)
+			if p == nil {
+				body.Fault_ = invalidProfile(profile.ProfileId)
+				return body
+			}
+			profiles = append(profiles, p)
+		}
+	}
+
+	hubs := req.HubsToSearch
+	if len(hubs) == 0 {
+		for _, ds := range simulator.Map.All("Datastore") {
+			hubs = append(hubs, types.PbmPlacementHub{
+				HubType: ds.Reference().Type,
+				HubId:   ds.Reference().Value,
+			})
+		}
+	}
+
+	body.Res = new(types.PbmCheckRequirementsResponse)
+
+	for _, hub := range hubs {
+		res := types.PbmPlacementCompatibilityResult{Hub: hub}
+		ref := vim.ManagedObjectReference{Type: hub.HubType, Value: hub.HubId}
+
+		for _, p := range profiles {
+			if !compatible(p, ref) {
+				res.Error = append(res.Error, vim.LocalizedMethodFault{
+					Fault:            &types.PbmCompatibilityCheckFault{Hub: hub},
+					LocalizedMessage: "Datastore does not match current VM policy",
+				})
+				break
+			}
+		}
+
+		body.Res.Returnval = append(body.Res.Returnval, res)
+	}
+
+	return body
+}
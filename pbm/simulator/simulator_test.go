@@ -0,0 +1,188 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/pbm"
+	"github.com/vmware/govmomi/pbm/types"
+	"github.com/vmware/govmomi/simulator"
+	vim "github.com/vmware/govmomi/vim25/types"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	model := simulator.VPX()
+
+	defer model.Remove()
+	err := model.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := model.Service.NewServer()
+	defer s.Close()
+
+	model.Service.RegisterSDK(New())
+
+	vc, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := pbm.NewClient(ctx, vc.Client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rtype := types.PbmProfileResourceType{
+		ResourceType: string(types.PbmProfileResourceTypeEnumSTORAGE),
+	}
+
+	ids, err := c.QueryProfile(ctx, rtype, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != len(defaultProfiles()) {
+		t.Errorf("ids=%d", len(ids))
+	}
+
+	spec, err := pbm.CreateCapabilityProfileSpec(pbm.CapabilityProfileCreateSpec{
+		Name: "govmomi-test",
+		CapabilityList: []pbm.Capability{{
+			ID:           "stripeWidth",
+			Namespace:    "VSAN",
+			PropertyList: []pbm.Property{{ID: "stripeWidth", Value: "2", DataType: "int"}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pid, err := c.CreateProfile(ctx, *spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.CreateProfile(ctx, *spec); err == nil {
+		t.Error("expected error") // PbmDuplicateName
+	}
+
+	id, err := c.ProfileIDByName(ctx, spec.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != pid.UniqueId {
+		t.Errorf("id=%s", id)
+	}
+
+	err = c.UpdateProfile(ctx, *pid, types.PbmCapabilityProfileUpdateSpec{Description: "updated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profiles, err := c.RetrieveContent(ctx, []types.PbmProfileId{*pid})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profiles[0].GetPbmProfile().Description != "updated" {
+		t.Errorf("profile=%#v", profiles[0])
+	}
+
+	// vSAN rules are not satisfied by any of the simulator datastores
+	req := []types.BasePbmPlacementRequirement{&types.PbmPlacementCapabilityProfileRequirement{ProfileId: *pid}}
+	res, err := c.CheckRequirements(ctx, nil, nil, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) == 0 || len(res.CompatibleDatastores()) != 0 {
+		t.Errorf("res=%#v", res)
+	}
+
+	vm := simulator.Map.Any("VirtualMachine")
+	entity := types.PbmServerObjectRef{
+		ObjectType: string(types.PbmObjectTypeVirtualMachine),
+		Key:        vm.Reference().Value,
+	}
+
+	status, err := c.CheckCompliance(ctx, []types.PbmServerObjectRef{entity}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status[0].ComplianceStatus != string(types.PbmComplianceStatusNotApplicable) {
+		t.Errorf("status=%s", status[0].ComplianceStatus)
+	}
+
+	// associate the vSAN profile with the VM, which is not on a vSAN datastore
+	spec.Name = "govmomi-test-tier"
+	spec.Constraints.(*types.PbmCapabilitySubProfileConstraints).SubProfiles[0].Capability[0].Id.Namespace = "com.example"
+	tier, err := c.CreateProfile(ctx, *spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj := object.NewVirtualMachine(vc.Client, vm.Reference())
+
+	for _, test := range []struct {
+		id     *types.PbmProfileId
+		status types.PbmComplianceStatus
+	}{
+		{pid, types.PbmComplianceStatusNonCompliant},
+		{tier, types.PbmComplianceStatusCompliant},
+	} {
+		task, err := obj.Reconfigure(ctx, vim.VirtualMachineConfigSpec{
+			VmProfile: []vim.BaseVirtualMachineProfileSpec{
+				&vim.VirtualMachineDefinedProfileSpec{ProfileId: test.id.UniqueId},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = task.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		associated, err := c.QueryAssociatedProfile(ctx, entity)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(associated) != 1 || associated[0] != *test.id {
+			t.Errorf("associated=%#v", associated)
+		}
+
+		status, err = c.CheckCompliance(ctx, []types.PbmServerObjectRef{entity}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status[0].ComplianceStatus != string(test.status) {
+			t.Errorf("status=%s", status[0].ComplianceStatus)
+		}
+	}
+
+	outcome, err := c.DeleteProfile(ctx, []types.PbmProfileId{*pid, *pid, ids[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outcome) != 2 {
+		t.Errorf("outcome=%#v", outcome) // not found and default profile
+	}
+}
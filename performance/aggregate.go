@@ -0,0 +1,296 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package performance
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Counter returns the PerfCounterInfo of the series, as provided by Manager.ToMetricSeries.
+func (s *MetricSeries) Counter() *types.PerfCounterInfo {
+	return s.info
+}
+
+// rollup returns the rollup type of the series counter, defaulting to "none".
+func (s *MetricSeries) rollup() types.PerfSummaryType {
+	if s.info == nil {
+		return types.PerfSummaryTypeNone
+	}
+	return s.info.RollupType
+}
+
+// Normalize returns the series values converted to the counter's base unit, along with the name of that unit.
+// See Convert.
+func (s *MetricSeries) Normalize() ([]float64, string) {
+	vals := make([]float64, len(s.Value))
+	unit := ""
+
+	for i, v := range s.Value {
+		if s.info == nil {
+			vals[i] = float64(v)
+			continue
+		}
+		vals[i], unit = Convert(s.info, v)
+	}
+
+	return vals, unit
+}
+
+// Rate returns the series values per second, for counters with a statsType of "delta",
+// where each value is the change over the sample interval. Values of other counters are returned as-is.
+func (s *MetricSeries) Rate(info []types.PerfSampleInfo) []float64 {
+	vals := make([]float64, len(s.Value))
+
+	for i, v := range s.Value {
+		vals[i] = float64(v)
+
+		if s.info != nil && s.info.StatsType == types.PerfStatsTypeDelta && i < len(info) && info[i].Interval > 0 {
+			vals[i] /= float64(info[i].Interval)
+		}
+	}
+
+	return vals
+}
+
+// Stat returns the named statistic over the series values, in the counter's unit.
+// If name is empty, the statistic is chosen based on the counter's rollupType. See Statistic.
+func (s *MetricSeries) Stat(name string) (float64, error) {
+	if name == "" {
+		name = DefaultStat(s.rollup())
+	}
+
+	vals := make([]float64, len(s.Value))
+	for i, v := range s.Value {
+		vals[i] = float64(v)
+	}
+
+	return Statistic(name, vals)
+}
+
+// DefaultStat returns the statistic name used to summarize values of the given rollup type over time.
+func DefaultStat(rollup types.PerfSummaryType) string {
+	switch rollup {
+	case types.PerfSummaryTypeAverage:
+		return "avg"
+	case types.PerfSummaryTypeSummation:
+		return "sum"
+	case types.PerfSummaryTypeMaximum:
+		return "max"
+	case types.PerfSummaryTypeMinimum:
+		return "min"
+	default:
+		return "latest"
+	}
+}
+
+// Statistic returns the named statistic over the given values, which must not be empty.
+// Supported names are "avg", "sum", "min", "max", "latest" and "pN", a percentile such as "p95",
+// using the nearest-rank method.
+func Statistic(name string, vals []float64) (float64, error) {
+	if len(vals) == 0 {
+		return 0, fmt.Errorf("%s: no values", name)
+	}
+
+	switch name {
+	case "avg", "sum":
+		var sum float64
+		for _, v := range vals {
+			sum += v
+		}
+		if name == "avg" {
+			return sum / float64(len(vals)), nil
+		}
+		return sum, nil
+	case "min", "max":
+		res := vals[0]
+		for _, v := range vals[1:] {
+			if (name == "min" && v < res) || (name == "max" && v > res) {
+				res = v
+			}
+		}
+		return res, nil
+	case "latest":
+		return vals[len(vals)-1], nil
+	}
+
+	if strings.HasPrefix(name, "p") {
+		p, err := strconv.ParseFloat(name[1:], 64)
+		if err == nil && p > 0 && p <= 100 {
+			sorted := append([]float64(nil), vals...)
+			sort.Float64s(sorted)
+			rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+			return sorted[rank-1], nil
+		}
+	}
+
+	return 0, fmt.Errorf("unsupported statistic: %q", name)
+}
+
+// combine returns the value of multiple instances of a counter at a point in time.
+// Percentages are averaged, other values are combined according to the rollup type, summing by default.
+func combine(info *types.PerfCounterInfo, vals []int64) int64 {
+	var res int64
+
+	rollup := types.PerfSummaryTypeNone
+	percent := false
+	if info != nil {
+		rollup = info.RollupType
+		percent = info.UnitInfo.GetElementDescription().Key == string(types.PerformanceManagerUnitPercent)
+	}
+
+	for i, v := range vals {
+		switch {
+		case i == 0:
+			res = v
+		case rollup == types.PerfSummaryTypeMaximum:
+			if v > res {
+				res = v
+			}
+		case rollup == types.PerfSummaryTypeMinimum:
+			if v < res {
+				res = v
+			}
+		default:
+			res += v
+		}
+	}
+
+	if percent && rollup != types.PerfSummaryTypeMaximum && rollup != types.PerfSummaryTypeMinimum && len(vals) != 0 {
+		res /= int64(len(vals))
+	}
+
+	return res
+}
+
+// Aggregate returns a copy of the EntityMetric with a single series per counter.
+// If the aggregate instance ("") of a counter is present, it is used as-is.
+// Otherwise, the instances are combined, for example summing per-vCPU "cpu.usagemhz" values to the VM total.
+// An instance with fewer values than SampleInfo is not included in the samples it lacks.
+func (m *EntityMetric) Aggregate() EntityMetric {
+	res := EntityMetric{
+		Entity:     m.Entity,
+		SampleInfo: m.SampleInfo,
+	}
+
+	var names []string
+	byName := make(map[string][]*MetricSeries)
+
+	for i := range m.Value {
+		s := &m.Value[i]
+		if _, ok := byName[s.Name]; !ok {
+			names = append(names, s.Name)
+		}
+		byName[s.Name] = append(byName[s.Name], s)
+	}
+
+	for _, name := range names {
+		series := byName[name]
+		total := MetricSeries{
+			Name: name,
+			unit: series[0].unit,
+			info: series[0].info,
+		}
+
+		aggregate := false
+		for _, s := range series {
+			if s.Instance == "" {
+				total.Value = s.Value
+				aggregate = true
+				break
+			}
+		}
+
+		if !aggregate {
+			total.Value = make([]int64, len(m.SampleInfo))
+			vals := make([]int64, 0, len(series))
+
+			for i := range total.Value {
+				vals = vals[:0]
+				for _, s := range series {
+					if i < len(s.Value) { // instances with a shorter series are skipped
+						vals = append(vals, s.Value[i])
+					}
+				}
+				total.Value[i] = combine(total.info, vals)
+			}
+		}
+
+		res.Value = append(res.Value, total)
+	}
+
+	return res
+}
+
+// Rollup returns a copy of the EntityMetric with samples rolled up into the given interval (in seconds),
+// such as rolling up realtime (20s) samples into 5 minute (300s) samples.
+// Each sample is included in the period that ends at or after its timestamp, such that sample timestamps are aligned to the interval.
+// Values are combined according to the counter rollupType: average (rounded), summation, maximum, minimum or latest.
+func (m *EntityMetric) Rollup(interval int32) EntityMetric {
+	period := time.Duration(interval) * time.Second
+
+	res := EntityMetric{
+		Entity: m.Entity,
+	}
+
+	var buckets [][]int // sample indices per period
+
+	for i, info := range m.SampleInfo {
+		end := info.Timestamp.Truncate(period)
+		if end.Before(info.Timestamp) {
+			end = end.Add(period)
+		}
+
+		n := len(res.SampleInfo)
+		if n == 0 || !res.SampleInfo[n-1].Timestamp.Equal(end) {
+			res.SampleInfo = append(res.SampleInfo, types.PerfSampleInfo{Timestamp: end, Interval: interval})
+			buckets = append(buckets, nil)
+			n++
+		}
+		buckets[n-1] = append(buckets[n-1], i)
+	}
+
+	for _, s := range m.Value {
+		r := s
+		r.Value = make([]int64, len(buckets))
+
+		for i, bucket := range buckets {
+			vals := make([]float64, 0, len(bucket))
+			for _, j := range bucket {
+				if j < len(s.Value) {
+					vals = append(vals, float64(s.Value[j]))
+				}
+			}
+			if len(vals) == 0 {
+				continue
+			}
+
+			v, _ := Statistic(DefaultStat(s.rollup()), vals)
+			r.Value[i] = int64(math.Round(v))
+		}
+
+		res.Value = append(res.Value, r)
+	}
+
+	return res
+}
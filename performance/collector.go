@@ -0,0 +1,341 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package performance
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vmware/govmomi/fault"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Sample is a single metric value, converted to the counter's base unit.
+type Sample struct {
+	Entity   types.ManagedObjectReference `json:"entity"`
+	Name     string                       `json:"name"`    // Entity name
+	Counter  string                       `json:"counter"` // Counter name, such as "cpu.usage.average"
+	Instance string                       `json:"instance"`
+	Interval int32                        `json:"interval"`
+	Time     time.Time                    `json:"time"`
+	Unit     string                       `json:"unit"` // Base unit, such as "bytes" or "seconds"
+	Value    float64                      `json:"value"`
+	Summary  string                       `json:"-"` // Counter description
+}
+
+// Collector periodically samples performance metrics for the entities within a container.
+type Collector struct {
+	m *Manager
+
+	Container types.ManagedObjectReference // Defaults to the root folder
+	Types     []string                     // Entity types, such as "VirtualMachine" and "HostSystem"
+	Metrics   []string                     // Counter names, such as "cpu.usage.average"
+	Instance  string                       // Metric instance, defaults to "*" (all instances)
+	Intervals []int32                      // Interval IDs, defaults to 20 (realtime)
+	Period    time.Duration                // Time between collections, defaults to 20s
+
+	// MaxQueryMetrics is the maximum number of metrics per QueryPerf call.
+	// Defaults to the vCenter "config.vpxd.stats.maxQueryMetrics" setting, if any. A negative value disables batching.
+	MaxQueryMetrics int
+
+	// Sample, if set, is called with the samples of each collection.
+	Sample func([]Sample) error
+
+	mu      sync.Mutex
+	samples []Sample
+}
+
+// NewCollector creates a new Collector instance.
+func NewCollector(m *Manager) *Collector {
+	return &Collector{
+		m:         m,
+		Container: m.Client().ServiceContent.RootFolder,
+		Types:     []string{"VirtualMachine", "HostSystem"},
+		Instance:  "*",
+		Intervals: []int32{20},
+		Period:    20 * time.Second,
+	}
+}
+
+// maxQueryMetrics returns the maximum number of metrics per query, 0 if unlimited.
+func (c *Collector) maxQueryMetrics(ctx context.Context) (int, error) {
+	if c.MaxQueryMetrics != 0 {
+		if c.MaxQueryMetrics < 0 {
+			return 0, nil
+		}
+		return c.MaxQueryMetrics, nil
+	}
+
+	client := c.m.Client()
+	if client.ServiceContent.Setting == nil {
+		return 0, nil // ESX
+	}
+
+	opts, err := object.NewOptionManager(client, *client.ServiceContent.Setting).Query(ctx, "config.vpxd.stats.maxQueryMetrics")
+	if err != nil {
+		if fault.Is(err, new(types.InvalidName)) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	for _, opt := range opts {
+		n, err := strconv.Atoi(fmt.Sprint(opt.GetOptionValue().Value))
+		if err == nil && n > 0 {
+			return n, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// entities returns the name of each entity in the Container, with the given Types.
+func (c *Collector) entities(ctx context.Context) (map[types.ManagedObjectReference]string, error) {
+	v, err := view.NewManager(c.m.Client()).CreateContainerView(ctx, c.Container, c.Types, true)
+	if err != nil {
+		return nil, err
+	}
+
+	defer v.Destroy(context.Background())
+
+	var content []mo.ManagedEntity
+	err = v.Retrieve(ctx, c.Types, []string{"name"}, &content)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[types.ManagedObjectReference]string, len(content))
+	for _, e := range content {
+		names[e.Self] = e.Name
+	}
+
+	return names, nil
+}
+
+// batch splits the specs such that each batch contains at most max metrics.
+func batch(specs []types.PerfQuerySpec, max int) [][]types.PerfQuerySpec {
+	if max <= 0 {
+		return [][]types.PerfQuerySpec{specs}
+	}
+
+	var batches [][]types.PerfQuerySpec
+	var cur []types.PerfQuerySpec
+	n := 0
+
+	for _, spec := range specs {
+		ids := spec.MetricId
+
+		for len(ids) != 0 {
+			if n == max {
+				batches = append(batches, cur)
+				cur, n = nil, 0
+			}
+
+			size := max - n
+			if size > len(ids) {
+				size = len(ids)
+			}
+
+			s := spec
+			s.MetricId = ids[:size]
+			cur = append(cur, s)
+			n += size
+			ids = ids[size:]
+		}
+	}
+
+	if len(cur) != 0 {
+		batches = append(batches, cur)
+	}
+
+	return batches
+}
+
+// Collect queries the latest sample of each metric for each entity and interval.
+func (c *Collector) Collect(ctx context.Context) ([]Sample, error) {
+	info, err := c.m.CounterInfoByName(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	counters, err := c.m.CounterInfoByKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	max, err := c.maxQueryMetrics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := c.entities(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []types.PerfMetricId
+	for _, name := range c.Metrics {
+		counter, ok := info[name]
+		if !ok {
+			return nil, fmt.Errorf("counter %q not found", name)
+		}
+		ids = append(ids, types.PerfMetricId{CounterId: counter.Key, Instance: c.Instance})
+	}
+
+	now, err := methods.GetCurrentTime(ctx, c.m.Client())
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]types.ManagedObjectReference, 0, len(names))
+	for entity := range names {
+		entities = append(entities, entity)
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].Value < entities[j].Value
+	})
+
+	var specs []types.PerfQuerySpec
+
+	for _, interval := range c.Intervals {
+		spec := types.PerfQuerySpec{
+			IntervalId: interval,
+			MaxSample:  1,
+			MetricId:   ids,
+		}
+
+		if interval >= 60 {
+			// Historical intervals require a StartTime
+			start := now.Add(time.Duration(-2*interval) * time.Second)
+			spec.StartTime = &start
+		}
+
+		for _, entity := range entities {
+			spec.Entity = entity
+			specs = append(specs, spec)
+		}
+	}
+
+	var samples []Sample
+
+	for _, query := range batch(specs, max) {
+		res, err := c.m.Query(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, base := range res {
+			metric, ok := base.(*types.PerfEntityMetric)
+			if !ok || len(metric.SampleInfo) == 0 {
+				continue
+			}
+
+			last := len(metric.SampleInfo) - 1
+			sample := metric.SampleInfo[last]
+
+			for _, val := range metric.Value {
+				series, ok := val.(*types.PerfMetricIntSeries)
+				if !ok || len(series.Value) <= last {
+					continue
+				}
+
+				counter := counters[series.Id.CounterId]
+				if counter == nil {
+					continue
+				}
+
+				value, unit := Convert(counter, series.Value[last])
+
+				s := Sample{
+					Entity:   metric.Entity,
+					Name:     names[metric.Entity],
+					Counter:  counter.Name(),
+					Instance: series.Id.Instance,
+					Interval: sample.Interval,
+					Time:     sample.Timestamp,
+					Unit:     unit,
+					Value:    value,
+				}
+
+				if d := counter.NameInfo.GetElementDescription(); d != nil {
+					s.Summary = d.Summary
+				}
+
+				samples = append(samples, s)
+			}
+		}
+	}
+
+	c.mu.Lock()
+	c.samples = samples
+	c.mu.Unlock()
+
+	return samples, nil
+}
+
+// Samples returns the samples of the most recent collection.
+func (c *Collector) Samples() []Sample {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.samples
+}
+
+// Run collects samples every Period until the context is canceled or an error occurs.
+func (c *Collector) Run(ctx context.Context) error {
+	period := c.Period
+	if period <= 0 {
+		period = 20 * time.Second
+	}
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		samples, err := c.Collect(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if c.Sample != nil {
+			if err = c.Sample(samples); err != nil {
+				return err
+			}
+		}
+
+		if ctx.Err() != nil {
+			return nil // select below would pick at random when both channels are ready
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package performance
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Convert returns the value of a counter in its base unit, along with the name of the base unit.
+// For example, a value in kiloBytes is converted to bytes and a percent value is converted to a ratio.
+// The unit is empty for counters that are a plain number.
+func Convert(counter *types.PerfCounterInfo, val int64) (float64, string) {
+	v := float64(val)
+
+	switch types.PerformanceManagerUnit(counter.UnitInfo.GetElementDescription().Key) {
+	case types.PerformanceManagerUnitPercent:
+		return v / 10000, "ratio" // percent values are scaled by 100
+	case types.PerformanceManagerUnitKiloBytes:
+		return v * 1024, "bytes"
+	case types.PerformanceManagerUnitMegaBytes:
+		return v * 1024 * 1024, "bytes"
+	case types.PerformanceManagerUnitTeraBytes:
+		return v * 1024 * 1024 * 1024 * 1024, "bytes"
+	case types.PerformanceManagerUnitKiloBytesPerSecond:
+		return v * 1024, "bytes_per_second"
+	case types.PerformanceManagerUnitMegaBytesPerSecond:
+		return v * 1024 * 1024, "bytes_per_second"
+	case types.PerformanceManagerUnitMegaHertz:
+		return v * 1000 * 1000, "hertz"
+	case types.PerformanceManagerUnitMicrosecond:
+		return v / 1000 / 1000, "seconds"
+	case types.PerformanceManagerUnitMillisecond:
+		return v / 1000, "seconds"
+	case types.PerformanceManagerUnitSecond:
+		return v, "seconds"
+	case types.PerformanceManagerUnitWatt:
+		return v, "watts"
+	case types.PerformanceManagerUnitJoule:
+		return v, "joules"
+	case "celsius":
+		return v, "celsius"
+	default:
+		return v, ""
+	}
+}
+
+// MetricName returns the OpenMetrics name for the given Sample, such as "vsphere_cpu_usage_average_ratio".
+func MetricName(s *Sample) string {
+	name := "vsphere_" + s.Counter
+	if s.Unit != "" {
+		name += "_" + s.Unit
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == ':':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+var labelEscape = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WriteOpenMetrics writes the samples in the OpenMetrics text format.
+// Each counter is exposed as a gauge, labeled with the entity name, type and reference value, the metric instance and the interval ID.
+func WriteOpenMetrics(w io.Writer, samples []Sample) error {
+	byName := make(map[string][]*Sample)
+	var names []string
+
+	for i := range samples {
+		name := MetricName(&samples[i])
+		if _, ok := byName[name]; !ok {
+			names = append(names, name)
+		}
+		byName[name] = append(byName[name], &samples[i])
+	}
+
+	sort.Strings(names)
+
+	b := bufio.NewWriter(w)
+
+	for _, name := range names {
+		family := byName[name]
+
+		fmt.Fprintf(b, "# TYPE %s gauge\n", name)
+		if unit := family[0].Unit; unit != "" {
+			fmt.Fprintf(b, "# UNIT %s %s\n", name, unit)
+		}
+		if help := family[0].Summary; help != "" {
+			fmt.Fprintf(b, "# HELP %s %s\n", name, labelEscape.Replace(help))
+		}
+
+		for _, s := range family {
+			fmt.Fprintf(b, "%s{entity=\"%s\",type=\"%s\",moref=\"%s\",instance=\"%s\",interval=\"%d\"} %s %s\n",
+				name,
+				labelEscape.Replace(s.Name),
+				s.Entity.Type,
+				labelEscape.Replace(s.Entity.Value),
+				labelEscape.Replace(s.Instance),
+				s.Interval,
+				strconv.FormatFloat(s.Value, 'g', -1, 64),
+				strconv.FormatFloat(float64(s.Time.UnixNano())/1e9, 'f', 3, 64))
+		}
+	}
+
+	fmt.Fprintln(b, "# EOF")
+
+	return b.Flush()
+}
+
+// WriteCSV writes the samples as CSV, with a header row if header is true.
+func WriteCSV(w io.Writer, samples []Sample, header bool) error {
+	cw := csv.NewWriter(w)
+
+	if header {
+		_ = cw.Write([]string{"time", "entity", "name", "counter", "instance", "interval", "unit", "value"})
+	}
+
+	for _, s := range samples {
+		_ = cw.Write([]string{
+			s.Time.Format(time.RFC3339),
+			s.Entity.String(),
+			s.Name,
+			s.Counter,
+			s.Instance,
+			strconv.Itoa(int(s.Interval)),
+			s.Unit,
+			strconv.FormatFloat(s.Value, 'g', -1, 64),
+		})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes each sample as a line of JSON.
+func WriteJSON(w io.Writer, samples []Sample) error {
+	enc := json.NewEncoder(w)
+
+	for i := range samples {
+		if err := enc.Encode(&samples[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ServeHTTP implements http.Handler, exposing the samples of the most recent collection in the OpenMetrics text format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+	_ = WriteOpenMetrics(w, c.Samples())
+}
@@ -324,6 +324,7 @@ func (m *Manager) SampleByName(ctx context.Context, spec types.PerfQuerySpec, me
 type MetricSeries struct {
 	Name     string
 	unit     string
+	info     *types.PerfCounterInfo
 	Instance string
 	Value    []int64
 }
@@ -394,6 +395,7 @@ func (m *Manager) ToMetricSeries(ctx context.Context, series []types.BasePerfEnt
 			values = append(values, MetricSeries{
 				Name:     counters[v.Id.CounterId].Name(),
 				unit:     counters[v.Id.CounterId].UnitInfo.GetElementDescription().Key,
+				info:     counters[v.Id.CounterId],
 				Instance: v.Id.Instance,
 				Value:    v.Value,
 			})
@@ -35,6 +35,7 @@ import (
 type Collector struct {
 	roundTripper soap.RoundTripper
 	reference    types.ManagedObjectReference
+	options      RetrieveOptions
 }
 
 // DefaultCollector returns the session's default property collector.
@@ -117,39 +118,156 @@ func (p *Collector) CancelWaitForUpdates(ctx context.Context) error {
 	return err
 }
 
-func (p *Collector) RetrieveProperties(ctx context.Context, req types.RetrieveProperties) (*types.RetrievePropertiesResponse, error) {
+// RetrieveOptions configures the paging behavior of RetrieveProperties and the methods that use it,
+// such as Retrieve and RetrieveWithFilter.
+type RetrieveOptions struct {
+	// MaxObjects limits the number of objects returned per page.
+	// A value of 0 leaves the page size to the server.
+	MaxObjects int32
+
+	// Page, if set, is called with each page of results as it is retrieved.
+	// The results are not accumulated in this case, leaving the caller's dst empty.
+	Page func([]types.ObjectContent) error
+}
+
+// WithRetrieveOptions returns a copy of the Collector, configured with the given RetrieveOptions.
+func (p Collector) WithRetrieveOptions(opts RetrieveOptions) *Collector {
+	p.options = opts
+	return &p
+}
+
+// withoutPage returns a copy of the Collector with the RetrieveOptions.Page callback unset,
+// for intermediate retrievals such as the filter matching done by RetrieveWithFilter.
+func (p Collector) withoutPage() *Collector {
+	p.options.Page = nil
+	return &p
+}
+
+func (p *Collector) RetrievePropertiesEx(ctx context.Context, req types.RetrievePropertiesEx) (*types.RetrieveResult, error) {
 	req.This = p.Reference()
-	return methods.RetrieveProperties(ctx, p.roundTripper, &req)
+
+	res, err := methods.RetrievePropertiesEx(ctx, p.roundTripper, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Returnval, nil
+}
+
+func (p *Collector) ContinueRetrievePropertiesEx(ctx context.Context, token string) (*types.RetrieveResult, error) {
+	req := types.ContinueRetrievePropertiesEx{
+		This:  p.Reference(),
+		Token: token,
+	}
+
+	res, err := methods.ContinueRetrievePropertiesEx(ctx, p.roundTripper, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &res.Returnval, nil
+}
+
+func (p *Collector) CancelRetrievePropertiesEx(ctx context.Context, token string) error {
+	req := types.CancelRetrievePropertiesEx{
+		This:  p.Reference(),
+		Token: token,
+	}
+
+	_, err := methods.CancelRetrievePropertiesEx(ctx, p.roundTripper, &req)
+	return err
+}
+
+// RetrieveProperties retrieves the properties specified by req using RetrievePropertiesEx,
+// following the continuation token via ContinueRetrievePropertiesEx until all pages have been retrieved.
+// The page size and an optional page callback can be configured using WithRetrieveOptions.
+func (p *Collector) RetrieveProperties(ctx context.Context, req types.RetrieveProperties) (*types.RetrievePropertiesResponse, error) {
+	opts := p.options
+
+	rreq := types.RetrievePropertiesEx{
+		SpecSet: req.SpecSet,
+		Options: types.RetrieveOptions{
+			MaxObjects: opts.MaxObjects,
+		},
+	}
+
+	res := &types.RetrievePropertiesResponse{}
+
+	result, err := p.RetrievePropertiesEx(ctx, rreq)
+
+	for {
+		if err != nil {
+			return nil, err
+		}
+
+		if result == nil {
+			break // no objects matched the specs
+		}
+
+		if opts.Page == nil {
+			res.Returnval = append(res.Returnval, result.Objects...)
+		} else if err = opts.Page(result.Objects); err != nil {
+			if result.Token != "" {
+				_ = p.CancelRetrievePropertiesEx(ctx, result.Token)
+			}
+			return nil, err
+		}
+
+		if result.Token == "" {
+			break
+		}
+
+		result, err = p.ContinueRetrievePropertiesEx(ctx, result.Token)
+	}
+
+	return res, nil
 }
 
 // Retrieve loads properties for a slice of managed objects. The dst argument
 // must be a pointer to a []interface{}, which is populated with the instances
 // of the specified managed objects, with the relevant properties filled in. If
 // the properties slice is nil, all properties are loaded.
+// The managed objects may be of different types, in which case the properties
+// must be valid for each type, such as "name" for any ManagedEntity.
+// See also RetrieveByType and mo.LoadRetrievePropertiesResponse.
 func (p *Collector) Retrieve(ctx context.Context, objs []types.ManagedObjectReference, ps []string, dst interface{}) error {
+	return p.retrieve(ctx, objs, func(string) []string { return ps }, dst)
+}
+
+// RetrieveByType populates dst as Retrieve does, for managed objects of mixed types.
+// The ps map specifies the properties to load for each managed object type,
+// where a type with a nil (or no) entry has all properties loaded.
+// The dst argument can be a pointer to a []interface{} or a map of type name to typed slice pointer,
+// for example: map[string]interface{}{"VirtualMachine": &vms, "HostSystem": &hosts}
+func (p *Collector) RetrieveByType(ctx context.Context, objs []types.ManagedObjectReference, ps map[string][]string, dst interface{}) error {
+	return p.retrieve(ctx, objs, func(kind string) []string { return ps[kind] }, dst)
+}
+
+func (p *Collector) retrieve(ctx context.Context, objs []types.ManagedObjectReference, ps func(string) []string, dst interface{}) error {
 	if len(objs) == 0 {
 		return errors.New("object references is empty")
 	}
 
-	var propSpec *types.PropertySpec
+	var propSet []types.PropertySpec
 	var objectSet []types.ObjectSpec
+	kinds := make(map[string]bool)
 
 	for _, obj := range objs {
-		// Ensure that all object reference types are the same
-		if propSpec == nil {
-			propSpec = &types.PropertySpec{
+		// Include a PropertySpec for each object reference type
+		if !kinds[obj.Type] {
+			kinds[obj.Type] = true
+
+			propSpec := types.PropertySpec{
 				Type: obj.Type,
 			}
 
-			if ps == nil {
+			if props := ps(obj.Type); props == nil {
 				propSpec.All = types.NewBool(true)
 			} else {
-				propSpec.PathSet = ps
-			}
-		} else {
-			if obj.Type != propSpec.Type {
-				return errors.New("object references must have the same type")
+				propSpec.PathSet = props
 			}
+
+			propSet = append(propSet, propSpec)
 		}
 
 		objectSpec := types.ObjectSpec{
@@ -164,7 +282,7 @@ func (p *Collector) Retrieve(ctx context.Context, objs []types.ManagedObjectRefe
 		SpecSet: []types.PropertyFilterSpec{
 			{
 				ObjectSet: objectSet,
-				PropSet:   []types.PropertySpec{*propSpec},
+				PropSet:   propSet,
 			},
 		},
 	}
@@ -190,7 +308,7 @@ func (p *Collector) RetrieveWithFilter(ctx context.Context, objs []types.Managed
 
 	var content []types.ObjectContent
 
-	err := p.Retrieve(ctx, objs, filter.Keys(), &content)
+	err := p.withoutPage().Retrieve(ctx, objs, filter.Keys(), &content)
 	if err != nil {
 		return err
 	}
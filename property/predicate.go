@@ -0,0 +1,598 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package property
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Predicate matches objects by their property values, supporting comparison operators, regular expressions,
+// existence checks and boolean combinations.  See ParsePredicate.
+type Predicate struct {
+	node node
+}
+
+type node interface {
+	match(props []types.DynamicProperty) bool
+	paths() []string
+}
+
+type andNode []node
+
+func (n andNode) match(props []types.DynamicProperty) bool {
+	for _, x := range n {
+		if !x.match(props) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n andNode) paths() []string {
+	var paths []string
+	for _, x := range n {
+		paths = append(paths, x.paths()...)
+	}
+	return paths
+}
+
+type orNode []node
+
+func (n orNode) match(props []types.DynamicProperty) bool {
+	for _, x := range n {
+		if x.match(props) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n orNode) paths() []string {
+	return andNode(n).paths()
+}
+
+type notNode struct {
+	node
+}
+
+func (n notNode) match(props []types.DynamicProperty) bool {
+	return !n.node.match(props)
+}
+
+type comparison struct {
+	path  string
+	op    string
+	value types.AnyType
+	re    *regexp.Regexp
+}
+
+func (c *comparison) paths() []string {
+	return []string{c.path}
+}
+
+func (c *comparison) match(props []types.DynamicProperty) bool {
+	vals := values(props, c.path)
+
+	switch c.op {
+	case "!=", "!~":
+		return !c.any(vals)
+	default:
+		return c.any(vals)
+	}
+}
+
+// any returns true if any of the given values match, where "!=" and "!~" match as "==" and "=~" respectively.
+func (c *comparison) any(vals []reflect.Value) bool {
+	for _, val := range vals {
+		switch c.op {
+		case "":
+			if !reflect.DeepEqual(val.Interface(), reflect.Zero(val.Type()).Interface()) {
+				return true
+			}
+		case "==", "!=":
+			f := Filter{c.path: c.value}
+			if f.MatchProperty(types.DynamicProperty{Name: c.path, Val: val.Interface()}) {
+				return true
+			}
+		case "=~", "!~":
+			if c.re.MatchString(valueString(val)) {
+				return true
+			}
+		default:
+			n, ok := compare(val, c.value.(string))
+			if !ok {
+				continue
+			}
+			switch c.op {
+			case "<":
+				ok = n < 0
+			case "<=":
+				ok = n <= 0
+			case ">":
+				ok = n > 0
+			case ">=":
+				ok = n >= 0
+			}
+			if ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// values returns the values of the given property path, where the path may extend beyond the collected property,
+// such as the "ipAddress" field of each element of the VirtualMachine "guest.net" property.
+func values(props []types.DynamicProperty, path string) []reflect.Value {
+	for _, p := range props {
+		if p.Name == path {
+			return walk(reflect.ValueOf(p.Val), nil)
+		}
+		if strings.HasPrefix(path, p.Name+".") {
+			return walk(reflect.ValueOf(p.Val), strings.Split(path[len(p.Name)+1:], "."))
+		}
+	}
+	return nil
+}
+
+func walk(val reflect.Value, path []string) []reflect.Value {
+	for val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+
+	if !val.IsValid() {
+		return nil
+	}
+
+	if val.Kind() == reflect.Struct && val.NumField() == 1 && strings.HasPrefix(val.Type().Name(), "ArrayOf") {
+		val = val.Field(0)
+	}
+
+	if val.Kind() == reflect.Slice {
+		var res []reflect.Value
+		for i := 0; i < val.Len(); i++ {
+			res = append(res, walk(val.Index(i), path)...)
+		}
+		return res
+	}
+
+	if len(path) == 0 {
+		return []reflect.Value{val}
+	}
+
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return walk(field(val, path[0]), path[1:])
+}
+
+// field returns the struct field with the given xml name, ignoring case and including embedded structs.
+func field(val reflect.Value, name string) reflect.Value {
+	for i := 0; i < val.NumField(); i++ {
+		f := val.Type().Field(i)
+
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if v := field(val.Field(i), name); v.IsValid() {
+				return v
+			}
+			continue
+		}
+
+		tag := strings.Split(f.Tag.Get("xml"), ",")[0]
+		if strings.EqualFold(tag, name) || strings.EqualFold(f.Name, name) {
+			return val.Field(i)
+		}
+	}
+
+	return reflect.Value{}
+}
+
+func valueString(val reflect.Value) string {
+	if s, ok := val.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	if val.Kind() == reflect.String {
+		return val.String() // enum types
+	}
+	return fmt.Sprint(val.Interface())
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// compare returns the result of comparing val to s, and false if the values cannot be compared.
+// Numbers are compared numerically, times as RFC3339 and strings lexically unless both are numeric.
+func compare(val reflect.Value, s string) (int, bool) {
+	if t, ok := val.Interface().(time.Time); ok {
+		x, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return 0, false
+		}
+		switch {
+		case t.Before(x):
+			return -1, true
+		case t.After(x):
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+
+	x, err := strconv.ParseFloat(s, 64)
+
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmpFloat(float64(val.Int()), x), err == nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmpFloat(float64(val.Uint()), x), err == nil
+	case reflect.Float32, reflect.Float64:
+		return cmpFloat(val.Float(), x), err == nil
+	case reflect.String:
+		if err == nil {
+			if y, verr := strconv.ParseFloat(val.String(), 64); verr == nil {
+				return cmpFloat(y, x), true
+			}
+		}
+		return strings.Compare(val.String(), s), true
+	default:
+		return 0, false
+	}
+}
+
+// CollectPath returns the longest prefix of the given property path that can be collected for each of the given
+// managed object types.  See mo.CollectPath.
+func CollectPath(path string, kind ...string) string {
+	res := path
+
+	for _, k := range kind {
+		if p := mo.CollectPath(k, path); len(p) < len(res) {
+			res = p
+		}
+	}
+
+	return res
+}
+
+// Keys returns the property paths to collect for evaluating the Predicate against objects of the given types.
+func (p *Predicate) Keys(kind ...string) []string {
+	var keys []string
+	seen := make(map[string]bool)
+
+	for _, path := range p.node.paths() {
+		key := CollectPath(path, kind...)
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// Match returns true if the given properties match the Predicate.
+func (p *Predicate) Match(props []types.DynamicProperty) bool {
+	return p.node.match(props)
+}
+
+// Select returns the objects where ObjectContent.PropSet matches the Predicate.
+func (p *Predicate) Select(objects []types.ObjectContent) []types.ObjectContent {
+	var res []types.ObjectContent
+
+	for _, o := range objects {
+		if p.Match(o.PropSet) {
+			res = append(res, o)
+		}
+	}
+
+	return res
+}
+
+// And returns a Predicate that matches if p and each of the given predicates match.
+func (p *Predicate) And(x ...*Predicate) *Predicate {
+	n := andNode{p.node}
+	for i := range x {
+		n = append(n, x[i].node)
+	}
+	return &Predicate{node: n}
+}
+
+// Predicate returns a Predicate equivalent to the Filter.
+func (f Filter) Predicate() *Predicate {
+	var n andNode
+
+	for _, key := range f.Keys() {
+		n = append(n, &comparison{path: key, op: "==", value: f[key]})
+	}
+
+	sort.Slice(n, func(i, j int) bool {
+		return n[i].(*comparison).path < n[j].(*comparison).path
+	})
+
+	return &Predicate{node: n}
+}
+
+// SortObjectContent sorts objects by the value of the given property path, using the same comparison rules as Predicate.
+// Objects without a value for the path sort first, or last if desc is true.  If the path refers to multiple values, the first is used.
+func SortObjectContent(objects []types.ObjectContent, path string, desc bool) {
+	first := func(i int) (reflect.Value, bool) {
+		vals := values(objects[i].PropSet, path)
+		if len(vals) == 0 {
+			return reflect.Value{}, false
+		}
+		return vals[0], true
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		a, aok := first(i)
+		b, bok := first(j)
+		if !aok || !bok {
+			if desc {
+				return aok && !bok
+			}
+			return !aok && bok
+		}
+
+		n, ok := compare(a, valueString(b))
+		if !ok {
+			n = strings.Compare(valueString(a), valueString(b))
+		}
+		if desc {
+			return n > 0
+		}
+		return n < 0
+	})
+}
+
+type predicateParser struct {
+	expr string
+	pos  int
+}
+
+// ParsePredicate parses a Predicate expression, such as "summary.quickStats.overallCpuUsage>1000 && runtime.powerState==poweredOn"
+// or "(name=~'^web-[0-9]+$' || name==db-*) && !config.template".
+// A comparison is a property path, followed by an operator and a value.  The operators "==" (or "=") and "!="
+// support the same value conversion and glob matching as Filter, "<", "<=", ">" and ">=" compare numbers and
+// RFC3339 times, "=~" and "!~" match a regular expression.  Values containing spaces, parentheses or operators
+// can be quoted.  A property path without an operator matches if the property is set to a non-zero value.
+// Paths may extend into array properties, such as "guest.net.ipAddress", in which case a comparison matches if
+// any of the array elements match, and "!=" matches if none of the elements are equal.
+// Comparisons can be combined with "&&", "||", "!" and parentheses.
+func ParsePredicate(expr string) (*Predicate, error) {
+	p := &predicateParser{expr: expr}
+
+	n, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skip()
+	if p.pos != len(p.expr) {
+		return nil, p.errorf("unexpected %q", p.expr[p.pos:])
+	}
+
+	return &Predicate{node: n}, nil
+}
+
+func (p *predicateParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid predicate %q at offset %d: %s", p.expr, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *predicateParser) skip() {
+	for p.pos < len(p.expr) && (p.expr[p.pos] == ' ' || p.expr[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *predicateParser) next(token string) bool {
+	p.skip()
+	if strings.HasPrefix(p.expr[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *predicateParser) or() (node, error) {
+	var n orNode
+
+	for {
+		x, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		n = append(n, x)
+
+		if !p.next("||") {
+			break
+		}
+	}
+
+	if len(n) == 1 {
+		return n[0], nil
+	}
+	return n, nil
+}
+
+func (p *predicateParser) and() (node, error) {
+	var n andNode
+
+	for {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		n = append(n, x)
+
+		if !p.next("&&") {
+			break
+		}
+	}
+
+	if len(n) == 1 {
+		return n[0], nil
+	}
+	return n, nil
+}
+
+func (p *predicateParser) unary() (node, error) {
+	if p.next("!") {
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x}, nil
+	}
+
+	if p.next("(") {
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.next(")") {
+			return nil, p.errorf("expected ')'")
+		}
+		return x, nil
+	}
+
+	return p.comparison()
+}
+
+var predicateOperators = []string{"==", "!=", ">=", "<=", "=~", "!~", ">", "<", "="}
+
+func (p *predicateParser) comparison() (node, error) {
+	p.skip()
+
+	start := p.pos
+	for p.pos < len(p.expr) {
+		c := p.expr[p.pos]
+		if c != '.' && c != '_' && !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && !('0' <= c && c <= '9') {
+			break
+		}
+		p.pos++
+	}
+
+	c := &comparison{path: p.expr[start:p.pos]}
+	if c.path == "" {
+		return nil, p.errorf("expected property path")
+	}
+
+	for _, op := range predicateOperators {
+		if p.next(op) {
+			c.op = op
+			break
+		}
+	}
+
+	switch c.op {
+	case "":
+		return c, nil // existence check
+	case "=":
+		c.op = "=="
+	}
+
+	val, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	c.value = val
+
+	switch c.op {
+	case "=~", "!~":
+		if c.re, err = regexp.Compile(val); err != nil {
+			return nil, p.errorf("%s", err)
+		}
+	case "<", "<=", ">", ">=":
+		if _, err = strconv.ParseFloat(val, 64); err != nil {
+			if _, err = time.Parse(time.RFC3339, val); err != nil {
+				return nil, p.errorf("%s operand %q is not a number or RFC3339 time", c.op, val)
+			}
+		}
+	}
+
+	return c, nil
+}
+
+func (p *predicateParser) value() (string, error) {
+	p.skip()
+
+	if p.pos == len(p.expr) {
+		return "", p.errorf("expected value")
+	}
+
+	switch q := p.expr[p.pos]; q {
+	case '\'':
+		end := strings.IndexByte(p.expr[p.pos+1:], q)
+		if end < 0 {
+			return "", p.errorf("unterminated string")
+		}
+		val := p.expr[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return val, nil
+	case '"':
+		for end := p.pos + 1; end < len(p.expr); end++ {
+			switch p.expr[end] {
+			case '\\':
+				end++
+			case '"':
+				val, err := strconv.Unquote(p.expr[p.pos : end+1])
+				if err != nil {
+					return "", p.errorf("%s", err)
+				}
+				p.pos = end + 1
+				return val, nil
+			}
+		}
+		return "", p.errorf("unterminated string")
+	}
+
+	start := p.pos
+	for p.pos < len(p.expr) {
+		rest := p.expr[p.pos:]
+		if rest[0] == ' ' || rest[0] == '\t' || rest[0] == ')' || strings.HasPrefix(rest, "&&") || strings.HasPrefix(rest, "||") {
+			break
+		}
+		p.pos++
+	}
+
+	if p.pos == start {
+		return "", p.errorf("expected value")
+	}
+
+	return p.expr[start:p.pos], nil
+}
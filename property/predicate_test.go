@@ -0,0 +1,144 @@
+/*
+Copyright (c) 2017 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package property
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestPredicate(t *testing.T) {
+	boot := time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	props := []types.DynamicProperty{
+		{Name: "name", Val: "web-01"},
+		{Name: "runtime.powerState", Val: types.VirtualMachinePowerStatePoweredOn},
+		{Name: "runtime.bootTime", Val: boot},
+		{Name: "summary.quickStats.overallCpuUsage", Val: int32(1500)},
+		{Name: "config.template", Val: false},
+		{Name: "guest.net", Val: types.ArrayOfGuestNicInfo{GuestNicInfo: []types.GuestNicInfo{
+			{Network: "VM Network", IpAddress: []string{"10.0.0.5", "fe80::1"}, Connected: true},
+			{Network: "Backup", IpAddress: []string{"192.168.1.5"}},
+		}}},
+		{Name: "datastore", Val: types.ArrayOfManagedObjectReference{ManagedObjectReference: []types.ManagedObjectReference{
+			{Type: "Datastore", Value: "datastore-1"},
+		}}},
+	}
+
+	tests := []struct {
+		expr  string
+		match bool
+	}{
+		{"name==web-01", true},
+		{"name=web-*", true},
+		{"name!=web-*", false},
+		{"name=~'^web-[0-9]+$'", true},
+		{"name!~^db", true},
+		{"runtime.powerState==poweredOn", true},
+		{"summary.quickStats.overallCpuUsage>1000", true},
+		{"summary.quickStats.overallCpuUsage >= 1500", true},
+		{"summary.quickStats.overallCpuUsage<1000", false},
+		{"runtime.bootTime<2018-10-02T00:00:00Z", true},
+		{"runtime.bootTime>2018-10-02T00:00:00Z", false},
+		{"config.template", false},
+		{"!config.template", true},
+		{"guest.net", true},
+		{"guest.ipAddress", false},
+		{"guest.net.ipAddress==192.168.1.*", true},
+		{"guest.net.ipAddress!=192.168.1.*", false},
+		{"guest.net.network=='VM Network'", true},
+		{"guest.net.connected==true", true},
+		{"guest.net.ipAddress==172.16.*", false},
+		{"datastore==Datastore:datastore-1", true},
+		{"name==db-01 || summary.quickStats.overallCpuUsage>1000", true},
+		{"name==db-01 || summary.quickStats.overallCpuUsage>2000", false},
+		{"(name==db-01 || name==web-01) && !(runtime.powerState==poweredOff)", true},
+		{`name=="web-01" && guest.net.ipAddress=~"^fe80:"`, true},
+		{"enoent", false},
+		{"!enoent", true},
+	}
+
+	for _, test := range tests {
+		p, err := ParsePredicate(test.expr)
+		if err != nil {
+			t.Fatalf("%s: %s", test.expr, err)
+		}
+
+		if p.Match(props) != test.match {
+			t.Errorf("%s: expected %t", test.expr, test.match)
+		}
+	}
+
+	for _, expr := range []string{"", "name==", "name=~(", "(name==x", "name==x)", "name==x &&", "==x", "name=='x",
+		"summary.quickStats.overallCpuUsage>abc", "config.hardware.numCPU<=2x", "runtime.bootTime>'2018-10-02'"} {
+		if _, err := ParsePredicate(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+
+	p, _ := ParsePredicate("guest.net.ipAddress==10.* && name==web-01 || config.hardware.device.key>0")
+	keys := p.Keys("VirtualMachine")
+	expect := []string{"guest.net", "name", "config.hardware.device"}
+	if len(keys) != len(expect) {
+		t.Fatalf("keys=%v", keys)
+	}
+	for i := range keys {
+		if keys[i] != expect[i] {
+			t.Errorf("keys=%v", keys)
+		}
+	}
+}
+
+func TestSortObjectContent(t *testing.T) {
+	content := func(name string, cpu int32) types.ObjectContent {
+		o := types.ObjectContent{PropSet: []types.DynamicProperty{{Name: "name", Val: name}}}
+		if cpu >= 0 {
+			o.PropSet = append(o.PropSet, types.DynamicProperty{Name: "summary.quickStats.overallCpuUsage", Val: cpu})
+		}
+		return o
+	}
+
+	objects := []types.ObjectContent{content("b", 900), content("a", 1000), content("c", -1), content("d", 80)}
+
+	names := func() string {
+		var s string
+		for _, o := range objects {
+			s += o.PropSet[0].Val.(string)
+		}
+		return s
+	}
+
+	tests := []struct {
+		path   string
+		desc   bool
+		expect string
+	}{
+		{"name", false, "abcd"},
+		{"name", true, "dcba"},
+		{"summary.quickStats.overallCpuUsage", false, "cdba"},
+		{"summary.quickStats.overallCpuUsage", true, "abdc"},
+	}
+
+	for _, test := range tests {
+		SortObjectContent(objects, test.path, test.desc)
+		if s := names(); s != test.expect {
+			t.Errorf("%s desc=%t: %s", test.path, test.desc, s)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package session
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// ErrPoolClosed is returned by Pool.Get after the Pool has been closed.
+var ErrPoolClosed = errors.New("session: pool closed")
+
+// Pool hands out up to a fixed number of independently authenticated clients, for use in parallel work.
+// Clients are created on demand by the Pool's connect function and reused once returned via Put.
+type Pool struct {
+	connect func(context.Context) (*vim25.Client, error)
+
+	mu      sync.Mutex
+	idle    []*vim25.Client
+	size    int
+	created int
+	closed  bool
+	notify  chan struct{}
+}
+
+// NewPool creates a Pool of up to size clients, created by the given connect function.
+func NewPool(size int, connect func(context.Context) (*vim25.Client, error)) *Pool {
+	if size < 1 {
+		size = 1
+	}
+
+	return &Pool{
+		connect: connect,
+		size:    size,
+		notify:  make(chan struct{}),
+	}
+}
+
+// Connect returns a Pool connect function that creates a client for the given URL, logged in with the URL's Userinfo.
+// Each client is wrapped with Relogin, such that a session terminated on the server side is renewed transparently.
+func Connect(u *url.URL, insecure bool) func(context.Context) (*vim25.Client, error) {
+	return func(ctx context.Context) (*vim25.Client, error) {
+		c, err := vim25.NewClient(ctx, soap.NewClient(u, insecure))
+		if err != nil {
+			return nil, err
+		}
+
+		m := NewManager(c)
+		c.RoundTripper = Relogin(c.RoundTripper, func(ctx context.Context) error {
+			return m.Login(ctx, u.User)
+		})
+
+		if err = m.Login(ctx, u.User); err != nil {
+			return nil, err
+		}
+
+		return c, nil
+	}
+}
+
+// Get returns an idle client, creating one if the Pool has not reached its size,
+// otherwise blocking until a client is returned via Put or the context is done.
+func (p *Pool) Get(ctx context.Context) (*vim25.Client, error) {
+	for {
+		p.mu.Lock()
+
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+
+		if n := len(p.idle); n != 0 {
+			c := p.idle[n-1]
+			p.idle = p.idle[:n-1]
+			p.mu.Unlock()
+			return c, nil
+		}
+
+		if p.created < p.size {
+			p.created++
+			p.mu.Unlock()
+
+			c, err := p.connect(ctx)
+			if err != nil {
+				p.release()
+				return nil, err
+			}
+
+			return c, nil
+		}
+
+		notify := p.notify
+		p.mu.Unlock()
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// broadcast wakes any callers blocked in Get.
+// Must be called with p.mu held.
+func (p *Pool) broadcast() {
+	close(p.notify)
+	p.notify = make(chan struct{})
+}
+
+// release gives up a client slot, for example when a client could not be created.
+func (p *Pool) release() {
+	p.mu.Lock()
+	p.created--
+	p.broadcast()
+	p.mu.Unlock()
+}
+
+// Put returns a client obtained via Get to the Pool.
+// A nil client releases its slot in the Pool, for example when the client is no longer usable.
+func (p *Pool) Put(c *vim25.Client) {
+	if c == nil {
+		p.release()
+		return
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		_ = NewManager(c).Logout(context.Background())
+		return
+	}
+	p.idle = append(p.idle, c)
+	p.broadcast()
+	p.mu.Unlock()
+}
+
+// Do calls the given function with a client from the Pool, returning the client to the Pool when done.
+func (p *Pool) Do(ctx context.Context, f func(*vim25.Client) error) error {
+	c, err := p.Get(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer p.Put(c)
+
+	return f(c)
+}
+
+// Close logs out idle clients and prevents further use of the Pool.
+// Clients that are in use are logged out when returned via Put.
+func (p *Pool) Close(ctx context.Context) error {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.broadcast()
+	p.mu.Unlock()
+
+	var err error
+
+	for _, c := range idle {
+		if lerr := NewManager(c).Logout(ctx); lerr != nil {
+			err = lerr
+		}
+	}
+
+	return err
+}
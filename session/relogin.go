@@ -0,0 +1,102 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package session
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// LoginFunc authenticates a session, for example using Manager.Login, Manager.LoginByToken
+// with a token issued by the sts package, or Manager.LoginExtensionByCertificate.
+type LoginFunc func(context.Context) error
+
+type relogin struct {
+	sync.Mutex
+
+	roundTripper soap.RoundTripper
+	login        LoginFunc
+	generation   uint64
+}
+
+type reloginContext struct{}
+
+// Relogin wraps the specified soap.RoundTripper, calling the login function when a request fails
+// with a NotAuthenticated fault, such as when the session was terminated or has expired on the server side.
+// After a successful login, the failed request is retried once.
+// The login function is typically a closure using a Manager, where the Manager's client uses the
+// RoundTripper returned by Relogin. Concurrent requests that fail with NotAuthenticated result in a single login.
+func Relogin(roundTripper soap.RoundTripper, login LoginFunc) soap.RoundTripper {
+	return &relogin{
+		roundTripper: roundTripper,
+		login:        login,
+	}
+}
+
+// notAuthenticated returns true if err is a NotAuthenticated fault.
+func notAuthenticated(err error) bool {
+	if soap.IsSoapFault(err) {
+		switch soap.ToSoapFault(err).VimFault().(type) {
+		case types.NotAuthenticated, *types.NotAuthenticated:
+			return true
+		}
+	}
+	if soap.IsVimFault(err) {
+		_, ok := soap.ToVimFault(err).(*types.NotAuthenticated)
+		return ok
+	}
+	return false
+}
+
+func (r *relogin) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	generation := atomic.LoadUint64(&r.generation)
+
+	err := r.roundTripper.RoundTrip(ctx, req, res)
+	if err == nil || !notAuthenticated(err) {
+		return err
+	}
+
+	// Requests made by the login function itself, or a Logout, are not retried.
+	if ctx.Value(reloginContext{}) != nil {
+		return err
+	}
+	if _, ok := req.(*methods.LogoutBody); ok {
+		return err
+	}
+
+	r.Lock()
+	if atomic.LoadUint64(&r.generation) == generation {
+		// The session has not been renewed since this request was sent
+		if err = r.login(context.WithValue(ctx, reloginContext{}, true)); err != nil {
+			r.Unlock()
+			return err
+		}
+		atomic.AddUint64(&r.generation, 1)
+	}
+	r.Unlock()
+
+	return r.roundTripper.RoundTrip(ctx, req, res)
+}
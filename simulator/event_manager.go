@@ -21,6 +21,7 @@ import (
 	"container/ring"
 	"log"
 	"reflect"
+	"strings"
 	"text/template"
 	"time"
 
@@ -79,6 +80,7 @@ func (m *EventManager) createCollector(ctx *Context, req *types.CreateCollectorF
 	}
 	collector.Filter = req.Filter
 	collector.fillPage(size)
+	collector.reset()
 
 	return collector, nil
 }
@@ -122,6 +124,72 @@ func (m *EventManager) QueryEvents(ctx *Context, req *types.QueryEvents) soap.Ha
 	return body
 }
 
+// eventArguments returns the argument descriptions for the given event type,
+// including the Event entity arguments and the fields specific to the event type.
+func eventArguments(kind reflect.Type) []types.EventArgDesc {
+	var args []types.EventArgDesc
+
+	for i := 0; i < kind.NumField(); i++ {
+		field := kind.Field(i)
+
+		if field.Anonymous {
+			if field.Type == reflect.TypeOf(types.Event{}) {
+				for _, name := range []string{"ComputeResource", "Datacenter", "Host", "Vm", "Ds", "Net", "Dvs"} {
+					arg, _ := field.Type.FieldByName(name)
+					args = append(args, eventArgument(arg))
+				}
+			} else if field.Type.Kind() == reflect.Struct {
+				args = append(args, eventArguments(field.Type)...)
+			}
+			continue
+		}
+
+		args = append(args, eventArgument(field))
+	}
+
+	return args
+}
+
+func eventArgument(field reflect.StructField) types.EventArgDesc {
+	kind := field.Type
+	if kind.Kind() == reflect.Ptr {
+		kind = kind.Elem()
+	}
+
+	name := kind.Name()
+	switch kind.Kind() {
+	case reflect.Int32:
+		name = "int"
+	case reflect.Int64:
+		name = "long"
+	case reflect.Bool:
+		name = "boolean"
+	case reflect.Slice:
+		name = kind.Elem().Name() + "[]"
+	}
+
+	return types.EventArgDesc{
+		Name: strings.Split(field.Tag.Get("xml"), ",")[0],
+		Type: name,
+	}
+}
+
+func (m *EventManager) RetrieveArgumentDescription(req *types.RetrieveArgumentDescription) soap.HasFault {
+	body := new(methods.RetrieveArgumentDescriptionBody)
+
+	kind, ok := types.TypeFunc()(req.EventTypeId)
+	if !ok || !reflect.PtrTo(kind).Implements(reflect.TypeOf((*types.BaseEvent)(nil)).Elem()) {
+		body.Fault_ = Fault("", &types.InvalidArgument{InvalidProperty: "eventTypeId"})
+		return body
+	}
+
+	body.Res = &types.RetrieveArgumentDescriptionResponse{
+		Returnval: eventArguments(kind),
+	}
+
+	return body
+}
+
 // formatMessage applies the EventDescriptionEventDetail.FullFormat template to the given event's FullFormattedMessage field.
 func (m *EventManager) formatMessage(event types.BaseEvent) {
 	id := reflect.ValueOf(event).Elem().Type().Name()
@@ -181,6 +249,7 @@ type EventHistoryCollector struct {
 
 	m    *EventManager
 	page *ring.Ring
+	pos  int32 // scrollable view position, events with a key greater than pos are "next"
 }
 
 // doEntityEventArgument calls f for each entity argument in the event.
@@ -313,6 +382,85 @@ func (c *EventHistoryCollector) typeMatches(event types.BaseEvent, spec *types.E
 	return false
 }
 
+// timeMatches returns true if the event was created within the spec Time range.
+func (c *EventHistoryCollector) timeMatches(event types.BaseEvent, spec *types.EventFilterSpec) bool {
+	t := spec.Time
+	if t == nil {
+		return true
+	}
+
+	created := event.GetEvent().CreatedTime
+
+	if t.BeginTime != nil && created.Before(*t.BeginTime) {
+		return false
+	}
+
+	if t.EndTime != nil && created.After(*t.EndTime) {
+		return false
+	}
+
+	return true
+}
+
+// userMatches returns true if the event was logged by one of the spec UserName users, or by the system if SystemUser is set.
+func (c *EventHistoryCollector) userMatches(event types.BaseEvent, spec *types.EventFilterSpec) bool {
+	u := spec.UserName
+	if u == nil {
+		return true
+	}
+
+	name := event.GetEvent().UserName
+
+	if name == "" {
+		return u.SystemUser
+	}
+
+	for _, user := range u.UserList {
+		if user == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// category returns the category of the event, such as "info" or "error".
+func (m *EventManager) category(event types.BaseEvent) string {
+	if e, ok := event.(*types.EventEx); ok {
+		if e.Severity == "" {
+			return string(types.EventEventSeverityInfo)
+		}
+		return e.Severity
+	}
+
+	id := reflect.ValueOf(event).Elem().Type().Name()
+
+	for _, info := range m.Description.EventInfo {
+		if info.Key == id {
+			return info.Category
+		}
+	}
+
+	return ""
+}
+
+// categoryMatches returns true if the event is in one of the spec Category categories.
+func (c *EventHistoryCollector) categoryMatches(event types.BaseEvent, spec *types.EventFilterSpec) bool {
+	if len(spec.Category) == 0 {
+		return true
+	}
+
+	category := c.m.category(event)
+
+	for _, name := range spec.Category {
+		if name == category {
+			return true
+		}
+	}
+
+	return false
+}
+
 // eventMatches returns true one of the filters matches the event.
 func (c *EventHistoryCollector) eventMatches(event types.BaseEvent) bool {
 	spec := c.Filter.(types.EventFilterSpec)
@@ -321,41 +469,36 @@ func (c *EventHistoryCollector) eventMatches(event types.BaseEvent) bool {
 		return false
 	}
 
-	// TODO: spec.Time, spec.UserName, etc
+	if !c.timeMatches(event, &spec) || !c.userMatches(event, &spec) || !c.categoryMatches(event, &spec) {
+		return false
+	}
+
+	if spec.EventChainId != 0 && event.GetEvent().ChainId != spec.EventChainId {
+		return false
+	}
 
 	return c.entityMatches(event, &spec)
 }
 
 // filePage copies the manager's latest events into the collector's page with Filter applied.
 func (c *EventHistoryCollector) fillPage(size int) {
-	l := c.page.Len()
-	delta := size - l
-
-	if delta < 0 {
-		// Shrink ring size
-		c.page = c.page.Unlink(-delta)
-		return
-	}
+	// Both pages are ordered newest first, following the Next link
+	c.page = ring.New(size)
 
 	matches := 0
 	mpage := c.m.page
 	page := c.page
 
-	if delta != 0 {
-		// Grow ring size
-		c.page = c.page.Link(ring.New(delta))
-	}
-
 	for i := 0; i < maxPageSize; i++ {
 		event, ok := mpage.Value.(types.BaseEvent)
-		mpage = mpage.Prev()
+		mpage = mpage.Next()
 		if !ok {
 			continue
 		}
 
 		if c.eventMatches(event) {
 			page.Value = event
-			page = page.Prev()
+			page = page.Next()
 			matches++
 			if matches == size {
 				break
@@ -404,6 +547,109 @@ func (c *EventHistoryCollector) DestroyCollector(ctx *Context, req *types.Destro
 	}
 }
 
+// history returns the manager's events that match the collector Filter, oldest first.
+func (c *EventHistoryCollector) history() []types.BaseEvent {
+	var events []types.BaseEvent
+
+	c.m.page.Do(func(val interface{}) {
+		if event, ok := val.(types.BaseEvent); ok && c.eventMatches(event) {
+			events = append(events, event)
+		}
+	})
+
+	// reverse, as the manager's page is ordered newest first
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+
+	return events
+}
+
+// reset moves the scrollable view to the item immediately preceding the latest page.
+func (c *EventHistoryCollector) reset() {
+	page := c.GetLatestPage()
+	if len(page) == 0 {
+		c.pos = c.m.key
+		return
+	}
+
+	c.pos = page[len(page)-1].GetEvent().Key - 1
+}
+
+func (c *EventHistoryCollector) ReadNextEvents(ctx *Context, req *types.ReadNextEvents) soap.HasFault {
+	body := new(methods.ReadNextEventsBody)
+	if req.MaxCount <= 0 {
+		body.Fault_ = Fault("", &types.InvalidArgument{InvalidProperty: "maxCount"})
+		return body
+	}
+
+	body.Res = new(types.ReadNextEventsResponse)
+
+	ctx.WithLock(c.m, func() {
+		for _, event := range c.history() {
+			key := event.GetEvent().Key
+			if key <= c.pos {
+				continue
+			}
+
+			body.Res.Returnval = append(body.Res.Returnval, event)
+			c.pos = key
+
+			if len(body.Res.Returnval) == int(req.MaxCount) {
+				break
+			}
+		}
+	})
+
+	return body
+}
+
+func (c *EventHistoryCollector) ReadPreviousEvents(ctx *Context, req *types.ReadPreviousEvents) soap.HasFault {
+	body := new(methods.ReadPreviousEventsBody)
+	if req.MaxCount <= 0 {
+		body.Fault_ = Fault("", &types.InvalidArgument{InvalidProperty: "maxCount"})
+		return body
+	}
+
+	body.Res = new(types.ReadPreviousEventsResponse)
+
+	ctx.WithLock(c.m, func() {
+		events := c.history()
+
+		for i := len(events) - 1; i >= 0; i-- {
+			key := events[i].GetEvent().Key
+			if key > c.pos {
+				continue
+			}
+
+			body.Res.Returnval = append(body.Res.Returnval, events[i])
+			c.pos = key - 1
+
+			if len(body.Res.Returnval) == int(req.MaxCount) {
+				break
+			}
+		}
+	})
+
+	return body
+}
+
+func (c *EventHistoryCollector) RewindCollector(ctx *Context, req *types.RewindCollector) soap.HasFault {
+	c.pos = 0
+
+	return &methods.RewindCollectorBody{
+		Res: new(types.RewindCollectorResponse),
+	}
+}
+
+func (c *EventHistoryCollector) ResetCollector(ctx *Context, req *types.ResetCollector) soap.HasFault {
+	ctx.WithLock(c.m, c.reset)
+
+	return &methods.ResetCollectorBody{
+		Res: new(types.ResetCollectorResponse),
+	}
+}
+
 func (c *EventHistoryCollector) GetLatestPage() []types.BaseEvent {
 	var latestPage []types.BaseEvent
 
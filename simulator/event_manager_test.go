@@ -17,11 +17,25 @@ limitations under the License.
 package simulator
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/event"
+	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -115,3 +129,331 @@ func TestEventManagerVPX(t *testing.T) {
 		}
 	}
 }
+
+func TestEventManagerFilter(t *testing.T) {
+	ctx := context.Background()
+
+	m := VPX()
+
+	defer m.Remove()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service.NewServer()
+	defer s.Close()
+
+	c, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := event.NewManager(c.Client)
+	root := c.ServiceContent.RootFolder
+	vm := Map.Any("VirtualMachine").(*VirtualMachine)
+	user := s.URL.User.Username()
+	future := time.Now().Add(time.Hour)
+
+	login := []string{"UserLoginSessionEvent"}
+	created := []string{"VmCreatedEvent"}
+
+	tests := []struct {
+		obj    types.ManagedObjectReference
+		filter event.Filter
+		expect int
+	}{
+		{root, event.Filter{Type: login}, 1},
+		{root, event.Filter{Type: login, UserName: []string{user}}, 1},
+		{root, event.Filter{Type: login, UserName: []string{"enoent"}}, 0},
+		{vm.Reference(), event.Filter{Type: created, UserName: []string{"enoent"}, System: true}, 1},
+		{vm.Reference(), event.Filter{Type: created, Category: []string{"info"}}, 1},
+		{vm.Reference(), event.Filter{Type: created, Category: []string{"error"}}, 0},
+		{vm.Reference(), event.Filter{Type: created, Begin: &future}, 0},
+		{vm.Reference(), event.Filter{Type: created, End: &future}, 1},
+	}
+
+	for i, test := range tests {
+		n := 0
+		test.filter.Objects = []types.ManagedObjectReference{test.obj}
+
+		err = e.Stream(ctx, test.filter, func(_ types.ManagedObjectReference, events []types.BaseEvent) error {
+			n += len(events)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n != test.expect {
+			t.Errorf("%d: expected %d events, got %d", i, test.expect, n)
+		}
+	}
+
+	// chain ID filter
+	var chain int32
+	err = e.Stream(ctx, event.Filter{Objects: []types.ManagedObjectReference{vm.Reference()}, Type: created},
+		func(_ types.ManagedObjectReference, events []types.BaseEvent) error {
+			chain = events[0].GetEvent().ChainId
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := 0
+	err = e.Stream(ctx, event.Filter{Objects: []types.ManagedObjectReference{root}, ChainID: chain},
+		func(_ types.ManagedObjectReference, events []types.BaseEvent) error {
+			for _, event := range events {
+				if event.GetEvent().ChainId != chain {
+					t.Errorf("chain=%d", event.GetEvent().ChainId)
+				}
+			}
+			n += len(events)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n == 0 {
+		t.Error("no events with chain ID")
+	}
+}
+
+func TestEventManagerCheckpoint(t *testing.T) {
+	ctx := context.Background()
+
+	m := VPX()
+
+	defer m.Remove()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service.NewServer()
+	defer s.Close()
+
+	c, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "govmomi-events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "checkpoint.json")
+
+	e := event.NewManager(c.Client)
+	vm := object.NewVirtualMachine(c.Client, Map.Any("VirtualMachine").Reference())
+
+	stream := func() []int32 {
+		checkpoint, cerr := event.NewCheckpoint(file)
+		if cerr != nil {
+			t.Fatal(cerr)
+		}
+
+		filter := event.Filter{
+			Objects:    []types.ManagedObjectReference{vm.Reference()},
+			PageSize:   2, // smaller than the number of events, requiring multiple reads
+			Checkpoint: checkpoint,
+		}
+
+		var keys []int32
+		cerr = e.Stream(ctx, filter, func(_ types.ManagedObjectReference, events []types.BaseEvent) error {
+			for _, event := range events {
+				keys = append(keys, event.GetEvent().Key)
+			}
+			return nil
+		})
+		if cerr != nil {
+			t.Fatal(cerr)
+		}
+
+		return keys
+	}
+
+	// first run, the latest page
+	keys := stream()
+	if len(keys) != 2 {
+		t.Fatalf("keys=%v", keys)
+	}
+	last := keys[1]
+
+	// nothing new
+	keys = stream()
+	if len(keys) != 0 {
+		t.Errorf("keys=%v", keys)
+	}
+
+	// more events than PageSize
+	for i := 0; i < 2; i++ {
+		for _, op := range []func(context.Context) (*object.Task, error){vm.PowerOff, vm.PowerOn} {
+			task, terr := op(ctx)
+			if terr != nil {
+				t.Fatal(terr)
+			}
+			if terr = task.Wait(ctx); terr != nil {
+				t.Fatal(terr)
+			}
+		}
+	}
+
+	keys = stream()
+	if len(keys) < 4 {
+		t.Fatalf("keys=%v", keys)
+	}
+
+	for _, key := range keys {
+		if key <= last {
+			t.Errorf("duplicate key %d (last=%d)", key, last)
+		}
+		last = key
+	}
+
+	keys = stream()
+	if len(keys) != 0 {
+		t.Errorf("keys=%v", keys)
+	}
+}
+
+func TestEventManagerExport(t *testing.T) {
+	ctx := context.Background()
+
+	m := VPX()
+
+	defer m.Remove()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service.NewServer()
+	defer s.Close()
+
+	c, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := event.NewManager(c.Client)
+	vm := Map.Any("VirtualMachine").(*VirtualMachine)
+	filter := event.Filter{
+		Objects: []types.ManagedObjectReference{vm.Reference()},
+		Type:    []string{"VmCreatedEvent"},
+	}
+
+	check := func(r event.Record) {
+		if r.Type != "VmCreatedEvent" || r.Category != "info" || r.Message == "" {
+			t.Errorf("record=%#v", r)
+		}
+		if r.Entity != "/DC0/vm/"+vm.Name || r.Ref != vm.Reference().String() {
+			t.Errorf("entity=%s ref=%s", r.Entity, r.Ref)
+		}
+		if r.Arguments["vm"] != vm.Name {
+			t.Errorf("arguments=%#v", r.Arguments)
+		}
+	}
+
+	// JSON lines
+	var buf bytes.Buffer
+	err = event.NewExporter(e, event.NewJSONSink(&buf)).Stream(ctx, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var r event.Record
+	if err = json.NewDecoder(&buf).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+	check(r)
+
+	// syslog over TCP
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	msgs := make(chan string, 1)
+	go func() {
+		conn, lerr := l.Accept()
+		if lerr != nil {
+			return
+		}
+		defer conn.Close()
+
+		var size int
+		in := bufio.NewReader(conn)
+		if _, lerr = fmt.Fscanf(in, "%d ", &size); lerr != nil {
+			msgs <- lerr.Error()
+			return
+		}
+		msg := make([]byte, size)
+		_, _ = io.ReadFull(in, msg)
+		msgs <- string(msg)
+	}()
+
+	syslog, err := event.NewSyslog("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	syslog.Hostname = "vcsim"
+
+	err = event.NewExporter(e, syslog).Stream(ctx, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = syslog.Close()
+
+	msg := <-msgs
+	prefix := "<14>1 "
+	if !strings.HasPrefix(msg, prefix) || !strings.Contains(msg, " vcsim govmomi ") || !strings.Contains(msg, " VmCreatedEvent [event@6876 ") {
+		t.Errorf("msg=%s", msg)
+	}
+	if err = json.Unmarshal([]byte(msg[strings.Index(msg, "] ")+2:]), &r); err != nil {
+		t.Fatal(err)
+	}
+	check(r)
+
+	// webhook, failing the first request
+	requests := 0
+	h := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var records []event.Record
+		if herr := json.NewDecoder(req.Body).Decode(&records); herr != nil || len(records) != 1 {
+			t.Errorf("records=%d, error=%v", len(records), herr)
+			return
+		}
+		check(records[0])
+	}))
+	defer h.Close()
+
+	webhook := &event.Webhook{URL: h.URL, Backoff: time.Millisecond}
+	err = event.NewExporter(e, webhook).Stream(ctx, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if requests != 2 {
+		t.Errorf("requests=%d", requests)
+	}
+
+	h.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	err = event.NewExporter(e, webhook).Stream(ctx, filter)
+	if err == nil {
+		t.Error("expected error")
+	}
+}
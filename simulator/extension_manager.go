@@ -0,0 +1,145 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+type ExtensionManager struct {
+	mo.ExtensionManager
+
+	// thumbprint maps an extension key to the SHA1 thumbprint of its certificate
+	thumbprint map[string]string
+}
+
+func NewExtensionManager(ref types.ManagedObjectReference) object.Reference {
+	m := &ExtensionManager{
+		thumbprint: make(map[string]string),
+	}
+	m.Self = ref
+	return m
+}
+
+func (m *ExtensionManager) find(key string) int {
+	for i := range m.ExtensionList {
+		if m.ExtensionList[i].Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *ExtensionManager) RegisterExtension(req *types.RegisterExtension) soap.HasFault {
+	body := new(methods.RegisterExtensionBody)
+
+	if req.Extension.Key == "" || m.find(req.Extension.Key) != -1 {
+		body.Fault_ = Fault("", &types.InvalidArgument{InvalidProperty: "extension.key"})
+		return body
+	}
+
+	m.ExtensionList = append(m.ExtensionList, req.Extension)
+
+	body.Res = new(types.RegisterExtensionResponse)
+	return body
+}
+
+func (m *ExtensionManager) UpdateExtension(req *types.UpdateExtension) soap.HasFault {
+	body := new(methods.UpdateExtensionBody)
+
+	i := m.find(req.Extension.Key)
+	if i == -1 {
+		body.Fault_ = Fault("", new(types.NotFound))
+		return body
+	}
+
+	m.ExtensionList[i] = req.Extension
+
+	body.Res = new(types.UpdateExtensionResponse)
+	return body
+}
+
+func (m *ExtensionManager) UnregisterExtension(req *types.UnregisterExtension) soap.HasFault {
+	body := new(methods.UnregisterExtensionBody)
+
+	i := m.find(req.ExtensionKey)
+	if i == -1 {
+		body.Fault_ = Fault("", new(types.NotFound))
+		return body
+	}
+
+	m.ExtensionList = append(m.ExtensionList[:i], m.ExtensionList[i+1:]...)
+	delete(m.thumbprint, req.ExtensionKey)
+
+	body.Res = new(types.UnregisterExtensionResponse)
+	return body
+}
+
+func (m *ExtensionManager) FindExtension(req *types.FindExtension) soap.HasFault {
+	body := &methods.FindExtensionBody{
+		Res: new(types.FindExtensionResponse),
+	}
+
+	// Returnval is unset if the extension is not found
+	if i := m.find(req.ExtensionKey); i != -1 {
+		e := m.ExtensionList[i]
+		body.Res.Returnval = &e
+	}
+
+	return body
+}
+
+func (m *ExtensionManager) SetExtensionCertificate(ctx *Context, req *types.SetExtensionCertificate) soap.HasFault {
+	body := new(methods.SetExtensionCertificateBody)
+
+	if m.find(req.ExtensionKey) == -1 {
+		body.Fault_ = Fault("", new(types.NotFound))
+		return body
+	}
+
+	var cert *x509.Certificate
+
+	if req.CertificatePem == "" {
+		// Use the certificate of the current connection
+		if ctx.req.TLS == nil || len(ctx.req.TLS.PeerCertificates) == 0 {
+			body.Fault_ = Fault("", new(types.NoClientCertificate))
+			return body
+		}
+		cert = ctx.req.TLS.PeerCertificates[0]
+	} else {
+		block, _ := pem.Decode([]byte(req.CertificatePem))
+		if block != nil {
+			cert, _ = x509.ParseCertificate(block.Bytes)
+		}
+		if cert == nil {
+			body.Fault_ = Fault("", &types.InvalidArgument{InvalidProperty: "certificatePem"})
+			return body
+		}
+	}
+
+	m.thumbprint[req.ExtensionKey] = soap.ThumbprintSHA1(cert)
+
+	body.Res = new(types.SetExtensionCertificateResponse)
+	return body
+}
@@ -0,0 +1,118 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+func TestExtensionManager(t *testing.T) {
+	ctx := context.Background()
+
+	m := VPX()
+
+	defer m.Remove()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service.NewServer()
+	defer s.Close()
+
+	c, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	em, err := object.GetExtensionManager(c.Client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := types.Extension{
+		Key:     "com.example.govmomi",
+		Version: "1.0",
+		Description: &types.Description{
+			Label:   "govmomi",
+			Summary: "govmomi test extension",
+		},
+	}
+
+	if err = em.Register(ctx, e); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = em.Register(ctx, e); err == nil {
+		t.Error("expected error") // already registered
+	}
+
+	list, err := em.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Key != e.Key {
+		t.Errorf("list=%#v", list)
+	}
+
+	e.Version = "2.0"
+	if err = em.Update(ctx, e); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := em.Find(ctx, e.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found == nil || found.Version != e.Version {
+		t.Errorf("found=%#v", found)
+	}
+
+	found, err = em.Find(ctx, "enoent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found != nil {
+		t.Errorf("found=%#v", found)
+	}
+
+	if err = em.SetCertificate(ctx, e.Key, "invalid"); err == nil {
+		t.Error("expected error") // invalid PEM
+	}
+
+	if err = em.SetCertificate(ctx, e.Key, ""); err == nil {
+		t.Error("expected error") // no client certificate
+	}
+
+	if err = em.Unregister(ctx, e.Key); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = em.Unregister(ctx, e.Key); err == nil {
+		t.Error("expected error") // not found
+	}
+
+	if err = em.Update(ctx, e); err == nil {
+		t.Error("expected error") // not found
+	}
+}
@@ -17,7 +17,15 @@ limitations under the License.
 package simulator
 
 import (
+	"encoding/csv"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"time"
 
@@ -52,6 +60,7 @@ type PerformanceManager struct {
 	datacenterMetrics []types.PerfMetricId
 	perfCounterIndex  map[int32]types.PerfCounterInfo
 	metricData        map[string]map[int32][]int64
+	generators        map[metricKey]MetricGenerator
 }
 
 func NewPerformanceManager(ref types.ManagedObjectReference) object.Reference {
@@ -72,7 +81,9 @@ func NewPerformanceManager(ref types.ManagedObjectReference) object.Reference {
 		m.datastoreMetrics = vpx.DatastoreMetrics[:]
 		m.datacenterMetrics = vpx.DatacenterMetrics[:]
 		m.metricData = vpx.MetricData
+		m.HistoricalInterval = vpx.HistoricalInterval[:]
 	}
+	m.generators = make(map[metricKey]MetricGenerator)
 	m.perfCounterIndex = make(map[int32]types.PerfCounterInfo, len(m.PerfCounter))
 	for _, p := range m.PerfCounter {
 		m.perfCounterIndex[p.Key] = p
@@ -179,81 +190,384 @@ func (p *PerformanceManager) QueryAvailablePerfMetric(ctx *Context, req *types.Q
 	return body
 }
 
+// MetricGenerator returns the value of a metric at the given sample time.
+type MetricGenerator func(t time.Time) int64
+
+// ConstantMetric returns a MetricGenerator with the given value at any time.
+func ConstantMetric(val int64) MetricGenerator {
+	return func(time.Time) int64 {
+		return val
+	}
+}
+
+// SineMetric returns a MetricGenerator that oscillates between min and max over the given period.
+func SineMetric(min, max int64, period time.Duration) MetricGenerator {
+	mid := float64(min+max) / 2
+	amp := float64(max-min) / 2
+
+	return func(t time.Time) int64 {
+		x := 2 * math.Pi * float64(t.UnixNano()%int64(period)) / float64(period)
+		return int64(math.Round(mid + amp*math.Sin(x)))
+	}
+}
+
+// StepMetric returns a MetricGenerator with value before prior to the given time and value after at or after that time.
+func StepMetric(before int64, at time.Time, after int64) MetricGenerator {
+	return func(t time.Time) int64 {
+		if t.Before(at) {
+			return before
+		}
+		return after
+	}
+}
+
+// CSVMetric returns a MetricGenerator that plays back the records read from r,
+// where each record has an RFC3339 timestamp and an integer value.
+// The value at a given time is that of the most recent record, or 0 prior to the first record.
+func CSVMetric(r io.Reader) (MetricGenerator, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	type point struct {
+		t   time.Time
+		val int64
+	}
+
+	points := make([]point, len(records))
+
+	for i, record := range records {
+		if len(record) != 2 {
+			return nil, fmt.Errorf("record %d: expected 2 fields, got %d", i+1, len(record))
+		}
+
+		points[i].t, err = time.Parse(time.RFC3339, record[0])
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %s", i+1, err)
+		}
+
+		points[i].val, err = strconv.ParseInt(record[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %s", i+1, err)
+		}
+	}
+
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].t.Before(points[j].t)
+	})
+
+	return func(t time.Time) int64 {
+		n := sort.Search(len(points), func(i int) bool {
+			return points[i].t.After(t)
+		})
+		if n == 0 {
+			return 0
+		}
+		return points[n-1].val
+	}, nil
+}
+
+// SetMetric sets the generator for the given entity's counter, such as "cpu.usage.average",
+// overriding the values otherwise derived from the entity's state or sample data.
+// If instance is "*", the generator applies to all instances of the counter.
+// A nil generator removes a previously set generator.
+func (p *PerformanceManager) SetMetric(entity types.ManagedObjectReference, counter string, instance string, gen MetricGenerator) error {
+	key, ok := p.counterKey(counter)
+	if !ok {
+		return fmt.Errorf("counter %q not found", counter)
+	}
+
+	id := metricKey{entity, key, instance}
+
+	Map.WithLock(p, func() {
+		if gen == nil {
+			delete(p.generators, id)
+		} else {
+			p.generators[id] = gen
+		}
+	})
+
+	return nil
+}
+
+type metricKey struct {
+	entity   types.ManagedObjectReference
+	counter  int32
+	instance string
+}
+
+// counterKey returns the key of the counter with the given name.
+func (p *PerformanceManager) counterKey(name string) (int32, bool) {
+	for _, info := range p.PerfCounter {
+		if counterName(&info) == name {
+			return info.Key, true
+		}
+	}
+	return 0, false
+}
+
+func counterName(info *types.PerfCounterInfo) string {
+	return info.GroupInfo.GetElementDescription().Key + "." +
+		info.NameInfo.GetElementDescription().Key + "." + string(info.RollupType)
+}
+
+// refreshRate returns the default interval ID for the given entity type.
+func refreshRate(kind string) int32 {
+	switch kind {
+	case "VirtualMachine", "HostSystem", "ResourcePool":
+		return realtimeProviderSummary.RefreshRate
+	default:
+		return 300
+	}
+}
+
+// sampleTimes returns the sample timestamps for the given query, in ascending order and aligned to the interval.
+func (p *PerformanceManager) sampleTimes(qs *types.PerfQuerySpec, interval int32) []time.Time {
+	period := time.Duration(interval) * time.Second
+	length := time.Hour // realtime stats are kept for 1 hour
+
+	for _, h := range p.HistoricalInterval {
+		if h.SamplingPeriod == interval {
+			length = time.Duration(h.Length) * time.Second
+		}
+	}
+
+	end := time.Now()
+	if qs.EndTime != nil && qs.EndTime.Before(end) {
+		end = *qs.EndTime
+	}
+	end = end.Truncate(period)
+
+	start := end.Add(-length)
+	if qs.StartTime != nil && qs.StartTime.After(start) {
+		start = *qs.StartTime
+	}
+
+	var times []time.Time
+	for t := end; t.After(start); t = t.Add(-period) {
+		times = append(times, t)
+	}
+
+	if qs.MaxSample > 0 && len(times) > int(qs.MaxSample) {
+		times = times[:qs.MaxSample]
+	}
+
+	// reverse, as the samples are ordered oldest first
+	for i, j := 0, len(times)-1; i < j; i, j = i+1, j-1 {
+		times[i], times[j] = times[j], times[i]
+	}
+
+	return times
+}
+
+// sample returns a value from the captured sample data, with some gaussian noise to make the data look more "real".
+// The noise is seeded such that the same value is returned for the same metric and time.
+func sample(entity types.ManagedObjectReference, id types.PerfMetricId, points []int64, t time.Time, interval int32) int64 {
+	if len(points) == 0 {
+		return 0
+	}
+
+	p := points[(t.Unix()/int64(interval))%int64(len(points))]
+
+	scale := p / 5
+	if scale > 0 {
+		h := fnv.New64a()
+		fmt.Fprintf(h, "%s%d%s%d", entity, id.CounterId, id.Instance, t.Unix())
+		r := rand.New(rand.NewSource(int64(h.Sum64())))
+
+		p += int64(r.NormFloat64() * float64(scale))
+		if p < 0 {
+			p = 0
+		}
+	}
+
+	return p
+}
+
+// diskUsage returns the size in kiloBytes of the files in the given datastore directory.
+func diskUsage(dir string) int64 {
+	var size int64
+
+	_ = filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+
+	return size / 1024
+}
+
+// metricState is a snapshot of the entity state used to derive metric values,
+// taken once per PerfQuerySpec rather than for each sample.
+type metricState struct {
+	vm        bool
+	poweredOn bool
+	memKB     int64
+	numCPU    int64
+	mhz       int64
+
+	ds         bool
+	capacityKB int64
+	usedKB     int64
+}
+
+// newMetricState reads the state of the given entity, under the lock of each object read.
+// Datastore disk usage is only computed if the query includes a disk usage metric.
+func (p *PerformanceManager) newMetricState(entity mo.Reference, ids []types.PerfMetricId) *metricState {
+	s := new(metricState)
+
+	switch obj := entity.(type) {
+	case *VirtualMachine:
+		var host *types.ManagedObjectReference
+
+		Map.WithLock(obj, func() {
+			s.vm = true
+			s.poweredOn = obj.Runtime.PowerState == types.VirtualMachinePowerStatePoweredOn
+			s.memKB = int64(obj.Config.Hardware.MemoryMB) * 1024
+			s.numCPU = int64(obj.Config.Hardware.NumCPU)
+			if obj.Runtime.Host != nil {
+				ref := *obj.Runtime.Host
+				host = &ref
+			}
+		})
+
+		s.mhz = 2000
+		if host != nil {
+			if h, ok := Map.Get(*host).(*HostSystem); ok {
+				Map.WithLock(h, func() {
+					if h.Hardware != nil {
+						s.mhz = h.Hardware.CpuInfo.Hz / 1000 / 1000
+					}
+				})
+			}
+		}
+	case *Datastore:
+		var dir string
+
+		Map.WithLock(obj, func() {
+			s.ds = true
+			s.capacityKB = obj.Summary.Capacity / 1024
+			dir = obj.Info.GetDatastoreInfo().Url
+		})
+
+		for _, id := range ids {
+			info := p.perfCounterIndex[id.CounterId]
+			switch counterName(&info) {
+			case "disk.used.latest", "disk.provisioned.latest":
+				s.usedKB = diskUsage(dir)
+				return s
+			}
+		}
+	}
+
+	return s
+}
+
+// value returns the value of the given metric at time t.
+// A generator set via SetMetric takes precedence, followed by values derived from the entity's state, and finally the sample data.
+func (p *PerformanceManager) value(ref types.ManagedObjectReference, state *metricState, id types.PerfMetricId, t time.Time, interval int32) int64 {
+	for _, instance := range []string{id.Instance, "*"} {
+		if gen, ok := p.generators[metricKey{ref, id.CounterId, instance}]; ok {
+			return gen(t)
+		}
+	}
+
+	info := p.perfCounterIndex[id.CounterId]
+	name := counterName(&info)
+	points := p.metricData[ref.Type]
+
+	usage := func(counter string) int64 {
+		key, _ := p.counterKey(counter)
+		return sample(ref, types.PerfMetricId{CounterId: key, Instance: id.Instance}, points[key], t, interval)
+	}
+
+	switch {
+	case state.vm:
+		if !state.poweredOn {
+			return 0
+		}
+
+		switch name {
+		case "cpu.usagemhz.average":
+			return usage("cpu.usage.average") * state.numCPU * state.mhz / 10000
+		case "mem.active.average":
+			return usage("mem.usage.average") * state.memKB / 10000
+		case "mem.granted.average", "mem.consumed.average":
+			return state.memKB
+		}
+	case state.ds:
+		switch name {
+		case "disk.used.latest", "disk.provisioned.latest":
+			return state.usedKB
+		case "disk.capacity.latest":
+			return state.capacityKB
+		}
+	}
+
+	return sample(ref, id, points[id.CounterId], t, interval)
+}
+
 func (p *PerformanceManager) QueryPerf(ctx *Context, req *types.QueryPerf) soap.HasFault {
 	body := new(methods.QueryPerfBody)
 	body.Req = req
 	body.Res = new(types.QueryPerfResponse)
 	body.Res.Returnval = make([]types.BasePerfEntityMetricBase, len(req.QuerySpec))
 
-	for i, qs := range req.QuerySpec {
-		metrics := new(types.PerfEntityMetric)
-		metrics.Entity = qs.Entity
+	for i := range req.QuerySpec {
+		qs := &req.QuerySpec[i]
 
-		// Get metric data for this entity type
-		metricData, ok := p.metricData[qs.Entity.Type]
-		if !ok {
-			body.Fault_ = Fault("", &types.InvalidArgument{
-				InvalidProperty: "Entity",
-			})
-		}
-		var start, end time.Time
-		if qs.StartTime == nil {
-			start = time.Now().Add(time.Duration(-365*24) * time.Hour) // Assume we have data for a year
-		} else {
-			start = *qs.StartTime
-		}
-		if qs.EndTime == nil {
-			end = time.Now()
-		} else {
-			end = *qs.EndTime
+		entity := ctx.Map.Get(qs.Entity)
+		if entity == nil {
+			body.Fault_ = Fault("", &types.InvalidArgument{InvalidProperty: "entity"})
+			return body
 		}
 
-		// Generate metric series. Divide into n buckets of interval seconds
 		interval := qs.IntervalId
-		if interval == -1 || interval == 0 {
-			interval = 20 // TODO: Determine from entity type
+		if interval <= 0 {
+			interval = refreshRate(qs.Entity.Type)
+		}
+
+		if interval != realtimeProviderSummary.RefreshRate && len(p.HistoricalInterval) != 0 {
+			valid := false
+			for _, h := range p.HistoricalInterval {
+				if h.SamplingPeriod == interval {
+					valid = true
+				}
+			}
+			if !valid {
+				body.Fault_ = Fault("", &types.InvalidArgument{InvalidProperty: "intervalId"})
+				return body
+			}
 		}
-		n := 1 + int32(end.Sub(start).Seconds())/interval
-		if n > qs.MaxSample {
-			n = qs.MaxSample
+
+		times := p.sampleTimes(qs, interval)
+		state := p.newMetricState(entity, qs.MetricId)
+
+		metrics := &types.PerfEntityMetric{
+			PerfEntityMetricBase: types.PerfEntityMetricBase{Entity: qs.Entity},
+			SampleInfo:           make([]types.PerfSampleInfo, len(times)),
+			Value:                make([]types.BasePerfMetricSeries, len(qs.MetricId)),
 		}
 
-		// Loop through each interval "tick"
-		metrics.SampleInfo = make([]types.PerfSampleInfo, n)
-		metrics.Value = make([]types.BasePerfMetricSeries, len(qs.MetricId))
-		for tick := int32(0); tick < n; tick++ {
-			metrics.SampleInfo[tick] = types.PerfSampleInfo{Timestamp: end.Add(time.Duration(-interval*tick) * time.Second), Interval: interval}
+		for j, t := range times {
+			metrics.SampleInfo[j] = types.PerfSampleInfo{Timestamp: t, Interval: interval}
 		}
 
 		for j, mid := range qs.MetricId {
-			// Create list of metrics for this tick
-			series := &types.PerfMetricIntSeries{Value: make([]int64, n)}
+			series := &types.PerfMetricIntSeries{Value: make([]int64, len(times))}
 			series.Id = mid
-			points := metricData[mid.CounterId]
-			offset := int64(start.Unix()) / int64(interval)
-
-			for tick := int32(0); tick < n; tick++ {
-				var p int64
-
-				// Use sample data if we have it. Otherwise, just send 0.
-				if len(points) > 0 {
-					p = points[(offset+int64(tick))%int64(len(points))]
-					scale := p / 5
-					if scale > 0 {
-						// Add some gaussian noise to make the data look more "real"
-						p += int64(rand.NormFloat64() * float64(scale))
-						if p < 0 {
-							p = 0
-						}
-					}
-				} else {
-					p = 0
-				}
-				series.Value[tick] = p
+
+			for k, t := range times {
+				series.Value[k] = p.value(qs.Entity, state, mid, t, interval)
 			}
+
 			metrics.Value[j] = series
 		}
+
 		body.Res.Returnval[i] = metrics
 	}
+
 	return body
 }
@@ -16,15 +16,28 @@ limitations under the License.
 package simulator
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/performance"
 	"github.com/vmware/govmomi/simulator/esx"
 	"github.com/vmware/govmomi/simulator/vpx"
+	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -320,3 +333,465 @@ func TestQueryPerf(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+type perfQueryCounter struct {
+	soap.RoundTripper
+
+	queries int
+	max     int
+}
+
+func (c *perfQueryCounter) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	if q, ok := req.(*methods.QueryPerfBody); ok {
+		c.queries++
+		n := 0
+		for _, spec := range q.Req.QuerySpec {
+			n += len(spec.MetricId)
+		}
+		if n > c.max {
+			c.max = n
+		}
+	}
+	return c.RoundTripper.RoundTrip(ctx, req, res)
+}
+
+func TestPerformanceCollector(t *testing.T) {
+	ctx := context.Background()
+
+	m := VPX()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer m.Remove()
+
+	c := *m.Service.client
+	counter := &perfQueryCounter{RoundTripper: c.RoundTripper}
+	c.RoundTripper = counter
+
+	collector := performance.NewCollector(performance.NewManager(&c))
+	collector.Metrics = []string{"cpu.usage.average", "mem.consumed.average", "net.bytesRx.average"}
+	collector.Intervals = []int32{20, 300}
+
+	samples, err := collector.Collect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nentities := len(Map.All("VirtualMachine")) + len(Map.All("HostSystem"))
+	expect := nentities * len(collector.Metrics) * len(collector.Intervals)
+	if len(samples) != expect {
+		t.Errorf("expected %d samples, got %d", expect, len(samples))
+	}
+
+	// vpxd.stats.maxQueryMetrics is 64
+	if counter.queries != 1 || counter.max != expect {
+		t.Errorf("queries=%d, max=%d", counter.queries, counter.max)
+	}
+
+	units := map[string]string{
+		"cpu.usage.average":    "ratio",
+		"mem.consumed.average": "bytes",
+		"net.bytesRx.average":  "bytes_per_second",
+	}
+
+	for _, s := range samples {
+		if s.Name == "" || s.Unit != units[s.Counter] {
+			t.Errorf("sample=%#v", s)
+		}
+	}
+
+	counter.queries, counter.max = 0, 0
+	collector.MaxQueryMetrics = 4
+
+	if _, err = collector.Collect(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if counter.max != 4 || counter.queries != (expect+3)/4 {
+		t.Errorf("queries=%d, max=%d", counter.queries, counter.max)
+	}
+
+	// OpenMetrics endpoint
+	s := httptest.NewServer(collector)
+	defer s.Close()
+
+	res, err := http.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, _ := ioutil.ReadAll(res.Body)
+	_ = res.Body.Close()
+
+	if !strings.HasPrefix(res.Header.Get("Content-Type"), "application/openmetrics-text") {
+		t.Errorf("Content-Type=%s", res.Header.Get("Content-Type"))
+	}
+
+	vm := Map.Any("VirtualMachine").(*VirtualMachine)
+	label := fmt.Sprintf(`{entity="%s",type="VirtualMachine",moref="%s",instance="*",interval="20"} `, vm.Name, vm.Self.Value)
+
+	for _, expect := range []string{
+		"# TYPE vsphere_cpu_usage_average_ratio gauge\n",
+		"# UNIT vsphere_mem_consumed_average_bytes bytes\n",
+		"\nvsphere_net_bytesRx_average_bytes_per_second" + label,
+		"# EOF\n",
+	} {
+		if !strings.Contains(string(body), expect) {
+			t.Errorf("missing %q", expect)
+		}
+	}
+
+	// CSV and JSON
+	var buf bytes.Buffer
+	if err = performance.WriteCSV(&buf, samples, true); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(samples)+1 {
+		t.Errorf("records=%d", len(records))
+	}
+
+	buf.Reset()
+	if err = performance.WriteJSON(&buf, samples[:1]); err != nil {
+		t.Fatal(err)
+	}
+	var sample performance.Sample
+	if err = json.Unmarshal(buf.Bytes(), &sample); err != nil {
+		t.Fatal(err)
+	}
+	if sample.Counter != samples[0].Counter || sample.Entity != samples[0].Entity {
+		t.Errorf("sample=%#v", sample)
+	}
+
+	// Run until canceled
+	ctx, cancel := context.WithCancel(ctx)
+	collector.Period = time.Millisecond
+	rounds := 0
+	collector.Sample = func([]performance.Sample) error {
+		rounds++
+		if rounds == 3 {
+			cancel()
+		}
+		return nil
+	}
+
+	if err = collector.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if rounds != 3 {
+		t.Errorf("rounds=%d", rounds)
+	}
+}
+
+func TestPerformanceManagerGenerators(t *testing.T) {
+	ctx := context.Background()
+
+	m := VPX()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer m.Remove()
+
+	// query via SOAP, to check the encoding of zero values
+	s := m.Service.NewServer()
+	defer s.Close()
+
+	vc, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := vc.Client
+	p := performance.NewManager(c)
+	pm := Map.Get(*c.ServiceContent.PerfManager).(*PerformanceManager)
+
+	counters, err := p.CounterInfoByName(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := func(entity mo.Reference, name string, spec types.PerfQuerySpec) *types.PerfEntityMetric {
+		spec.Entity = entity.Reference()
+		spec.MetricId = []types.PerfMetricId{{CounterId: counters[name].Key}}
+		res, qerr := p.Query(ctx, []types.PerfQuerySpec{spec})
+		if qerr != nil {
+			t.Fatal(qerr)
+		}
+		return res[0].(*types.PerfEntityMetric)
+	}
+
+	values := func(metric *types.PerfEntityMetric) []int64 {
+		return metric.Value[0].(*types.PerfMetricIntSeries).Value
+	}
+
+	latest := types.PerfQuerySpec{IntervalId: 20, MaxSample: 1}
+
+	// VM metrics follow config.hardware and power state
+	vm := Map.Any("VirtualMachine").(*VirtualMachine)
+
+	granted := values(query(vm, "mem.granted.average", latest))[0]
+	if granted != int64(vm.Config.Hardware.MemoryMB)*1024 {
+		t.Errorf("mem.granted=%d", granted)
+	}
+
+	realtime := types.PerfQuerySpec{IntervalId: 20, MaxSample: 10}
+	a := values(query(vm, "cpu.usage.average", realtime))
+	b := values(query(vm, "cpu.usage.average", realtime))
+	for i := range a {
+		if a[i] != b[i] {
+			t.Errorf("%d: %d != %d", i, a[i], b[i])
+		}
+	}
+
+	task, err := object.NewVirtualMachine(c, vm.Reference()).PowerOff(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = task.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"cpu.usage.average", "cpu.usagemhz.average", "mem.usage.average", "mem.granted.average"} {
+		metric := query(vm, name, realtime)
+		vals := values(metric)
+		if len(vals) != len(metric.SampleInfo) {
+			t.Errorf("%s: %d values, %d samples", name, len(vals), len(metric.SampleInfo))
+		}
+		for _, val := range vals {
+			if val != 0 {
+				t.Errorf("%s=%d", name, val)
+			}
+		}
+	}
+
+	// Datastore space follows file sizes
+	ds := Map.Any("Datastore").(*Datastore)
+	daily := types.PerfQuerySpec{IntervalId: 300, MaxSample: 1}
+	used := values(query(ds, "disk.used.latest", daily))[0]
+
+	err = ioutil.WriteFile(filepath.Join(ds.Info.GetDatastoreInfo().Url, "metric.dat"), make([]byte, 1024*1024), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := values(query(ds, "disk.used.latest", daily))[0]; n != used+1024 {
+		t.Errorf("disk.used=%d, expected=%d", n, used+1024)
+	}
+
+	if n := values(query(ds, "disk.capacity.latest", daily))[0]; n != ds.Summary.Capacity/1024 {
+		t.Errorf("disk.capacity=%d", n)
+	}
+
+	// Historical intervals
+	start := time.Now().Add(-time.Hour)
+	metric := query(ds, "disk.used.latest", types.PerfQuerySpec{IntervalId: 300, StartTime: &start})
+	if len(metric.SampleInfo) != 12 {
+		t.Errorf("samples=%d", len(metric.SampleInfo))
+	}
+	for i, info := range metric.SampleInfo {
+		if info.Interval != 300 || info.Timestamp.Unix()%300 != 0 || !info.Timestamp.After(start) {
+			t.Errorf("%d: %#v", i, info)
+		}
+		if i > 0 && info.Timestamp.Sub(metric.SampleInfo[i-1].Timestamp) != 5*time.Minute {
+			t.Errorf("%d: %s", i, info.Timestamp)
+		}
+	}
+
+	_, err = p.Query(ctx, []types.PerfQuerySpec{{Entity: ds.Reference(), IntervalId: 600}})
+	if err == nil {
+		t.Error("expected error")
+	}
+
+	// Custom generators
+	host := Map.Any("HostSystem")
+	now := time.Now().Truncate(20 * time.Second)
+	playback := fmt.Sprintf("%s,10\n%s,20\n", now.Add(-time.Minute).Format(time.RFC3339), now.Format(time.RFC3339))
+	csvMetric, err := CSVMetric(strings.NewReader(playback))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		gen    MetricGenerator
+		expect []int64
+	}{
+		{ConstantMetric(42), []int64{42, 42, 42, 42, 42, 42}},
+		{SineMetric(0, 100, 80*time.Second), nil},
+		{StepMetric(1, now.Add(-40*time.Second), 2), []int64{1, 1, 1, 2, 2, 2}},
+		{csvMetric, []int64{0, 0, 10, 10, 10, 20}},
+	}
+
+	spec := types.PerfQuerySpec{IntervalId: 20, MaxSample: 6, EndTime: &now}
+
+	for i, test := range tests {
+		if err = pm.SetMetric(host.Reference(), "cpu.usage.average", "*", test.gen); err != nil {
+			t.Fatal(err)
+		}
+
+		vals := values(query(host, "cpu.usage.average", spec))
+
+		if test.expect == nil {
+			// sine with a period of 4 samples
+			for j := range vals {
+				if vals[j] < 0 || vals[j] > 100 || (j >= 2 && vals[j]+vals[j-2] != 100) {
+					t.Errorf("%d: %v", i, vals)
+				}
+			}
+			continue
+		}
+
+		if fmt.Sprint(vals) != fmt.Sprint(test.expect) {
+			t.Errorf("%d: %v != %v", i, vals, test.expect)
+		}
+	}
+
+	if err = pm.SetMetric(host.Reference(), "enoent", "", nil); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestPerformanceManagerAggregate(t *testing.T) {
+	ctx := context.Background()
+
+	m := ESX()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer m.Remove()
+
+	c := m.Service.client
+	p := performance.NewManager(c)
+	pm := Map.Get(*c.ServiceContent.PerfManager).(*PerformanceManager)
+
+	counters, err := p.CounterInfoByName(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host := Map.Any("HostSystem").Reference()
+	now := time.Now().Truncate(5 * time.Minute)
+
+	generators := []struct {
+		name     string
+		instance string
+		gen      MetricGenerator
+	}{
+		{"cpu.usagemhz.average", "0", ConstantMetric(100)},
+		{"cpu.usagemhz.average", "1", StepMetric(200, now.Add(-50*time.Second), 400)},
+		{"cpu.usage.average", "0", ConstantMetric(1000)},
+		{"cpu.usage.average", "1", ConstantMetric(3000)},
+		{"mem.usage.maximum", "0", ConstantMetric(10)},
+		{"mem.usage.maximum", "1", ConstantMetric(20)},
+	}
+
+	spec := types.PerfQuerySpec{Entity: host, IntervalId: 20, MaxSample: 30, EndTime: &now}
+
+	for _, g := range generators {
+		if err = pm.SetMetric(host, g.name, g.instance, g.gen); err != nil {
+			t.Fatal(err)
+		}
+		spec.MetricId = append(spec.MetricId, types.PerfMetricId{CounterId: counters[g.name].Key, Instance: g.instance})
+	}
+
+	sample, err := p.Query(ctx, []types.PerfQuerySpec{spec})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := p.ToMetricSeries(ctx, sample)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metric := result[0].Aggregate()
+
+	if len(metric.Value) != 3 {
+		t.Fatalf("series=%d", len(metric.Value))
+	}
+
+	expect := []struct {
+		name  string
+		first int64
+		last  int64
+		stat  string
+		value float64
+	}{
+		{"cpu.usagemhz.average", 300, 500, "p95", 500},
+		{"cpu.usage.average", 2000, 2000, "", 2000},
+		{"mem.usage.maximum", 20, 20, "max", 20},
+	}
+
+	for i, e := range expect {
+		s := metric.Value[i]
+		if s.Name != e.name || s.Instance != "" {
+			t.Fatalf("%d: %s/%s", i, s.Name, s.Instance)
+		}
+		if s.Value[0] != e.first || s.Value[len(s.Value)-1] != e.last {
+			t.Errorf("%s: %v", s.Name, s.Value)
+		}
+
+		val, serr := s.Stat(e.stat)
+		if serr != nil {
+			t.Fatal(serr)
+		}
+		if val != e.value {
+			t.Errorf("%s: %s=%f", s.Name, e.stat, val)
+		}
+	}
+
+	// 27 samples before the step, 3 after
+	avg, _ := metric.Value[0].Stat("avg")
+	if avg != 320 {
+		t.Errorf("avg=%f", avg)
+	}
+
+	if _, err = metric.Value[0].Stat("p0"); err == nil {
+		t.Error("expected error")
+	}
+
+	// 30 realtime samples roll up into 2 or 3 5-minute samples, depending on alignment
+	rollup := metric.Rollup(300)
+	for i, info := range rollup.SampleInfo {
+		if info.Interval != 300 || info.Timestamp.Unix()%300 != 0 {
+			t.Errorf("%d: %#v", i, info)
+		}
+	}
+	last := len(rollup.SampleInfo) - 1
+	if !rollup.SampleInfo[last].Timestamp.Equal(now) {
+		t.Errorf("%s != %s", rollup.SampleInfo[last].Timestamp, now)
+	}
+	// 12 samples of 300 and 3 of 500 in the last 5 minutes
+	if v := rollup.Value[0].Value[last]; v != 340 {
+		t.Errorf("rollup=%d", v)
+	}
+
+	vals, unit := metric.Value[1].Normalize()
+	if unit != "ratio" || vals[0] != 0.2 {
+		t.Errorf("%v %s", vals, unit)
+	}
+
+	// An instance with fewer samples is skipped once its series ends
+	for i := range result[0].Value {
+		s := &result[0].Value[i]
+		if s.Name == "cpu.usage.average" && s.Instance == "1" {
+			s.Value = s.Value[:10]
+		}
+	}
+
+	short := result[0].Aggregate().Value[1]
+	if short.Value[9] != 2000 || short.Value[10] != 1000 || short.Value[len(short.Value)-1] != 1000 {
+		t.Errorf("%s: %v", short.Name, short.Value)
+	}
+}
@@ -26,6 +26,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/mo"
@@ -40,6 +42,16 @@ type PropertyCollector struct {
 	updates []types.ObjectUpdate
 	mu      sync.Mutex
 	cancel  context.CancelFunc
+	pending map[string]*retrievePage
+
+	truncated []types.PropertyFilterUpdate
+}
+
+// retrievePage holds the remaining objects of a RetrievePropertiesEx result,
+// to be returned by ContinueRetrievePropertiesEx.
+type retrievePage struct {
+	objects []types.ObjectContent
+	max     int
 }
 
 func NewPropertyCollector(ref types.ManagedObjectReference) object.Reference {
@@ -297,6 +309,10 @@ func (rr *retrieveResult) collectFields(ctx *Context, rval reflect.Value, fields
 	}
 
 	for _, name := range fields {
+		if name == "" {
+			continue // an empty path selects no property
+		}
+
 		if seen[name] {
 			// rvc 'ls' includes the "name" property twice, then fails with no error message or stack trace
 			// in RbVmomi::VIM::ObjectContent.to_hash_uncached when it sees the 2nd "name" property.
@@ -476,6 +492,7 @@ func (pc *PropertyCollector) CreatePropertyCollector(ctx *Context, c *types.Crea
 
 func (pc *PropertyCollector) DestroyPropertyCollector(ctx *Context, c *types.DestroyPropertyCollector) soap.HasFault {
 	pc.CancelWaitForUpdates(&types.CancelWaitForUpdates{This: c.This})
+	pc.dropPending()
 
 	body := &methods.DestroyPropertyCollectorBody{}
 
@@ -513,14 +530,98 @@ func (pc *PropertyCollector) RetrievePropertiesEx(ctx *Context, r *types.Retriev
 			objects = append(objects, o)
 		}
 		res.Objects = objects
-		body.Res = &types.RetrievePropertiesExResponse{
-			Returnval: res,
+
+		if max := int(r.Options.MaxObjects); max > 0 {
+			res = pc.page(&retrievePage{objects: res.Objects, max: max})
+		}
+
+		if len(res.Objects) != 0 {
+			body.Res = &types.RetrievePropertiesExResponse{
+				Returnval: res,
+			}
+		} else {
+			body.Res = new(types.RetrievePropertiesExResponse)
 		}
 	}
 
 	return body
 }
 
+// page returns the next page of objects, saving any remainder to be returned by ContinueRetrievePropertiesEx.
+func (pc *PropertyCollector) page(p *retrievePage) *types.RetrieveResult {
+	res := &types.RetrieveResult{
+		Objects: p.objects,
+	}
+
+	if len(p.objects) <= p.max {
+		return res
+	}
+
+	res.Objects = p.objects[:p.max]
+	p.objects = p.objects[p.max:]
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	if pc.pending == nil {
+		pc.pending = make(map[string]*retrievePage)
+	}
+
+	res.Token = uuid.New().String()
+	pc.pending[res.Token] = p
+
+	return res
+}
+
+// next removes and returns the pending result for the given token, if any.
+func (pc *PropertyCollector) next(token string) *retrievePage {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+
+	p, ok := pc.pending[token]
+	if ok {
+		delete(pc.pending, token)
+	}
+
+	return p
+}
+
+// dropPending removes all pending results, such as when the session ends or the collector is destroyed.
+func (pc *PropertyCollector) dropPending() {
+	pc.mu.Lock()
+	pc.pending = nil
+	pc.mu.Unlock()
+}
+
+func (pc *PropertyCollector) ContinueRetrievePropertiesEx(ctx *Context, r *types.ContinueRetrievePropertiesEx) soap.HasFault {
+	body := &methods.ContinueRetrievePropertiesExBody{}
+
+	p := pc.next(r.Token)
+	if p == nil {
+		body.Fault_ = Fault("", &types.InvalidArgument{InvalidProperty: "token"})
+		return body
+	}
+
+	body.Res = &types.ContinueRetrievePropertiesExResponse{
+		Returnval: *pc.page(p),
+	}
+
+	return body
+}
+
+func (pc *PropertyCollector) CancelRetrievePropertiesEx(ctx *Context, r *types.CancelRetrievePropertiesEx) soap.HasFault {
+	body := &methods.CancelRetrievePropertiesExBody{}
+
+	if pc.next(r.Token) == nil {
+		body.Fault_ = Fault("", &types.InvalidArgument{InvalidProperty: "token"})
+		return body
+	}
+
+	body.Res = new(types.CancelRetrievePropertiesExResponse)
+
+	return body
+}
+
 // RetrieveProperties is deprecated, but govmomi is still using it at the moment.
 func (pc *PropertyCollector) RetrieveProperties(ctx *Context, r *types.RetrieveProperties) soap.HasFault {
 	body := &methods.RetrievePropertiesBody{}
@@ -533,8 +634,9 @@ func (pc *PropertyCollector) RetrieveProperties(ctx *Context, r *types.RetrieveP
 	if res.Fault() != nil {
 		body.Fault_ = res.Fault()
 	} else {
-		body.Res = &types.RetrievePropertiesResponse{
-			Returnval: res.(*methods.RetrievePropertiesExBody).Res.Returnval.Objects,
+		body.Res = new(types.RetrievePropertiesResponse)
+		if rval := res.(*methods.RetrievePropertiesExBody).Res.Returnval; rval != nil {
+			body.Res.Returnval = rval.Objects
 		}
 	}
 
@@ -637,8 +739,15 @@ func (pc *PropertyCollector) WaitForUpdatesEx(ctx *Context, r *types.WaitForUpda
 			}
 		}
 	}
+	var max int32
+	if r.Options != nil && r.Options.MaxObjectUpdates > 0 {
+		max = r.Options.MaxObjectUpdates
+	}
+
 	pc.mu.Lock()
 	pc.cancel = cancel
+	pending := pc.truncated
+	pc.truncated = nil
 	pc.mu.Unlock()
 
 	body := &methods.WaitForUpdatesExBody{}
@@ -651,6 +760,13 @@ func (pc *PropertyCollector) WaitForUpdatesEx(ctx *Context, r *types.WaitForUpda
 		Returnval: set,
 	}
 
+	if len(pending) != 0 && r.Version != "" {
+		// Return the remainder of a truncated UpdateSet
+		set.FilterSet = pending
+		pc.truncate(set, max)
+		return body
+	}
+
 	apply := func() bool {
 		if fault := pc.apply(ctx, set); fault != nil {
 			body.Fault_ = Fault("", fault)
@@ -664,6 +780,9 @@ func (pc *PropertyCollector) WaitForUpdatesEx(ctx *Context, r *types.WaitForUpda
 		apply()                // Collect current state
 		set.Version = "-"      // Next request with Version set will wait via loop below
 		ctx.Map.AddHandler(pc) // Listen for create, update, delete of managed objects
+		if body.Res != nil {
+			pc.truncate(set, max)
+		}
 		return body
 	}
 
@@ -688,6 +807,13 @@ func (pc *PropertyCollector) WaitForUpdatesEx(ctx *Context, r *types.WaitForUpda
 
 			return body
 		case <-ticker.C:
+			if !ctx.validSession() {
+				// The session was terminated while waiting
+				body.Fault_ = Fault("", new(types.NotAuthenticated))
+				body.Res = nil
+				return body
+			}
+
 			pc.mu.Lock()
 			updates := pc.updates
 			pc.updates = nil // clear updates collected by the managed object CRUD listeners
@@ -739,6 +865,7 @@ func (pc *PropertyCollector) WaitForUpdatesEx(ctx *Context, r *types.WaitForUpda
 				}
 			}
 			if len(set.FilterSet) != 0 {
+				pc.truncate(set, max)
 				return body
 			}
 			if oneUpdate == true {
@@ -749,6 +876,47 @@ func (pc *PropertyCollector) WaitForUpdatesEx(ctx *Context, r *types.WaitForUpda
 	}
 }
 
+// truncate limits the given UpdateSet to max ObjectUpdates, if max is > 0.
+// The remainder is saved to be returned by the next call to WaitForUpdatesEx.
+func (pc *PropertyCollector) truncate(set *types.UpdateSet, max int32) {
+	if max <= 0 {
+		return
+	}
+
+	var pending []types.PropertyFilterUpdate
+	n := int(max)
+
+	for i, fu := range set.FilterSet {
+		if n >= len(fu.ObjectSet) {
+			n -= len(fu.ObjectSet)
+			continue
+		}
+
+		if n != 0 {
+			pending = append(pending, types.PropertyFilterUpdate{
+				Filter:    fu.Filter,
+				ObjectSet: fu.ObjectSet[n:],
+			})
+			set.FilterSet[i].ObjectSet = fu.ObjectSet[:n]
+			i++
+		}
+
+		pending = append(pending, set.FilterSet[i:]...)
+		set.FilterSet = set.FilterSet[:i]
+		break
+	}
+
+	if len(pending) == 0 {
+		return
+	}
+
+	set.Truncated = types.NewBool(true)
+
+	pc.mu.Lock()
+	pc.truncated = pending
+	pc.mu.Unlock()
+}
+
 // WaitForUpdates is deprecated, but pyvmomi is still using it at the moment.
 func (pc *PropertyCollector) WaitForUpdates(ctx *Context, r *types.WaitForUpdates) soap.HasFault {
 	body := &methods.WaitForUpdatesBody{}
@@ -18,6 +18,7 @@ package simulator
 
 import (
 	"context"
+	"errors"
 	"log"
 	"reflect"
 	"sync"
@@ -1463,3 +1464,273 @@ func TestPropertyCollectorSession(t *testing.T) { // aka issue-923
 		}
 	}
 }
+
+func TestPropertyCollectorPaging(t *testing.T) {
+	ctx := context.Background()
+
+	m := VPX()
+
+	defer m.Remove()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service.NewServer()
+	defer s.Close()
+
+	c, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := m.Count()
+	kind := []string{"VirtualMachine"}
+
+	v, err := view.NewManager(c.Client).CreateContainerView(ctx, c.ServiceContent.RootFolder, kind, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var vms []mo.VirtualMachine
+	err = v.WithRetrieveOptions(property.RetrieveOptions{MaxObjects: 1}).Retrieve(ctx, kind, []string{"name"}, &vms)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vms) != count.Machine {
+		t.Errorf("len(vms)=%d", len(vms))
+	}
+
+	var refs []types.ManagedObjectReference
+	for _, vm := range vms {
+		refs = append(refs, vm.Self)
+	}
+	name := vms[0].Name
+
+	pages := 0
+	objects := 0
+
+	pc := property.DefaultCollector(c.Client).WithRetrieveOptions(property.RetrieveOptions{
+		MaxObjects: 3,
+		Page: func(content []types.ObjectContent) error {
+			pages++
+			objects += len(content)
+			return nil
+		},
+	})
+
+	vms = nil
+	err = pc.Retrieve(ctx, refs, []string{"name"}, &vms)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vms) != 0 {
+		t.Errorf("len(vms)=%d", len(vms)) // results are passed to Page rather than dst
+	}
+
+	if objects != count.Machine || pages != (count.Machine+2)/3 {
+		t.Errorf("objects=%d, pages=%d", objects, pages)
+	}
+
+	err = pc.RetrieveWithFilter(ctx, refs, []string{"name"}, &vms, property.Filter{"name": name})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pages != (count.Machine+2)/3+1 || objects != count.Machine+1 {
+		t.Errorf("objects=%d, pages=%d", objects, pages) // filter matching is not passed to Page
+	}
+
+	// Page callback errors cancel the pending result
+	stop := pc.WithRetrieveOptions(property.RetrieveOptions{
+		MaxObjects: 1,
+		Page: func([]types.ObjectContent) error {
+			return errors.New("stop")
+		},
+	})
+
+	err = stop.Retrieve(ctx, refs, nil, &vms)
+	if err == nil || err.Error() != "stop" {
+		t.Errorf("err=%v", err)
+	}
+
+	// the root PropertyCollector is session-ized, see Session.Get
+	var session *PropertyCollector
+	for _, s := range Map.SessionManager().sessions {
+		session = s.Registry.Get(pc.Reference()).(*PropertyCollector)
+	}
+
+	pending := func() int {
+		session.mu.Lock()
+		defer session.mu.Unlock()
+		return len(session.pending)
+	}
+
+	if n := pending(); n != 0 {
+		t.Errorf("pending=%d", n)
+	}
+
+	req := types.RetrievePropertiesEx{
+		SpecSet: []types.PropertyFilterSpec{{
+			ObjectSet: []types.ObjectSpec{{Obj: refs[0]}, {Obj: refs[1]}},
+			PropSet:   []types.PropertySpec{{Type: "VirtualMachine", PathSet: []string{"name"}}},
+		}},
+		Options: types.RetrieveOptions{MaxObjects: 1},
+	}
+
+	res, err := pc.RetrievePropertiesEx(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Objects) != 1 || res.Token == "" {
+		t.Fatalf("res=%#v", res)
+	}
+
+	if err = pc.CancelRetrievePropertiesEx(ctx, res.Token); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = pc.ContinueRetrievePropertiesEx(ctx, res.Token); err == nil {
+		t.Error("expected error") // token was canceled
+	}
+
+	if err = pc.CancelRetrievePropertiesEx(ctx, "enoent"); err == nil {
+		t.Error("expected error")
+	}
+
+	// pending results are dropped when the collector is destroyed
+	cpc, err := pc.Create(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = cpc.RetrievePropertiesEx(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+
+	var created *PropertyCollector
+	for _, s := range Map.SessionManager().sessions {
+		created = s.Registry.Get(cpc.Reference()).(*PropertyCollector)
+	}
+
+	if err = cpc.Destroy(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(created.pending); n != 0 {
+		t.Errorf("pending=%d", n)
+	}
+
+	// and when the session ends
+	if _, err = pc.RetrievePropertiesEx(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := pending(); n != 1 {
+		t.Errorf("pending=%d", n)
+	}
+
+	if err = c.Logout(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := pending(); n != 0 {
+		t.Errorf("pending=%d", n)
+	}
+}
+
+func TestPropertyCollectorMixedTypes(t *testing.T) {
+	ctx := context.Background()
+
+	m := VPX()
+
+	defer m.Remove()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service.NewServer()
+	defer s.Close()
+
+	c, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vm := Map.Any("VirtualMachine").(*VirtualMachine)
+	refs := append([]types.ManagedObjectReference{vm.Self, *vm.Runtime.Host}, vm.Datastore...)
+
+	pc := property.DefaultCollector(c.Client)
+
+	var objs []interface{}
+
+	err = pc.Retrieve(ctx, refs, []string{"name"}, &objs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(objs) != len(refs) {
+		t.Fatalf("len(objs)=%d", len(objs))
+	}
+
+	if objs[0].(mo.VirtualMachine).Name != vm.Name {
+		t.Errorf("objs[0]=%#v", objs[0])
+	}
+
+	var vms []mo.VirtualMachine
+	var hosts []mo.HostSystem
+	var datastores []mo.Datastore
+
+	dst := map[string]interface{}{
+		"VirtualMachine": &vms,
+		"HostSystem":     &hosts,
+		"Datastore":      &datastores,
+	}
+
+	ps := map[string][]string{
+		"VirtualMachine": {"runtime.powerState"},
+		"HostSystem":     {"name", "summary.runtime"},
+		// all Datastore properties
+	}
+
+	err = pc.RetrieveByType(ctx, refs, ps, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vms) != 1 || vms[0].Name != "" || vms[0].Runtime.PowerState != vm.Runtime.PowerState {
+		t.Errorf("vms=%#v", vms)
+	}
+
+	if len(hosts) != 1 || hosts[0].Name == "" || hosts[0].Summary.Runtime == nil {
+		t.Errorf("hosts=%#v", hosts)
+	}
+
+	if len(datastores) != len(vm.Datastore) || datastores[0].Summary.Name == "" {
+		t.Errorf("datastores=%#v", datastores)
+	}
+
+	// properties must be valid for all types
+	err = pc.Retrieve(ctx, refs, []string{"runtime.powerState"}, &objs)
+	if err == nil {
+		t.Error("expected error")
+	}
+
+	// object.Common.Properties supports the same dst types
+	vms = nil
+	obj := object.NewVirtualMachine(c.Client, vm.Self)
+	err = obj.Properties(ctx, vm.Self, []string{"name"}, map[string]interface{}{"VirtualMachine": &vms})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vms) != 1 || vms[0].Name != vm.Name {
+		t.Errorf("vms=%#v", vms)
+	}
+}
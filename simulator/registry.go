@@ -90,6 +90,17 @@ func (r *Registry) typeFunc(name string) (reflect.Type, bool) {
 	return defaultMapType(name)
 }
 
+// xsiTypeName returns the namespace prefixed name of typ, if registered with the Registry's Namespace.
+func (r *Registry) xsiTypeName(typ reflect.Type) (string, bool) {
+	if r.Namespace != "" && r.Namespace != vim25.Namespace {
+		name := r.Namespace + ":" + typ.Name()
+		if kind, ok := defaultMapType(name); ok && kind == typ {
+			return name, true
+		}
+	}
+	return "", false
+}
+
 // typeName returns the type of the given object.
 func typeName(item mo.Reference) string {
 	return reflect.TypeOf(item).Elem().Name()
@@ -177,6 +188,21 @@ func (r *Registry) Any(kind string) mo.Entity {
 	return nil
 }
 
+// All returns all entities of type specified by kind.
+func (r *Registry) All(kind string) []mo.Entity {
+	r.m.Lock()
+	defer r.m.Unlock()
+
+	var entities []mo.Entity
+	for ref, val := range r.objects {
+		if ref.Type == kind {
+			entities = append(entities, val.(mo.Entity))
+		}
+	}
+
+	return entities
+}
+
 // applyHandlers calls the given func for each r.handlers
 func (r *Registry) applyHandlers(f func(o RegisterObject)) {
 	r.m.Lock()
@@ -75,6 +75,10 @@ func NewServiceInstance(content types.ServiceContent, folder mo.Folder) *Service
 		objects = append(objects, NewCustomFieldsManager(*s.Content.CustomFieldsManager))
 	}
 
+	if s.Content.ExtensionManager != nil {
+		objects = append(objects, NewExtensionManager(*s.Content.ExtensionManager))
+	}
+
 	if s.Content.IpPoolManager != nil {
 		objects = append(objects, NewIpPoolManager(*s.Content.IpPoolManager))
 	}
@@ -88,6 +88,25 @@ func (s *SessionManager) Login(ctx *Context, req *types.Login) soap.HasFault {
 	return body
 }
 
+// extensionCertificate returns true if the client certificate matches the certificate of the registered extension key.
+func extensionCertificate(ctx *Context, key string) bool {
+	ref := Map.content().ExtensionManager
+	if ref == nil {
+		return false // ESX
+	}
+
+	m := Map.Get(*ref).(*ExtensionManager)
+
+	var thumbprint string
+	var ok bool
+
+	Map.WithLock(m, func() {
+		thumbprint, ok = m.thumbprint[key]
+	})
+
+	return ok && thumbprint == soap.ThumbprintSHA1(ctx.req.TLS.PeerCertificates[0])
+}
+
 func (s *SessionManager) LoginExtensionByCertificate(ctx *Context, req *types.LoginExtensionByCertificate) soap.HasFault {
 	body := new(methods.LoginExtensionByCertificateBody)
 
@@ -96,7 +115,7 @@ func (s *SessionManager) LoginExtensionByCertificate(ctx *Context, req *types.Lo
 		return body
 	}
 
-	if req.ExtensionKey == "" || ctx.Session != nil {
+	if req.ExtensionKey == "" || ctx.Session != nil || !extensionCertificate(ctx, req.ExtensionKey) {
 		body.Fault_ = invalidLogin
 	} else {
 		body.Res = &types.LoginExtensionByCertificateResponse{
@@ -114,7 +133,10 @@ func (s *SessionManager) LoginByToken(ctx *Context, req *types.LoginByToken) soa
 		body.Fault_ = invalidLogin
 	} else {
 		var subject struct {
-			ID string `xml:"Assertion>Subject>NameID"`
+			ID         string `xml:"Assertion>Subject>NameID"`
+			Conditions struct {
+				NotOnOrAfter string `xml:",attr"`
+			} `xml:"Assertion>Conditions"`
 		}
 
 		if s, ok := ctx.Header.Security.(*Element); ok {
@@ -126,6 +148,11 @@ func (s *SessionManager) LoginByToken(ctx *Context, req *types.LoginByToken) soa
 			return body
 		}
 
+		if expires, err := time.Parse(time.RFC3339, subject.Conditions.NotOnOrAfter); err == nil && time.Now().After(expires) {
+			body.Fault_ = invalidLogin // token has expired
+			return body
+		}
+
 		body.Res = &types.LoginByTokenResponse{
 			Returnval: createSession(ctx, subject.ID, req.Locale),
 		}
@@ -134,12 +161,19 @@ func (s *SessionManager) LoginByToken(ctx *Context, req *types.LoginByToken) soa
 	return body
 }
 
-func (s *SessionManager) Logout(ctx *Context, _ *types.Logout) soap.HasFault {
-	session := ctx.Session
+// removeSession removes the given session, along with the session's views, RegisterObject handlers
+// and any RetrievePropertiesEx results pending in the session's PropertyCollectors.
+func (s *SessionManager) removeSession(ctx *Context, session Session) {
 	delete(s.sessions, session.Key)
 	pc := Map.content().PropertyCollector
 
-	for ref, obj := range ctx.Session.Registry.objects {
+	for ref, obj := range session.Registry.objects {
+		switch obj := obj.(type) {
+		case *PropertyCollector:
+			obj.dropPending()
+		case *ContainerView, *ListView:
+			destroyView(ref)
+		}
 		if ref == pc {
 			continue // don't unregister the PropertyCollector singleton
 		}
@@ -147,6 +181,11 @@ func (s *SessionManager) Logout(ctx *Context, _ *types.Logout) soap.HasFault {
 			ctx.Map.Remove(ref) // Remove RegisterObject handlers
 		}
 	}
+}
+
+func (s *SessionManager) Logout(ctx *Context, _ *types.Logout) soap.HasFault {
+	session := ctx.Session
+	s.removeSession(ctx, *session)
 
 	ctx.postEvent(&types.UserLogoutSessionEvent{
 		IpAddress: session.IpAddress,
@@ -166,7 +205,9 @@ func (s *SessionManager) TerminateSession(ctx *Context, req *types.TerminateSess
 			body.Fault_ = Fault("", new(types.InvalidArgument))
 			return body
 		}
-		delete(s.sessions, id)
+		if session, ok := s.sessions[id]; ok {
+			s.removeSession(ctx, session)
+		}
 	}
 
 	body.Res = new(types.TerminateSessionResponse)
@@ -276,6 +317,17 @@ func (c *Context) SetSession(session Session, login bool) {
 	}
 }
 
+// validSession returns false if the Context Session has since been removed via Logout or TerminateSession.
+func (c *Context) validSession() bool {
+	if c.Session == nil || c.m == nil {
+		return true
+	}
+
+	ok := false
+	c.WithLock(c.m, func() { _, ok = c.m.sessions[c.Session.Key] })
+	return ok
+}
+
 // WithLock holds a lock for the given object while then given function is run.
 func (c *Context) WithLock(obj mo.Reference, f func()) {
 	if c.Caller != nil && *c.Caller == obj.Reference() {
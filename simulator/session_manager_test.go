@@ -19,15 +19,20 @@ package simulator
 import (
 	"context"
 	"crypto/tls"
+	"encoding/pem"
+	"errors"
 	"log"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/property"
 	"github.com/vmware/govmomi/session"
 	"github.com/vmware/govmomi/simulator/vpx"
+	"github.com/vmware/govmomi/vim25"
 	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/soap"
@@ -202,7 +207,19 @@ func TestSessionManagerLoginExtension(t *testing.T) {
 		t.Fatal(terr)
 	}
 
-	u := ts.URL.User
+	admin, err := govmomi.NewClient(ctx, ts.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := "com.example.govmomi"
+	m := object.NewExtensionManager(admin.Client)
+
+	err = m.Register(ctx, types.Extension{Key: key, Description: new(types.Description)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	ts.URL.User = nil // skip Login()
 
 	c, err := govmomi.NewClient(ctx, ts.URL, true)
@@ -210,13 +227,28 @@ func TestSessionManagerLoginExtension(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	err = session.NewManager(c.Client).LoginExtensionByCertificate(ctx, u.Username())
+	err = session.NewManager(c.Client).LoginExtensionByCertificate(ctx, key)
 	if err == nil {
 		t.Error("expected error") // client cert not set
 	}
 
-	c.SetCertificate(ts.TLS.Certificates[0]) // any cert is ok with vcsim
-	err = session.NewManager(c.Client).LoginExtensionByCertificate(ctx, u.Username())
+	c.SetCertificate(ts.TLS.Certificates[0])
+	err = session.NewManager(c.Client).LoginExtensionByCertificate(ctx, key)
+	if err == nil {
+		t.Error("expected error") // extension certificate not set
+	}
+
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.TLS.Certificates[0].Certificate[0]})
+	if err = m.SetCertificate(ctx, key, string(cert)); err != nil {
+		t.Fatal(err)
+	}
+
+	err = session.NewManager(c.Client).LoginExtensionByCertificate(ctx, "enoent")
+	if err == nil {
+		t.Error("expected error") // extension not registered
+	}
+
+	err = session.NewManager(c.Client).LoginExtensionByCertificate(ctx, key)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -325,3 +357,172 @@ func TestSessionManagerPropertyCollector(t *testing.T) {
 		t.Errorf("kind=%s", set.Kind)
 	}
 }
+
+func TestSessionManagerRelogin(t *testing.T) {
+	ctx := context.Background()
+
+	m := VPX()
+	defer m.Remove()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service.NewServer()
+	defer s.Close()
+
+	admin, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := session.Connect(s.URL, true)(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sm := session.NewManager(c)
+	us, err := sm.UserSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = session.NewManager(admin.Client).TerminateSession(ctx, []string{us.Key})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without Relogin
+	_, err = methods.GetCurrentTime(ctx, c.Client)
+	if !isNotAuthenticated(err) {
+		t.Fatalf("err=%v", err)
+	}
+
+	// with Relogin, in parallel
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := methods.GetCurrentTime(ctx, c); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	nus, err := sm.UserSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nus == nil || nus.Key == us.Key {
+		t.Errorf("session=%#v", nus)
+	}
+
+	sessions := Map.SessionManager().sessions
+	if len(sessions) != 2 {
+		t.Errorf("%d sessions", len(sessions)) // admin + 1 relogin
+	}
+
+	// login failure is returned
+	c.RoundTripper = session.Relogin(c.Client, func(context.Context) error {
+		return errors.New("login failed")
+	})
+
+	err = session.NewManager(admin.Client).TerminateSession(ctx, []string{nus.Key})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = methods.GetCurrentTime(ctx, c)
+	if err == nil || err.Error() != "login failed" {
+		t.Errorf("err=%v", err)
+	}
+}
+
+func TestSessionPool(t *testing.T) {
+	ctx := context.Background()
+
+	m := VPX()
+	defer m.Remove()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service.NewServer()
+	defer s.Close()
+
+	size := 3
+	pool := session.NewPool(size, session.Connect(s.URL, true))
+
+	keys := make(chan string, size*4)
+	var wg sync.WaitGroup
+
+	for i := 0; i < size*4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := pool.Do(ctx, func(c *vim25.Client) error {
+				us, err := session.NewManager(c).UserSession(ctx)
+				if err == nil {
+					keys <- us.Key
+				}
+				return err
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(keys)
+
+	unique := make(map[string]bool)
+	for key := range keys {
+		unique[key] = true
+	}
+
+	if len(unique) == 0 || len(unique) > size {
+		t.Errorf("%d sessions", len(unique))
+	}
+
+	// Get blocks when all clients are in use
+	var clients []*vim25.Client
+	for i := 0; i < size; i++ {
+		c, err := pool.Get(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clients = append(clients, c)
+	}
+
+	tctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err = pool.Get(tctx); err != context.DeadlineExceeded {
+		t.Errorf("err=%v", err)
+	}
+
+	for _, c := range clients[1:] {
+		pool.Put(c)
+	}
+
+	if err = pool.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = pool.Get(ctx); err != session.ErrPoolClosed {
+		t.Errorf("err=%v", err)
+	}
+
+	pool.Put(clients[0]) // logged out after Close
+
+	if n := len(Map.SessionManager().sessions); n != 0 {
+		t.Errorf("%d sessions", n)
+	}
+}
@@ -122,7 +122,7 @@ func (s *Service) call(ctx *Context, method *Method) soap.HasFault {
 
 	if session == nil {
 		switch method.Name {
-		case "RetrieveServiceContent", "List", "Login", "LoginByToken", "LoginExtensionByCertificate", "RetrieveProperties", "RetrievePropertiesEx", "CloneSession":
+		case "RetrieveServiceContent", "SsoAdminServiceInstance", "SsoGroupcheckServiceInstance", "List", "Login", "LoginByToken", "LoginExtensionByCertificate", "RetrieveProperties", "RetrievePropertiesEx", "CloneSession":
 			// ok for now, TODO: authz
 		default:
 			fault := &types.NotAuthenticated{
@@ -363,6 +363,7 @@ func (s *Service) ServeSDK(w http.ResponseWriter, r *http.Request) {
 
 	fmt.Fprint(&out, xml.Header)
 	e := xml.NewEncoder(&out)
+	e.TypeName = ctx.Map.xsiTypeName
 	err = e.Encode(&soapEnvelope{
 		Enc:  "http://schemas.xmlsoap.org/soap/encoding/",
 		Env:  "http://schemas.xmlsoap.org/soap/envelope/",
@@ -17,9 +17,15 @@ limitations under the License.
 package simulator
 
 import (
+	"context"
+	"sync"
 	"testing"
 
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/task"
 	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/progress"
 	"github.com/vmware/govmomi/vim25/types"
 )
 
@@ -63,3 +69,200 @@ func TestNewTask(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestTaskWaiter(t *testing.T) {
+	ctx := context.Background()
+
+	m := VPX()
+
+	defer m.Remove()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service.NewServer()
+	defer s.Close()
+
+	c, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := task.NewWaiter(c.Client)
+
+	// A task that runs until we say so
+	running := make(chan struct{})
+	f := Map.Any("Folder")
+	blocker := CreateTask(f, "block", func(*Task) (types.AnyType, types.BaseMethodFault) {
+		<-running
+		return nil, nil
+	})
+	go blocker.Run()
+
+	if err = w.Add(ctx, blocker.Self, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var refs []types.ManagedObjectReference
+	reports := make(chan progress.Report, 100)
+	sink := progress.SinkFunc(func() chan<- progress.Report { return reports })
+
+	for i, vm := range Map.All("VirtualMachine") {
+		obj := object.NewVirtualMachine(c.Client, vm.Reference())
+
+		ptask, perr := obj.PowerOff(ctx)
+		if perr != nil {
+			t.Fatal(perr)
+		}
+
+		refs = append(refs, ptask.Reference())
+
+		var s progress.Sinker
+		if i == 0 {
+			s = sink
+		}
+
+		if err = w.Add(ctx, ptask.Reference(), s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type result struct {
+		res []task.Result
+		err error
+	}
+
+	done := make(chan result)
+	go func() {
+		res, werr := w.Wait(ctx)
+		done <- result{res, werr}
+	}()
+
+	// Add a task while waiting, which fails as the VM is already powered off
+	vm := object.NewVirtualMachine(c.Client, Map.Any("VirtualMachine").Reference())
+	ptask, err := vm.PowerOff(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = w.Add(ctx, ptask.Reference(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	close(running)
+
+	r := <-done
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+
+	if len(r.res) != len(refs)+2 {
+		t.Fatalf("%d results", len(r.res))
+	}
+
+	for i, res := range r.res {
+		if res.Info == nil {
+			t.Fatalf("%d: %s info is nil", i, res.Task)
+		}
+
+		switch i {
+		case 0:
+			if res.Task != blocker.Self || res.Err != nil {
+				t.Errorf("%d: %#v", i, res)
+			}
+		case len(r.res) - 1:
+			if res.Task != ptask.Reference() || res.Info.State != types.TaskInfoStateError {
+				t.Errorf("%d: %#v", i, res)
+			}
+			if _, ok := res.Err.(task.Error); !ok {
+				t.Errorf("%d: err=%#v", i, res.Err)
+			}
+		default:
+			if res.Task != refs[i-1] || res.Info.State != types.TaskInfoStateSuccess || res.Err != nil {
+				t.Errorf("%d: %#v", i, res)
+			}
+		}
+	}
+
+	n := 0
+	for range reports { // closed by the Waiter
+		n++
+	}
+	if n == 0 {
+		t.Error("no progress reports")
+	}
+}
+
+func TestTaskWaiterWaitForResult(t *testing.T) {
+	ctx := context.Background()
+
+	m := VPX()
+
+	defer m.Remove()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service.NewServer()
+	defer s.Close()
+
+	c, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := task.NewWaiter(c.Client)
+
+	vms := Map.All("VirtualMachine")
+	errs := make([]error, len(vms))
+	var wg sync.WaitGroup
+
+	for i := range vms {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			vm := object.NewVirtualMachine(c.Client, vms[i].Reference())
+
+			ptask, perr := vm.PowerOff(ctx)
+			if perr != nil {
+				errs[i] = perr
+				return
+			}
+
+			info, perr := w.WaitForResult(ctx, ptask.Reference(), nil)
+			if perr == nil && info.State != types.TaskInfoStateSuccess {
+				t.Errorf("state=%s", info.State)
+			}
+			errs[i] = perr
+		}(i)
+	}
+
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("%s: %s", vms[i].Reference(), err)
+		}
+	}
+
+	// The Waiter is reused once all tasks have finished, the VM is already powered off
+	vm := object.NewVirtualMachine(c.Client, vms[0].Reference())
+	ptask, err := vm.PowerOff(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := w.WaitForResult(ctx, ptask.Reference(), nil)
+	if _, ok := err.(task.Error); !ok {
+		t.Errorf("err=%#v", err)
+	}
+	if info == nil || info.State != types.TaskInfoStateError {
+		t.Errorf("info=%#v", info)
+	}
+}
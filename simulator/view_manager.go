@@ -128,6 +128,8 @@ func (m *ViewManager) CreateContainerView(ctx *Context, req *types.CreateContain
 	seen := make(map[types.ManagedObjectReference]bool)
 	container.add(root, seen)
 
+	ctx.Map.AddHandler(container) // Listen for entities entering or leaving the container
+
 	return body
 }
 
@@ -139,9 +141,128 @@ type ContainerView struct {
 
 func (v *ContainerView) DestroyView(ctx *Context, c *types.DestroyView) soap.HasFault {
 	ctx.Session.Remove(c.This)
+	ctx.Map.Remove(c.This)
 	return destroyView(c.This)
 }
 
+// parents returns the given entity's parent references, including the
+// resource pool and host of a VirtualMachine, which are its parents in a ContainerView.
+func parents(obj mo.Reference) []types.ManagedObjectReference {
+	var refs []types.ManagedObjectReference
+
+	e, ok := obj.(mo.Entity)
+	if !ok {
+		return nil
+	}
+
+	if p := e.Entity().Parent; p != nil {
+		refs = append(refs, *p)
+	}
+
+	var vm *mo.VirtualMachine
+	switch o := obj.(type) {
+	case *VirtualMachine:
+		vm = &o.VirtualMachine
+	case *mo.VirtualMachine: // Registry.Update passes the embedded mo type to UpdateObject
+		vm = o
+	}
+
+	if vm != nil {
+		if vm.ResourcePool != nil {
+			refs = append(refs, *vm.ResourcePool)
+		}
+		if vm.Runtime.Host != nil {
+			refs = append(refs, *vm.Runtime.Host)
+		}
+	}
+
+	return refs
+}
+
+// contains returns true if the given object is a child of the view's Container,
+// or a descendant if the view is Recursive.
+func (v *ContainerView) contains(obj mo.Reference) bool {
+	for _, ref := range parents(obj) {
+		if ref == v.Container {
+			return true
+		}
+
+		if v.Recursive {
+			if p := Map.Get(ref); p != nil && v.contains(p) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (v *ContainerView) update() {
+	Map.Update(v, []types.PropertyChange{{Name: "view", Val: v.View}})
+}
+
+func (v *ContainerView) PutObject(obj mo.Reference) {
+	ref := obj.Reference()
+
+	if !v.include(ref) || !v.contains(obj) {
+		return
+	}
+
+	changed := false
+
+	Map.WithLock(v, func() {
+		if FindReference(v.View, ref) == nil {
+			v.View = append(v.View, ref)
+			changed = true
+		}
+	})
+
+	if changed {
+		v.update()
+	}
+}
+
+// UpdateObject re-checks the containment of an entity that has moved to another parent, resource pool or host,
+// adding it to or removing it from the view.
+func (v *ContainerView) UpdateObject(obj mo.Reference, changes []types.PropertyChange) {
+	moved := false
+	for _, change := range changes {
+		switch change.Name {
+		case "parent", "resourcePool", "runtime.host":
+			moved = true
+		}
+	}
+
+	if !moved || !v.include(obj.Reference()) {
+		return
+	}
+
+	if v.contains(obj) {
+		v.PutObject(obj)
+	} else {
+		v.RemoveObject(obj.Reference())
+	}
+}
+
+func (v *ContainerView) RemoveObject(ref types.ManagedObjectReference) {
+	if ref == v.Self {
+		return // DestroyView, which holds our lock
+	}
+
+	changed := false
+
+	Map.WithLock(v, func() {
+		if FindReference(v.View, ref) != nil {
+			RemoveReference(&v.View, ref)
+			changed = true
+		}
+	})
+
+	if changed {
+		v.update()
+	}
+}
+
 func (v *ContainerView) include(o types.ManagedObjectReference) bool {
 	if len(v.types) == 0 {
 		return true
@@ -18,11 +18,14 @@ package simulator
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/vmware/govmomi"
 	"github.com/vmware/govmomi/object"
 	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/session"
 	"github.com/vmware/govmomi/view"
 	"github.com/vmware/govmomi/vim25/mo"
 	"github.com/vmware/govmomi/vim25/types"
@@ -142,3 +145,340 @@ func TestContainerViewVPX(t *testing.T) {
 		}
 	}
 }
+
+func TestContainerViewFindWithPredicate(t *testing.T) {
+	ctx := context.Background()
+
+	m := VPX()
+
+	defer m.Remove()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := m.Service.client
+	root := c.ServiceContent.RootFolder
+	kind := []string{"VirtualMachine"}
+
+	vm := Map.Any("VirtualMachine").(*VirtualMachine)
+	task, err := object.NewVirtualMachine(c, vm.Reference()).PowerOff(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = task.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := view.NewManager(c).CreateContainerView(ctx, root, kind, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer v.Destroy(ctx)
+
+	tests := []struct {
+		expr   string
+		expect int
+	}{
+		{"name==*", 4},
+		{"runtime.powerState==poweredOff", 1},
+		{"runtime.powerState!=poweredOff && summary.config.numCpu>=1", 3},
+		{"name=~_VM1$ || name==DC0_H0_VM0", 3},
+		{"config.hardware.device.deviceInfo.label=='IDE 0'", 4},
+		{"config.hardware.device.key>100000", 0},
+	}
+
+	for _, test := range tests {
+		p, perr := property.ParsePredicate(test.expr)
+		if perr != nil {
+			t.Fatal(perr)
+		}
+
+		content, ferr := v.FindWithPredicate(ctx, kind, p, "name")
+		if ferr != nil {
+			t.Fatal(ferr)
+		}
+
+		if len(content) != test.expect {
+			t.Errorf("%s: %d", test.expr, len(content))
+		}
+
+		for _, o := range content {
+			for _, prop := range o.PropSet {
+				if strings.HasPrefix(prop.Name, "config.hardware.device.") {
+					t.Errorf("collected %s", prop.Name) // collected as config.hardware.device
+				}
+			}
+		}
+	}
+
+	p := property.Filter{"runtime.powerState": "poweredOn"}.Predicate()
+	content, err := v.FindWithPredicate(ctx, kind, p, "name")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	property.SortObjectContent(content, "name", true)
+	var names []string
+	for _, o := range content {
+		for _, prop := range o.PropSet {
+			if prop.Name == "name" {
+				names = append(names, prop.Val.(string))
+			}
+		}
+	}
+
+	if len(names) != 3 || names[0] < names[1] || names[1] < names[2] {
+		t.Errorf("names=%v", names)
+	}
+}
+
+func TestViewCache(t *testing.T) {
+	ctx := context.Background()
+
+	m := VPX()
+
+	defer m.Remove()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service.NewServer()
+	defer s.Close()
+
+	c, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := c.ServiceContent.RootFolder
+	cache := view.NewCache(c.Client, root, []string{"VirtualMachine"}, []string{"config.uuid", "runtime.powerState"})
+	cache.MaxObjectUpdates = 1 // truncated UpdateSets
+	cache.Login = func(ctx context.Context) error {
+		return c.Login(ctx, s.URL.User)
+	}
+
+	changes := make(chan view.Change, 100)
+	cancelSubscription := cache.Subscribe(func(change view.Change) {
+		changes <- change
+	})
+	defer cancelSubscription()
+
+	wait := func(f func(view.Change) bool) {
+		for {
+			select {
+			case change := <-changes:
+				if f(change) {
+					return
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("timeout waiting for change")
+			}
+		}
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() {
+		done <- cache.Run(cctx)
+	}()
+
+	select {
+	case <-cache.Ready():
+	case err = <-done:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for cache")
+	}
+
+	count := m.Count()
+	if n := len(cache.List("VirtualMachine")); n != count.Machine {
+		t.Errorf("cached %d VMs", n)
+	}
+
+	vm := Map.Any("VirtualMachine").(*VirtualMachine)
+
+	match := func(objects []interface{}) bool {
+		for _, o := range objects {
+			if o.(mo.VirtualMachine).Self == vm.Self {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !match(cache.FindByName(vm.Name)) {
+		t.Error("FindByName")
+	}
+
+	if !match(cache.FindByUUID(strings.ToUpper(vm.Config.Uuid))) {
+		t.Error("FindByUUID")
+	}
+
+	if !match(cache.Children(*vm.Parent)) {
+		t.Error("Children")
+	}
+
+	obj := object.NewVirtualMachine(admin.Client, vm.Self)
+
+	task, err := obj.PowerOff(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = task.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	wait(func(change view.Change) bool {
+		if change.Ref != vm.Self || change.Kind != types.ObjectUpdateKindModify {
+			return false
+		}
+		return change.Object.(mo.VirtualMachine).Runtime.PowerState == types.VirtualMachinePowerStatePoweredOff
+	})
+
+	o, ok := cache.Get(vm.Self)
+	if !ok || o.(mo.VirtualMachine).Runtime.PowerState != types.VirtualMachinePowerStatePoweredOff {
+		t.Errorf("object=%#v", o)
+	}
+
+	// Terminate the cache session, the cache should login again and resync
+	us, err := session.NewManager(c.Client).UserSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = session.NewManager(admin.Client).TerminateSession(ctx, []string{us.Key})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err = obj.Destroy(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = task.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	wait(func(change view.Change) bool {
+		return change.Ref == vm.Self && change.Kind == types.ObjectUpdateKindLeave
+	})
+
+	if n := len(cache.List()); n != count.Machine-1 {
+		t.Errorf("cached %d VMs", n)
+	}
+
+	if _, ok = cache.Get(vm.Self); ok {
+		t.Error("destroyed VM is still cached")
+	}
+
+	us, err = session.NewManager(c.Client).UserSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if us == nil {
+		t.Error("expected Login")
+	}
+
+	cancel()
+
+	if err = <-done; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestContainerViewMove(t *testing.T) {
+	ctx := context.Background()
+
+	m := VPX()
+
+	defer m.Remove()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service.NewServer()
+	defer s.Close()
+
+	c, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var host *HostSystem
+	for _, e := range Map.All("HostSystem") {
+		if e.Entity().Parent.Type == "ComputeResource" {
+			host = e.(*HostSystem) // standalone host
+		}
+	}
+	cluster := Map.Any("ClusterComputeResource").(*ClusterComputeResource)
+	pool := Map.Get(*host.Parent).(*mo.ComputeResource).ResourcePool
+
+	v, err := view.NewManager(c.Client).CreateContainerView(ctx, *host.Parent, []string{"VirtualMachine"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var vm *VirtualMachine
+	for _, ref := range Map.Get(*cluster.ResourcePool).(*ResourcePool).Vm {
+		vm = Map.Get(ref).(*VirtualMachine)
+		break
+	}
+
+	contains := func() bool {
+		var cv mo.ContainerView
+		if err = v.Properties(ctx, v.Reference(), []string{"view"}, &cv); err != nil {
+			t.Fatal(err)
+		}
+		return FindReference(cv.View, vm.Self) != nil
+	}
+
+	relocate := func(spec types.VirtualMachineRelocateSpec) {
+		task, rerr := object.NewVirtualMachine(c.Client, vm.Self).Relocate(ctx, spec, types.VirtualMachineMovePriorityDefaultPriority)
+		if rerr != nil {
+			t.Fatal(rerr)
+		}
+		if rerr = task.Wait(ctx); rerr != nil {
+			t.Fatal(rerr)
+		}
+	}
+
+	if contains() {
+		t.Fatal("cluster VM in host view")
+	}
+
+	relocate(types.VirtualMachineRelocateSpec{Pool: pool, Host: &host.Self})
+	if !contains() {
+		t.Error("VM moved into the container is not in the view")
+	}
+
+	relocate(types.VirtualMachineRelocateSpec{Pool: cluster.ResourcePool, Host: &cluster.Host[0]})
+	if contains() {
+		t.Error("VM moved out of the container is still in the view")
+	}
+
+	// Logout should destroy the view
+	if err = c.Logout(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if Map.Get(v.Reference()) != nil {
+		t.Error("view handler was not removed")
+	}
+
+	for _, ref := range Map.ViewManager().ViewList {
+		if ref == v.Reference() {
+			t.Error("view was not removed from ViewList")
+		}
+	}
+}
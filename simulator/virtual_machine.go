@@ -43,6 +43,8 @@ type VirtualMachine struct {
 
 	log string
 	sid int32
+
+	profile map[int32][]string // storage profile IDs of the VM home (key -1) and its disks
 }
 
 func NewVirtualMachine(parent types.ManagedObjectReference, spec *types.VirtualMachineConfigSpec) (*VirtualMachine, types.BaseMethodFault) {
@@ -209,6 +211,8 @@ func (vm *VirtualMachine) apply(spec *types.VirtualMachineConfigSpec) {
 		vm.Config.ManagedBy = spec.ManagedBy
 	}
 
+	vm.setProfile(-1, spec.VmProfile)
+
 	if spec.BootOptions != nil {
 		vm.Config.BootOptions = spec.BootOptions
 	}
@@ -298,6 +302,38 @@ func (vm *VirtualMachine) configure(spec *types.VirtualMachineConfigSpec) types.
 	return vm.configureDevices(spec)
 }
 
+// setProfile records the storage profile IDs of the VM home (key -1) or the device with the given key.
+// Nil spec leaves the profile as-is, an empty profile spec removes it.
+func (vm *VirtualMachine) setProfile(key int32, spec []types.BaseVirtualMachineProfileSpec) {
+	if spec == nil {
+		return
+	}
+
+	if vm.profile == nil {
+		vm.profile = make(map[int32][]string)
+	}
+
+	var ids []string
+	for _, p := range spec {
+		if p, ok := p.(*types.VirtualMachineDefinedProfileSpec); ok {
+			ids = append(ids, p.ProfileId)
+		}
+	}
+
+	if len(ids) == 0 {
+		delete(vm.profile, key)
+	} else {
+		vm.profile[key] = ids
+	}
+}
+
+// StorageProfile returns the storage profile IDs associated with the VM home (key -1)
+// or the virtual disk with the given key, as specified by the VmProfile and device Profile
+// fields of the VM's create and reconfigure specs.
+func (vm *VirtualMachine) StorageProfile(key int32) []string {
+	return vm.profile[key]
+}
+
 func (vm *VirtualMachine) useDatastore(name string) *Datastore {
 	host := Map.Get(*vm.Runtime.Host).(*HostSystem)
 
@@ -643,12 +679,17 @@ func (vm *VirtualMachine) genVmdkPath() (string, types.BaseMethodFault) {
 
 func (vm *VirtualMachine) configureDevices(spec *types.VirtualMachineConfigSpec) types.BaseMethodFault {
 	devices := object.VirtualDeviceList(vm.Config.Hardware.Device)
+	keys := make(map[int32]int32) // negative keys of added devices, mapped to the assigned keys
 
 	for i, change := range spec.DeviceChange {
 		dspec := change.GetVirtualDeviceConfigSpec()
 		device := dspec.Device.GetVirtualDevice()
 		invalid := &types.InvalidDeviceSpec{DeviceIndex: int32(i)}
 
+		if key, ok := keys[device.ControllerKey]; ok {
+			device.ControllerKey = key
+		}
+
 		switch dspec.Operation {
 		case types.VirtualDeviceConfigSpecOperationAdd:
 			if devices.FindByKey(device.Key) != nil {
@@ -660,11 +701,16 @@ func (vm *VirtualMachine) configureDevices(spec *types.VirtualMachineConfigSpec)
 				devices = vm.removeDevice(devices, dspec)
 			}
 
+			key := device.Key
 			err := vm.configureDevice(devices, dspec)
 			if err != nil {
 				return err
 			}
+			if key < -1 {
+				keys[key] = device.Key
+			}
 
+			vm.setProfile(device.Key, dspec.Profile)
 			devices = append(devices, dspec.Device)
 		case types.VirtualDeviceConfigSpecOperationEdit:
 			rspec := *dspec
@@ -680,9 +726,11 @@ func (vm *VirtualMachine) configureDevices(spec *types.VirtualMachineConfigSpec)
 				return err
 			}
 
+			vm.setProfile(device.Key, dspec.Profile)
 			devices = append(devices, dspec.Device)
 		case types.VirtualDeviceConfigSpecOperationRemove:
 			devices = vm.removeDevice(devices, dspec)
+			delete(vm.profile, device.Key)
 		}
 	}
 
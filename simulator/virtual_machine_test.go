@@ -376,6 +376,107 @@ func TestReconfigVmDevice(t *testing.T) {
 	}
 }
 
+func TestReconfigVmDeviceDiff(t *testing.T) {
+	ctx := context.Background()
+
+	m := ESX()
+	defer m.Remove()
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service.NewServer()
+	defer s.Close()
+
+	c, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vmm := Map.Any("VirtualMachine").(*VirtualMachine)
+	vm := object.NewVirtualMachine(c.Client, vmm.Reference())
+
+	device, err := vm.Device(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	desired := device.Select(func(d types.BaseVirtualDevice) bool {
+		_, ok := d.(types.BaseVirtualEthernetCard)
+		return !ok // remove NIC
+	})
+
+	scsi, err := desired.CreateSCSIController("pvscsi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	desired = append(desired, scsi)
+
+	for _, size := range []int64{1024, 2048} {
+		disk := &types.VirtualDisk{CapacityInKB: size}
+		disk.ControllerKey = scsi.GetVirtualDevice().Key
+		disk.Backing = &types.VirtualDiskFlatVer2BackingInfo{
+			DiskMode:        string(types.VirtualDiskModePersistent),
+			ThinProvisioned: types.NewBool(true),
+		}
+		desired = append(desired, disk)
+	}
+
+	changes, err := device.Diff(desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(changes) != 4 {
+		t.Fatalf("changes=%d", len(changes))
+	}
+
+	task, err := vm.Reconfigure(ctx, types.VirtualMachineConfigSpec{DeviceChange: changes})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = task.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	device, err = vm.Device(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(device.SelectByType((*types.VirtualEthernetCard)(nil))) != 0 {
+		t.Error("NIC was not removed")
+	}
+
+	// the Model creates a pvscsi controller, the added controller follows it
+	pvscsi := device.SelectByType((*types.ParaVirtualSCSIController)(nil))
+	if len(pvscsi) != 2 {
+		t.Fatalf("pvscsi=%d", len(pvscsi))
+	}
+	key := pvscsi[1].GetVirtualDevice().Key
+
+	units := make(map[int32]bool)
+	for _, d := range device.SelectByType((*types.VirtualDisk)(nil)) {
+		disk := d.GetVirtualDevice()
+		if disk.ControllerKey == key {
+			units[*disk.UnitNumber] = true
+		}
+	}
+	if len(units) != 2 {
+		t.Errorf("disks on controller %d: %v", key, units)
+	}
+
+	// applying the same desired list again results in no changes
+	changes, err = device.Diff(device)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("changes=%d", len(changes))
+	}
+}
+
 func TestReconfigVm(t *testing.T) {
 	ctx := context.Background()
 
@@ -21799,3 +21799,39 @@ var DatacenterMetrics = []types.PerfMetricId{
 		Instance:  "",
 	},
 }
+
+// HistoricalInterval is the default VC PerformanceManager.historicalInterval
+var HistoricalInterval = []types.PerfInterval{
+	{
+		Key:            1,
+		SamplingPeriod: 300,
+		Name:           "Past day",
+		Length:         86400,
+		Level:          1,
+		Enabled:        true,
+	},
+	{
+		Key:            2,
+		SamplingPeriod: 1800,
+		Name:           "Past week",
+		Length:         604800,
+		Level:          1,
+		Enabled:        true,
+	},
+	{
+		Key:            3,
+		SamplingPeriod: 7200,
+		Name:           "Past month",
+		Length:         2592000,
+		Level:          1,
+		Enabled:        true,
+	},
+	{
+		Key:            4,
+		SamplingPeriod: 86400,
+		Name:           "Past year",
+		Length:         31536000,
+		Level:          1,
+		Enabled:        true,
+	},
+}
@@ -73,4 +73,8 @@ var Setting = []types.BaseOptionValue{
 		Key:   "event.maxAgeEnabled",
 		Value: bool(true),
 	},
+	&types.OptionValue{
+		Key:   "config.vpxd.stats.maxQueryMetrics",
+		Value: "64",
+	},
 }
@@ -0,0 +1,303 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vmware/govmomi/ssoadmin/types"
+)
+
+const (
+	kindPerson   = "person"
+	kindSolution = "solution"
+	kindGroup    = "group"
+)
+
+var (
+	errAlreadyExists = errors.New("principal already exists")
+	errInvalid       = errors.New("invalid credentials")
+)
+
+// principal is a person user, solution user or group in the Directory.
+type principal struct {
+	id       types.PrincipalId
+	kind     string
+	person   types.AdminPersonDetails
+	solution types.AdminSolutionDetails
+	group    types.AdminGroupDetails
+	password string
+	disabled bool
+	role     string
+	wstrust  map[string]bool
+	parents  map[string]bool // keys of the groups this principal is a direct member of
+}
+
+func (p *principal) user() *types.AdminUser {
+	u := &types.AdminUser{
+		Id:   p.id,
+		Kind: p.kind,
+	}
+	switch p.kind {
+	case kindPerson:
+		u.Description = p.person.Description
+	case kindSolution:
+		u.Description = p.solution.Description
+	}
+	return u
+}
+
+func (p *principal) personUser() *types.AdminPersonUser {
+	return &types.AdminPersonUser{
+		Id:       p.id,
+		Details:  p.person,
+		Disabled: p.disabled,
+	}
+}
+
+func (p *principal) solutionUser() *types.AdminSolutionUser {
+	return &types.AdminSolutionUser{
+		Id:       p.id,
+		Details:  p.solution,
+		Disabled: p.disabled,
+	}
+}
+
+func (p *principal) adminGroup() *types.AdminGroup {
+	return &types.AdminGroup{
+		Id:      p.id,
+		Details: p.group,
+	}
+}
+
+// Directory is an in-memory store of SSO principals, shared by the SSO admin and STS simulators.
+type Directory struct {
+	Domain string
+
+	mu        sync.Mutex
+	principal map[string]*principal
+}
+
+// NewDirectory returns a Directory for the given system domain,
+// populated with the default groups and an admin user with the given name and password.
+func NewDirectory(domain string, admin string, password string) *Directory {
+	d := &Directory{
+		Domain:    domain,
+		principal: make(map[string]*principal),
+	}
+
+	for _, name := range []string{"Administrators", "Users", "SolutionUsers"} {
+		_ = d.add(&principal{id: d.id(name), kind: kindGroup})
+	}
+
+	_ = d.AddPersonUser(admin, password, types.RoleAdministrator)
+
+	return d
+}
+
+// key returns the case insensitive map key for the given id.
+func key(id types.PrincipalId) string {
+	return strings.ToLower(id.Name + "@" + id.Domain)
+}
+
+// id parses the given name, which may be of the form "name", "name@domain" or "domain\name".
+func (d *Directory) id(name string) types.PrincipalId {
+	if p := strings.SplitN(name, "@", 2); len(p) == 2 {
+		return types.PrincipalId{Name: p[0], Domain: p[1]}
+	}
+	if p := strings.SplitN(name, `\`, 2); len(p) == 2 {
+		return types.PrincipalId{Name: p[1], Domain: p[0]}
+	}
+	return types.PrincipalId{Name: name, Domain: d.Domain}
+}
+
+// lookup returns the principal for the given id, with the domain defaulting to the system domain.
+func (d *Directory) lookup(id types.PrincipalId, kind ...string) *principal {
+	if id.Domain == "" {
+		id.Domain = d.Domain
+	}
+
+	p, ok := d.principal[key(id)]
+	if !ok {
+		return nil
+	}
+
+	if len(kind) == 0 {
+		return p
+	}
+
+	for _, k := range kind {
+		if p.kind == k {
+			return p
+		}
+	}
+
+	return nil
+}
+
+func (d *Directory) add(p *principal) error {
+	k := key(p.id)
+	if _, ok := d.principal[k]; ok {
+		return errAlreadyExists
+	}
+	if p.parents == nil {
+		p.parents = make(map[string]bool)
+	}
+	p.wstrust = make(map[string]bool)
+	d.principal[k] = p
+	return nil
+}
+
+// AddPersonUser adds a person user with the given password and role to the system domain.
+func (d *Directory) AddPersonUser(name string, password string, role string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	p := &principal{
+		id:       d.id(name),
+		kind:     kindPerson,
+		password: password,
+		role:     role,
+		parents:  map[string]bool{key(d.id("Users")): true},
+	}
+
+	if role == types.RoleAdministrator {
+		p.parents[key(d.id("Administrators"))] = true
+	}
+
+	return d.add(p)
+}
+
+// AuthenticateUser implements the sts/simulator Authenticator interface.
+func (d *Directory) AuthenticateUser(name, password string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	p := d.lookup(d.id(name), kindPerson)
+	if p == nil || p.disabled || p.password != password {
+		return "", errInvalid
+	}
+
+	return p.id.Name + "@" + strings.ToUpper(p.id.Domain), nil
+}
+
+// AuthenticateCertificate implements the sts/simulator Authenticator interface.
+func (d *Directory) AuthenticateCertificate(cert []byte) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, p := range d.principal {
+		if p.kind != kindSolution || p.disabled {
+			continue
+		}
+
+		c, err := base64.StdEncoding.DecodeString(p.solution.Certificate)
+		if err != nil {
+			continue
+		}
+
+		if bytes.Equal(c, cert) {
+			return p.id.Name + "@" + strings.ToUpper(p.id.Domain), nil
+		}
+	}
+
+	return "", errInvalid
+}
+
+// parentGroups returns the groups the given principal is a member of, including nested groups when all is true.
+func (d *Directory) parentGroups(p *principal, all bool) []*principal {
+	seen := make(map[string]bool)
+	var groups []*principal
+	var walk func(*principal)
+
+	walk = func(m *principal) {
+		for k := range m.parents {
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			g, ok := d.principal[k]
+			if !ok {
+				continue
+			}
+			groups = append(groups, g)
+			if all {
+				walk(g)
+			}
+		}
+	}
+
+	walk(p)
+
+	sort.Slice(groups, func(i, j int) bool { return key(groups[i].id) < key(groups[j].id) })
+
+	return groups
+}
+
+// members returns the direct members of the given group.
+func (d *Directory) members(g *principal) []*principal {
+	var members []*principal
+	k := key(g.id)
+
+	for _, p := range d.principal {
+		if p.parents[k] {
+			members = append(members, p)
+		}
+	}
+
+	sort.Slice(members, func(i, j int) bool { return key(members[i].id) < key(members[j].id) })
+
+	return members
+}
+
+// search returns principals of the given kind matching the search criteria, sorted by id.
+func (d *Directory) search(kind string, domain string, search string, limit int32) []*principal {
+	var res []*principal
+	search = strings.ToLower(search)
+
+	for _, p := range d.principal {
+		if p.kind != kind {
+			continue
+		}
+		if domain != "" && !strings.EqualFold(domain, p.id.Domain) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(p.id.Name), search) {
+			continue
+		}
+		res = append(res, p)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return key(res[i].id) < key(res[j].id) })
+
+	if limit > 0 && len(res) > int(limit) {
+		res = res[:limit]
+	}
+
+	return res
+}
+
+// isAdmin returns true if the given session user has the Administrator role.
+func (d *Directory) isAdmin(name string) bool {
+	p := d.lookup(d.id(name), kindPerson, kindSolution)
+	return p != nil && !p.disabled && p.role == types.RoleAdministrator
+}
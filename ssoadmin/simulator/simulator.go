@@ -0,0 +1,959 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/ssoadmin"
+	"github.com/vmware/govmomi/ssoadmin/methods"
+	"github.com/vmware/govmomi/ssoadmin/types"
+	"github.com/vmware/govmomi/vim25/soap"
+	vim "github.com/vmware/govmomi/vim25/types"
+)
+
+var content = types.AdminServiceContent{
+	SessionManager:             vim.ManagedObjectReference{Type: "SsoSessionManager", Value: "ssoSessionManager"},
+	PrincipalDiscoveryService:  vim.ManagedObjectReference{Type: "SsoAdminPrincipalDiscoveryService", Value: "principalDiscoveryService"},
+	PrincipalManagementService: vim.ManagedObjectReference{Type: "SsoAdminPrincipalManagementService", Value: "principalManagementService"},
+	RoleManagementService:      vim.ManagedObjectReference{Type: "SsoAdminRoleManagementService", Value: "roleManagementService"},
+}
+
+var groupcheck = types.GroupcheckServiceContent{
+	SessionManager:    content.SessionManager,
+	GroupCheckService: vim.ManagedObjectReference{Type: "SsoGroupcheckGroupCheckService", Value: "groupCheckService"},
+}
+
+// New creates an SSO admin simulator, with the Directory's system domain and Administrator user
+// as configured by the settings "config.vpxd.sso.default.admin" property.
+// The user of the given URL, if any, is also added to the Directory.
+// Both users are granted the Administrator role and use the URL's password.
+// The Directory can be used as the sts/simulator Authenticator.
+func New(u *url.URL, settings []vim.BaseOptionValue) (*simulator.Registry, *Directory) {
+	r := simulator.NewRegistry()
+	r.Namespace = ssoadmin.Namespace
+	r.Path = ssoadmin.Path
+
+	defaultAdmin := "Administrator@vsphere.local"
+	for _, setting := range settings {
+		opt := setting.GetOptionValue()
+		if opt.Key == "config.vpxd.sso.default.admin" {
+			defaultAdmin = opt.Value.(string)
+		}
+	}
+
+	var name, password string
+	if u.User != nil {
+		name = u.User.Username()
+		password, _ = u.User.Password()
+	}
+
+	id := strings.SplitN(defaultAdmin, "@", 2)
+	dir := NewDirectory(id[len(id)-1], id[0], password)
+	if name != "" {
+		_ = dir.AddPersonUser(name, password, types.RoleAdministrator)
+	}
+
+	r.Put(&ServiceInstance{
+		ManagedObjectReference: ssoadmin.ServiceInstance,
+		Content:                content,
+	})
+	r.Put(&GroupcheckServiceInstance{
+		ManagedObjectReference: vim.ManagedObjectReference{Type: "SsoGroupcheckServiceInstance", Value: "ServiceInstance"},
+		Content:                groupcheck,
+	})
+	r.Put(&SessionManager{
+		ManagedObjectReference: content.SessionManager,
+		dir:                    dir,
+	})
+	r.Put(&PrincipalDiscoveryService{
+		ManagedObjectReference: content.PrincipalDiscoveryService,
+		dir:                    dir,
+	})
+	r.Put(&PrincipalManagementService{
+		ManagedObjectReference: content.PrincipalManagementService,
+		dir:                    dir,
+	})
+	r.Put(&RoleManagementService{
+		ManagedObjectReference: content.RoleManagementService,
+		dir:                    dir,
+	})
+	r.Put(&GroupCheckService{
+		ManagedObjectReference: groupcheck.GroupCheckService,
+		dir:                    dir,
+	})
+
+	return r, dir
+}
+
+var (
+	invalidLogin = simulator.Fault("Login failure", new(vim.InvalidLogin))
+	noPermission = simulator.Fault("Administrator role required", &vim.NoPermission{PrivilegeId: types.RoleAdministrator})
+)
+
+func notFound(name string) *soap.Fault {
+	return simulator.Fault(name+" not found", &vim.NotFound{})
+}
+
+func alreadyExists(name string) *soap.Fault {
+	return simulator.Fault(name+" already exists", &vim.AlreadyExists{Name: name})
+}
+
+// admin returns a NoPermission fault if the session user does not have the Administrator role.
+func admin(ctx *simulator.Context, dir *Directory) *soap.Fault {
+	if ctx.Session == nil || !dir.isAdmin(ctx.Session.UserName) {
+		return noPermission
+	}
+	return nil
+}
+
+type ServiceInstance struct {
+	vim.ManagedObjectReference
+
+	Content types.AdminServiceContent
+}
+
+func (s *ServiceInstance) SsoAdminServiceInstance(_ *types.SsoAdminServiceInstance) soap.HasFault {
+	return &methods.SsoAdminServiceInstanceBody{
+		Res: &types.SsoAdminServiceInstanceResponse{
+			Returnval: s.Content,
+		},
+	}
+}
+
+type GroupcheckServiceInstance struct {
+	vim.ManagedObjectReference
+
+	Content types.GroupcheckServiceContent
+}
+
+func (s *GroupcheckServiceInstance) SsoGroupcheckServiceInstance(_ *types.SsoGroupcheckServiceInstance) soap.HasFault {
+	return &methods.SsoGroupcheckServiceInstanceBody{
+		Res: &types.SsoGroupcheckServiceInstanceResponse{
+			Returnval: s.Content,
+		},
+	}
+}
+
+type SessionManager struct {
+	vim.ManagedObjectReference
+
+	dir *Directory
+}
+
+// Login requires a SAML token issued by the STS for a user in the Directory.
+// Note that the token signature is not verified.
+func (s *SessionManager) Login(ctx *simulator.Context, _ *types.Login) soap.HasFault {
+	body := new(methods.LoginBody)
+
+	var subject struct {
+		ID string `xml:"Assertion>Subject>NameID"`
+	}
+
+	if e, ok := ctx.Header.Security.(*simulator.Element); ok {
+		_ = e.Decode(&subject)
+	}
+
+	s.dir.mu.Lock()
+	p := s.dir.lookup(s.dir.id(subject.ID), kindPerson, kindSolution)
+	s.dir.mu.Unlock()
+
+	if p == nil || p.disabled {
+		body.Fault_ = invalidLogin
+		return body
+	}
+
+	now := time.Now().UTC()
+	name := p.id.Name + "@" + p.id.Domain
+
+	ctx.SetSession(simulator.Session{
+		UserSession: vim.UserSession{
+			Key:            uuid.New().String(),
+			UserName:       name,
+			FullName:       name,
+			LoginTime:      now,
+			LastActiveTime: now,
+		},
+		Registry: simulator.NewRegistry(),
+	}, true)
+
+	body.Res = new(types.LoginResponse)
+
+	return body
+}
+
+func (s *SessionManager) Logout(ctx *simulator.Context, _ *types.Logout) soap.HasFault {
+	m := simulator.Map.SessionManager()
+
+	ctx.WithLock(m, func() {
+		m.Logout(ctx, new(vim.Logout))
+	})
+
+	return &methods.LogoutBody{Res: new(types.LogoutResponse)}
+}
+
+type PrincipalDiscoveryService struct {
+	vim.ManagedObjectReference
+
+	dir *Directory
+}
+
+func (s *PrincipalDiscoveryService) FindUser(req *types.FindUser) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	body := &methods.FindUserBody{Res: new(types.FindUserResponse)}
+
+	if p := s.dir.lookup(req.UserId, kindPerson, kindSolution); p != nil {
+		body.Res.Returnval = p.user()
+	}
+
+	return body
+}
+
+func (s *PrincipalDiscoveryService) FindPersonUser(req *types.FindPersonUser) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	body := &methods.FindPersonUserBody{Res: new(types.FindPersonUserResponse)}
+
+	if p := s.dir.lookup(req.UserId, kindPerson); p != nil {
+		body.Res.Returnval = p.personUser()
+	}
+
+	return body
+}
+
+func (s *PrincipalDiscoveryService) FindSolutionUser(req *types.FindSolutionUser) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	body := &methods.FindSolutionUserBody{Res: new(types.FindSolutionUserResponse)}
+
+	if p := s.dir.lookup(s.dir.id(req.UserName), kindSolution); p != nil {
+		body.Res.Returnval = p.solutionUser()
+	}
+
+	return body
+}
+
+func (s *PrincipalDiscoveryService) FindGroup(req *types.FindGroup) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	body := &methods.FindGroupBody{Res: new(types.FindGroupResponse)}
+
+	if p := s.dir.lookup(req.GroupId, kindGroup); p != nil {
+		body.Res.Returnval = p.adminGroup()
+	}
+
+	return body
+}
+
+func (s *PrincipalDiscoveryService) FindUsers(req *types.FindUsers) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	body := &methods.FindUsersBody{Res: new(types.FindUsersResponse)}
+
+	for _, kind := range []string{kindPerson, kindSolution} {
+		for _, p := range s.dir.search(kind, req.Criteria.Domain, req.Criteria.SearchString, req.Limit) {
+			body.Res.Returnval = append(body.Res.Returnval, *p.user())
+		}
+	}
+
+	if req.Limit > 0 && len(body.Res.Returnval) > int(req.Limit) {
+		body.Res.Returnval = body.Res.Returnval[:req.Limit]
+	}
+
+	return body
+}
+
+func (s *PrincipalDiscoveryService) FindPersonUsers(req *types.FindPersonUsers) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	body := &methods.FindPersonUsersBody{Res: new(types.FindPersonUsersResponse)}
+
+	for _, p := range s.dir.search(kindPerson, req.Criteria.Domain, req.Criteria.SearchString, req.Limit) {
+		body.Res.Returnval = append(body.Res.Returnval, *p.personUser())
+	}
+
+	return body
+}
+
+func (s *PrincipalDiscoveryService) FindSolutionUsers(req *types.FindSolutionUsers) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	body := &methods.FindSolutionUsersBody{Res: new(types.FindSolutionUsersResponse)}
+
+	for _, p := range s.dir.search(kindSolution, "", req.SearchString, req.Limit) {
+		body.Res.Returnval = append(body.Res.Returnval, *p.solutionUser())
+	}
+
+	return body
+}
+
+func (s *PrincipalDiscoveryService) FindGroups(req *types.FindGroups) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	body := &methods.FindGroupsBody{Res: new(types.FindGroupsResponse)}
+
+	for _, p := range s.dir.search(kindGroup, req.Criteria.Domain, req.Criteria.SearchString, req.Limit) {
+		body.Res.Returnval = append(body.Res.Returnval, *p.adminGroup())
+	}
+
+	return body
+}
+
+func (s *PrincipalDiscoveryService) Find(req *types.Find) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	body := &methods.FindBody{Res: new(types.FindResponse)}
+	res := &body.Res.Returnval
+	criteria := &req.Criteria
+
+	for _, p := range s.dir.search(kindPerson, criteria.Domain, criteria.SearchString, req.Limit) {
+		res.PersonUsers = append(res.PersonUsers, *p.personUser())
+	}
+	for _, p := range s.dir.search(kindSolution, criteria.Domain, criteria.SearchString, req.Limit) {
+		res.SolutionUsers = append(res.SolutionUsers, *p.solutionUser())
+	}
+	for _, p := range s.dir.search(kindGroup, criteria.Domain, criteria.SearchString, req.Limit) {
+		res.Groups = append(res.Groups, *p.adminGroup())
+	}
+
+	return body
+}
+
+func (s *PrincipalDiscoveryService) FindUsersInGroup(req *types.FindUsersInGroup) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	body := new(methods.FindUsersInGroupBody)
+
+	g := s.dir.lookup(req.GroupId, kindGroup)
+	if g == nil {
+		body.Fault_ = notFound(req.GroupId.Name)
+		return body
+	}
+
+	body.Res = new(types.FindUsersInGroupResponse)
+	search := strings.ToLower(req.SearchString)
+
+	for _, p := range s.dir.members(g) {
+		if p.kind == kindGroup {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(p.id.Name), search) {
+			continue
+		}
+		if req.Limit > 0 && len(body.Res.Returnval) == int(req.Limit) {
+			break
+		}
+		body.Res.Returnval = append(body.Res.Returnval, *p.user())
+	}
+
+	return body
+}
+
+func (s *PrincipalDiscoveryService) FindDirectParentGroups(req *types.FindDirectParentGroups) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	body := new(methods.FindDirectParentGroupsBody)
+
+	p := s.dir.lookup(req.PrincipalId)
+	if p == nil {
+		body.Fault_ = notFound(req.PrincipalId.Name)
+		return body
+	}
+
+	body.Res = new(types.FindDirectParentGroupsResponse)
+
+	for _, g := range s.dir.parentGroups(p, false) {
+		body.Res.Returnval = append(body.Res.Returnval, *g.adminGroup())
+	}
+
+	return body
+}
+
+func (s *PrincipalDiscoveryService) FindNestedParentGroups(req *types.FindNestedParentGroups) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	body := new(methods.FindNestedParentGroupsBody)
+
+	p := s.dir.lookup(req.UserId)
+	if p == nil {
+		body.Fault_ = notFound(req.UserId.Name)
+		return body
+	}
+
+	body.Res = new(types.FindNestedParentGroupsResponse)
+
+	for _, g := range s.dir.parentGroups(p, true) {
+		body.Res.Returnval = append(body.Res.Returnval, *g.adminGroup())
+	}
+
+	return body
+}
+
+type PrincipalManagementService struct {
+	vim.ManagedObjectReference
+
+	dir *Directory
+}
+
+func (s *PrincipalManagementService) CreateLocalPersonUser(ctx *simulator.Context, req *types.CreateLocalPersonUser) soap.HasFault {
+	body := new(methods.CreateLocalPersonUserBody)
+
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	if body.Fault_ = admin(ctx, s.dir); body.Fault_ != nil {
+		return body
+	}
+
+	p := &principal{
+		id:       types.PrincipalId{Name: req.UserName, Domain: s.dir.Domain},
+		kind:     kindPerson,
+		person:   req.UserDetails,
+		password: req.Password,
+		role:     types.RoleRegularUser,
+		parents:  map[string]bool{key(s.dir.id("Users")): true},
+	}
+
+	if err := s.dir.add(p); err != nil {
+		body.Fault_ = alreadyExists(req.UserName)
+		return body
+	}
+
+	body.Res = new(types.CreateLocalPersonUserResponse)
+
+	return body
+}
+
+func (s *PrincipalManagementService) CreateLocalSolutionUser(ctx *simulator.Context, req *types.CreateLocalSolutionUser) soap.HasFault {
+	body := new(methods.CreateLocalSolutionUserBody)
+
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	if body.Fault_ = admin(ctx, s.dir); body.Fault_ != nil {
+		return body
+	}
+
+	p := &principal{
+		id:       types.PrincipalId{Name: req.UserName, Domain: s.dir.Domain},
+		kind:     kindSolution,
+		solution: req.UserDetails,
+		role:     types.RoleRegularUser,
+		parents:  map[string]bool{key(s.dir.id("SolutionUsers")): true},
+	}
+
+	if err := s.dir.add(p); err != nil {
+		body.Fault_ = alreadyExists(req.UserName)
+		return body
+	}
+
+	body.Res = new(types.CreateLocalSolutionUserResponse)
+
+	return body
+}
+
+func (s *PrincipalManagementService) CreateLocalGroup(ctx *simulator.Context, req *types.CreateLocalGroup) soap.HasFault {
+	body := new(methods.CreateLocalGroupBody)
+
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	if body.Fault_ = admin(ctx, s.dir); body.Fault_ != nil {
+		return body
+	}
+
+	p := &principal{
+		id:    types.PrincipalId{Name: req.GroupName, Domain: s.dir.Domain},
+		kind:  kindGroup,
+		group: req.GroupDetails,
+	}
+
+	if err := s.dir.add(p); err != nil {
+		body.Fault_ = alreadyExists(req.GroupName)
+		return body
+	}
+
+	body.Res = new(types.CreateLocalGroupResponse)
+
+	return body
+}
+
+func (s *PrincipalManagementService) UpdateLocalPersonUserDetails(ctx *simulator.Context, req *types.UpdateLocalPersonUserDetails) soap.HasFault {
+	body := new(methods.UpdateLocalPersonUserDetailsBody)
+
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	if body.Fault_ = admin(ctx, s.dir); body.Fault_ != nil {
+		return body
+	}
+
+	p := s.dir.lookup(s.dir.id(req.UserName), kindPerson)
+	if p == nil {
+		body.Fault_ = notFound(req.UserName)
+		return body
+	}
+
+	p.person = req.UserDetails
+	body.Res = new(types.UpdateLocalPersonUserDetailsResponse)
+
+	return body
+}
+
+func (s *PrincipalManagementService) UpdateLocalSolutionUserDetails(ctx *simulator.Context, req *types.UpdateLocalSolutionUserDetails) soap.HasFault {
+	body := new(methods.UpdateLocalSolutionUserDetailsBody)
+
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	if body.Fault_ = admin(ctx, s.dir); body.Fault_ != nil {
+		return body
+	}
+
+	p := s.dir.lookup(s.dir.id(req.UserName), kindSolution)
+	if p == nil {
+		body.Fault_ = notFound(req.UserName)
+		return body
+	}
+
+	p.solution = req.UserDetails
+	body.Res = new(types.UpdateLocalSolutionUserDetailsResponse)
+
+	return body
+}
+
+func (s *PrincipalManagementService) UpdateLocalGroupDetails(ctx *simulator.Context, req *types.UpdateLocalGroupDetails) soap.HasFault {
+	body := new(methods.UpdateLocalGroupDetailsBody)
+
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	if body.Fault_ = admin(ctx, s.dir); body.Fault_ != nil {
+		return body
+	}
+
+	p := s.dir.lookup(s.dir.id(req.GroupName), kindGroup)
+	if p == nil {
+		body.Fault_ = notFound(req.GroupName)
+		return body
+	}
+
+	p.group = req.GroupDetails
+	body.Res = new(types.UpdateLocalGroupDetailsResponse)
+
+	return body
+}
+
+func (s *PrincipalManagementService) ResetLocalPersonUserPassword(ctx *simulator.Context, req *types.ResetLocalPersonUserPassword) soap.HasFault {
+	body := new(methods.ResetLocalPersonUserPasswordBody)
+
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	if body.Fault_ = admin(ctx, s.dir); body.Fault_ != nil {
+		return body
+	}
+
+	p := s.dir.lookup(s.dir.id(req.UserName), kindPerson)
+	if p == nil {
+		body.Fault_ = notFound(req.UserName)
+		return body
+	}
+
+	p.password = req.NewPassword
+	body.Res = new(types.ResetLocalPersonUserPasswordResponse)
+
+	return body
+}
+
+func (s *PrincipalManagementService) DeleteLocalPrincipal(ctx *simulator.Context, req *types.DeleteLocalPrincipal) soap.HasFault {
+	body := new(methods.DeleteLocalPrincipalBody)
+
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	if body.Fault_ = admin(ctx, s.dir); body.Fault_ != nil {
+		return body
+	}
+
+	p := s.dir.lookup(s.dir.id(req.PrincipalName))
+	if p == nil {
+		body.Fault_ = notFound(req.PrincipalName)
+		return body
+	}
+
+	k := key(p.id)
+	delete(s.dir.principal, k)
+	for _, m := range s.dir.principal {
+		delete(m.parents, k)
+	}
+
+	body.Res = new(types.DeleteLocalPrincipalResponse)
+
+	return body
+}
+
+func (s *PrincipalManagementService) DisableUserAccount(ctx *simulator.Context, req *types.DisableUserAccount) soap.HasFault {
+	body := new(methods.DisableUserAccountBody)
+
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	if body.Fault_ = admin(ctx, s.dir); body.Fault_ != nil {
+		return body
+	}
+
+	p := s.dir.lookup(req.UserId, kindPerson, kindSolution)
+	if p == nil {
+		body.Fault_ = notFound(req.UserId.Name)
+		return body
+	}
+
+	body.Res = &types.DisableUserAccountResponse{Returnval: !p.disabled}
+	p.disabled = true
+
+	return body
+}
+
+func (s *PrincipalManagementService) EnableUserAccount(ctx *simulator.Context, req *types.EnableUserAccount) soap.HasFault {
+	body := new(methods.EnableUserAccountBody)
+
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	if body.Fault_ = admin(ctx, s.dir); body.Fault_ != nil {
+		return body
+	}
+
+	p := s.dir.lookup(req.UserId, kindPerson, kindSolution)
+	if p == nil {
+		body.Fault_ = notFound(req.UserId.Name)
+		return body
+	}
+
+	body.Res = &types.EnableUserAccountResponse{Returnval: p.disabled}
+	p.disabled = false
+
+	return body
+}
+
+// addToGroup adds the given principals to the local group, returning true for each principal that was not already a member.
+func (s *PrincipalManagementService) addToGroup(ctx *simulator.Context, group string, ids ...types.PrincipalId) ([]bool, *soap.Fault) {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	if fault := admin(ctx, s.dir); fault != nil {
+		return nil, fault
+	}
+
+	g := s.dir.lookup(s.dir.id(group), kindGroup)
+	if g == nil {
+		return nil, notFound(group)
+	}
+	k := key(g.id)
+
+	res := make([]bool, len(ids))
+	for i, id := range ids {
+		p := s.dir.lookup(id)
+		if p == nil {
+			return nil, notFound(id.Name)
+		}
+		if p == g {
+			return nil, simulator.Fault("group cannot be a member of itself", &vim.InvalidArgument{InvalidProperty: "groupId"})
+		}
+		res[i] = !p.parents[k]
+		p.parents[k] = true
+	}
+
+	return res, nil
+}
+
+func (s *PrincipalManagementService) AddUserToLocalGroup(ctx *simulator.Context, req *types.AddUserToLocalGroup) soap.HasFault {
+	body := new(methods.AddUserToLocalGroupBody)
+
+	res, fault := s.addToGroup(ctx, req.GroupName, req.UserId)
+	if fault != nil {
+		body.Fault_ = fault
+		return body
+	}
+
+	body.Res = &types.AddUserToLocalGroupResponse{Returnval: res[0]}
+
+	return body
+}
+
+func (s *PrincipalManagementService) AddUsersToLocalGroup(ctx *simulator.Context, req *types.AddUsersToLocalGroup) soap.HasFault {
+	body := new(methods.AddUsersToLocalGroupBody)
+
+	res, fault := s.addToGroup(ctx, req.GroupName, req.UserIds...)
+	if fault != nil {
+		body.Fault_ = fault
+		return body
+	}
+
+	body.Res = &types.AddUsersToLocalGroupResponse{Returnval: res}
+
+	return body
+}
+
+func (s *PrincipalManagementService) AddGroupToLocalGroup(ctx *simulator.Context, req *types.AddGroupToLocalGroup) soap.HasFault {
+	body := new(methods.AddGroupToLocalGroupBody)
+
+	res, fault := s.addToGroup(ctx, req.GroupName, req.GroupId)
+	if fault != nil {
+		body.Fault_ = fault
+		return body
+	}
+
+	body.Res = &types.AddGroupToLocalGroupResponse{Returnval: res[0]}
+
+	return body
+}
+
+// removeFromGroup removes the given principals from the local group, returning true for each principal that was a member.
+func (s *PrincipalManagementService) removeFromGroup(ctx *simulator.Context, group string, ids ...types.PrincipalId) ([]bool, *soap.Fault) {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	if fault := admin(ctx, s.dir); fault != nil {
+		return nil, fault
+	}
+
+	g := s.dir.lookup(s.dir.id(group), kindGroup)
+	if g == nil {
+		return nil, notFound(group)
+	}
+	k := key(g.id)
+
+	res := make([]bool, len(ids))
+	for i, id := range ids {
+		if p := s.dir.lookup(id); p != nil {
+			res[i] = p.parents[k]
+			delete(p.parents, k)
+		}
+	}
+
+	return res, nil
+}
+
+func (s *PrincipalManagementService) RemoveFromLocalGroup(ctx *simulator.Context, req *types.RemoveFromLocalGroup) soap.HasFault {
+	body := new(methods.RemoveFromLocalGroupBody)
+
+	res, fault := s.removeFromGroup(ctx, req.GroupName, req.PrincipalId)
+	if fault != nil {
+		body.Fault_ = fault
+		return body
+	}
+
+	body.Res = &types.RemoveFromLocalGroupResponse{Returnval: res[0]}
+
+	return body
+}
+
+func (s *PrincipalManagementService) RemovePrincipalsFromLocalGroup(ctx *simulator.Context, req *types.RemovePrincipalsFromLocalGroup) soap.HasFault {
+	body := new(methods.RemovePrincipalsFromLocalGroupBody)
+
+	res, fault := s.removeFromGroup(ctx, req.GroupName, req.PrincipalsIds...)
+	if fault != nil {
+		body.Fault_ = fault
+		return body
+	}
+
+	body.Res = &types.RemovePrincipalsFromLocalGroupResponse{Returnval: res}
+
+	return body
+}
+
+type RoleManagementService struct {
+	vim.ManagedObjectReference
+
+	dir *Directory
+}
+
+func (s *RoleManagementService) SetRole(ctx *simulator.Context, req *types.SetRole) soap.HasFault {
+	body := new(methods.SetRoleBody)
+
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	if body.Fault_ = admin(ctx, s.dir); body.Fault_ != nil {
+		return body
+	}
+
+	p := s.dir.lookup(req.UserId, kindPerson, kindSolution)
+	if p == nil {
+		body.Fault_ = notFound(req.UserId.Name)
+		return body
+	}
+
+	body.Res = &types.SetRoleResponse{Returnval: p.role != req.Role}
+	p.role = req.Role
+
+	return body
+}
+
+func (s *RoleManagementService) HasAdministratorRole(req *types.HasAdministratorRole) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	p := s.dir.lookup(req.UserId, kindPerson, kindSolution)
+
+	return &methods.HasAdministratorRoleBody{
+		Res: &types.HasAdministratorRoleResponse{
+			Returnval: p != nil && p.role == types.RoleAdministrator,
+		},
+	}
+}
+
+func (s *RoleManagementService) HasRegularUserRole(req *types.HasRegularUserRole) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	p := s.dir.lookup(req.UserId, kindPerson, kindSolution)
+
+	return &methods.HasRegularUserRoleBody{
+		Res: &types.HasRegularUserRoleResponse{
+			Returnval: p != nil && (p.role == types.RoleRegularUser || p.role == types.RoleAdministrator),
+		},
+	}
+}
+
+func (s *RoleManagementService) GrantWSTrustRole(ctx *simulator.Context, req *types.GrantWSTrustRole) soap.HasFault {
+	body := new(methods.GrantWSTrustRoleBody)
+
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	if body.Fault_ = admin(ctx, s.dir); body.Fault_ != nil {
+		return body
+	}
+
+	p := s.dir.lookup(req.UserId, kindPerson, kindSolution)
+	if p == nil {
+		body.Fault_ = notFound(req.UserId.Name)
+		return body
+	}
+
+	body.Res = &types.GrantWSTrustRoleResponse{Returnval: !p.wstrust[req.Role]}
+	p.wstrust[req.Role] = true
+
+	return body
+}
+
+func (s *RoleManagementService) RevokeWSTrustRole(ctx *simulator.Context, req *types.RevokeWSTrustRole) soap.HasFault {
+	body := new(methods.RevokeWSTrustRoleBody)
+
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	if body.Fault_ = admin(ctx, s.dir); body.Fault_ != nil {
+		return body
+	}
+
+	p := s.dir.lookup(req.UserId, kindPerson, kindSolution)
+	if p == nil {
+		body.Fault_ = notFound(req.UserId.Name)
+		return body
+	}
+
+	body.Res = &types.RevokeWSTrustRoleResponse{Returnval: p.wstrust[req.Role]}
+	delete(p.wstrust, req.Role)
+
+	return body
+}
+
+type GroupCheckService struct {
+	vim.ManagedObjectReference
+
+	dir *Directory
+}
+
+func (s *GroupCheckService) IsMemberOfGroup(req *types.IsMemberOfGroup) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	body := &methods.IsMemberOfGroupBody{Res: new(types.IsMemberOfGroupResponse)}
+
+	if p := s.dir.lookup(req.UserId); p != nil {
+		k := key(req.GroupId)
+		for _, g := range s.dir.parentGroups(p, true) {
+			if key(g.id) == k {
+				body.Res.Returnval = true
+				break
+			}
+		}
+	}
+
+	return body
+}
+
+func (s *GroupCheckService) FindParentGroups(req *types.FindParentGroups) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	body := &methods.FindParentGroupsBody{Res: new(types.FindParentGroupsResponse)}
+
+	p := s.dir.lookup(req.UserId)
+	if p == nil {
+		return body
+	}
+
+	groups := make(map[string]bool)
+	for _, g := range s.dir.parentGroups(p, true) {
+		groups[key(g.id)] = true
+	}
+
+	for _, id := range req.GroupList {
+		if groups[key(id)] {
+			body.Res.Returnval = append(body.Res.Returnval, id)
+		}
+	}
+
+	return body
+}
+
+func (s *GroupCheckService) FindAllParentGroups(req *types.FindAllParentGroups) soap.HasFault {
+	s.dir.mu.Lock()
+	defer s.dir.mu.Unlock()
+
+	body := &methods.FindAllParentGroupsBody{Res: new(types.FindAllParentGroupsResponse)}
+
+	if p := s.dir.lookup(req.UserId); p != nil {
+		for _, g := range s.dir.parentGroups(p, true) {
+			body.Res.Returnval = append(body.Res.Returnval, g.id)
+		}
+	}
+
+	return body
+}
@@ -0,0 +1,297 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulator
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	lsim "github.com/vmware/govmomi/lookup/simulator"
+	"github.com/vmware/govmomi/session"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/ssoadmin"
+	"github.com/vmware/govmomi/ssoadmin/types"
+	"github.com/vmware/govmomi/sts"
+	stsim "github.com/vmware/govmomi/sts/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+func newCertificate(t *testing.T) *tls.Certificate {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "govmomi-test"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// login issues a token for the given request and uses it to login to the SSO admin server.
+func login(ctx context.Context, t *testing.T, c *vim25.Client, req sts.TokenRequest) (*ssoadmin.Client, *sts.Signer) {
+	tokens, err := sts.NewClient(ctx, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := tokens.Issue(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admin, err := ssoadmin.NewClient(ctx, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := soap.Header{Security: signer}
+	if err = admin.Login(admin.WithHeader(ctx, header)); err != nil {
+		t.Fatal(err)
+	}
+
+	return admin, signer
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	model := simulator.VPX()
+
+	defer model.Remove()
+	err := model.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model.Service.TLS = new(tls.Config) // STS endpoint is https
+	s := model.Service.NewServer()
+	defer s.Close()
+
+	settings := simulator.Map.OptionManager().Setting
+
+	registry, dir := New(s.URL, settings)
+	model.Service.RegisterSDK(registry)
+
+	path, handler := stsim.New(s.URL, settings)
+	handler.Authenticator = dir
+	model.Service.ServeMux.Handle(path, handler)
+
+	model.Service.RegisterSDK(lsim.New())
+
+	c, err := vim25.NewClient(ctx, soap.NewClient(s.URL, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = ssoadmin.NewClient(ctx, c); err != nil {
+		t.Fatal(err) // ServiceInstance methods do not require authentication
+	}
+
+	tokens, err := sts.NewClient(ctx, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = tokens.Issue(ctx, sts.TokenRequest{Userinfo: url.UserPassword("user", "enoent")})
+	if err == nil {
+		t.Error("expected error")
+	}
+
+	admin, _ := login(ctx, t, c, sts.TokenRequest{Userinfo: s.URL.User})
+
+	details := types.AdminPersonDetails{Description: "govmomi test user"}
+	if err = admin.CreatePersonUser(ctx, "govmomi", details, "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = admin.CreatePersonUser(ctx, "govmomi", details, "password"); err == nil {
+		t.Error("expected error") // AlreadyExists
+	}
+
+	user, err := admin.FindPersonUser(ctx, "govmomi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user == nil || user.Details.Description != details.Description {
+		t.Fatalf("user=%#v", user)
+	}
+
+	details.EmailAddress = "govmomi@vsphere.local"
+	if err = admin.UpdatePersonUser(ctx, "govmomi", details); err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := admin.FindUser(ctx, "govmomi@vsphere.local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u == nil || u.Kind != kindPerson {
+		t.Fatalf("user=%#v", u)
+	}
+
+	groups, err := admin.FindParentGroups(ctx, u.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0].Name != "Users" {
+		t.Errorf("groups=%#v", groups)
+	}
+
+	if err = admin.ResetPersonPassword(ctx, "govmomi", "changed"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A person user can login to vCenter using a token issued with its credentials
+	_, err = tokens.Issue(ctx, sts.TokenRequest{Userinfo: url.UserPassword("govmomi", "password")})
+	if err == nil {
+		t.Error("expected error") // password was reset
+	}
+
+	user1, signer := login(ctx, t, c, sts.TokenRequest{Userinfo: url.UserPassword("govmomi", "changed")})
+
+	if err = user1.CreatePersonUser(ctx, "enoent", details, "password"); err == nil {
+		t.Error("expected error") // Administrator role required
+	}
+
+	vc, err := vim25.NewClient(ctx, soap.NewClient(s.URL, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := session.NewManager(vc)
+	if err = m.LoginByToken(vc.WithHeader(ctx, soap.Header{Security: signer})); err != nil {
+		t.Fatal(err)
+	}
+
+	us, err := m.UserSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if us.UserName != "govmomi@VSPHERE.LOCAL" {
+		t.Errorf("session user=%s", us.UserName)
+	}
+
+	// A solution user can be issued a HoK token using its certificate
+	cert := newCertificate(t)
+	solution := types.AdminSolutionDetails{
+		Certificate: base64.StdEncoding.EncodeToString(cert.Certificate[0]),
+	}
+
+	if err = admin.CreateSolutionUser(ctx, "govmomi-solution", solution); err != nil {
+		t.Fatal(err)
+	}
+
+	id := types.PrincipalId{Name: "govmomi-solution", Domain: admin.Domain}
+
+	for _, role := range []bool{true, false} {
+		ok, err := admin.GrantWSTrustRole(ctx, id, types.RoleActAsUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != role {
+			t.Errorf("GrantWSTrustRole=%t", ok)
+		}
+	}
+
+	if ok, _ := admin.SetRole(ctx, id, types.RoleAdministrator); !ok {
+		t.Error("SetRole")
+	}
+
+	solutionAdmin, _ := login(ctx, t, c, sts.TokenRequest{Certificate: cert})
+
+	// ActAs requests must authenticate the requester
+	_, err = tokens.Issue(ctx, sts.TokenRequest{Userinfo: url.UserPassword("user", "enoent"), ActAs: true, Token: signer.Token})
+	if err == nil {
+		t.Error("expected error")
+	}
+
+	if _, err = tokens.Issue(ctx, sts.TokenRequest{Certificate: cert, ActAs: true, Token: signer.Token}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Subject names are escaped in the issued token
+	if err = admin.CreatePersonUser(ctx, "<govmomi&>", details, "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err = tokens.Issue(ctx, sts.TokenRequest{Userinfo: url.UserPassword("<govmomi&>", "password")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vc, err = vim25.NewClient(ctx, soap.NewClient(s.URL, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m = session.NewManager(vc)
+	if err = m.LoginByToken(vc.WithHeader(ctx, soap.Header{Security: signer})); err != nil {
+		t.Fatal(err)
+	}
+
+	us, err = m.UserSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if us.UserName != "<govmomi&>@VSPHERE.LOCAL" {
+		t.Errorf("session user=%s", us.UserName)
+	}
+
+	if err = solutionAdmin.DeletePrincipal(ctx, "govmomi"); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err = admin.FindPersonUser(ctx, "govmomi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user != nil {
+		t.Errorf("user=%#v", user)
+	}
+
+	if err = solutionAdmin.DeletePrincipal(ctx, "govmomi"); err == nil {
+		t.Error("expected error") // NotFound
+	}
+
+	if err = admin.Logout(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = admin.FindUser(ctx, "Administrator"); err == nil {
+		t.Error("expected error") // NotAuthenticated
+	}
+}
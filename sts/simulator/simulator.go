@@ -17,21 +17,45 @@ limitations under the License.
 package simulator
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
+	"text/template"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/vmware/govmomi/sts/internal"
 	"github.com/vmware/govmomi/vim25/soap"
 	vim "github.com/vmware/govmomi/vim25/types"
+	"github.com/vmware/govmomi/vim25/xml"
 )
 
+// Authenticator validates the credentials of Issue requests.
+// See ssoadmin/simulator for an implementation.
+type Authenticator interface {
+	// AuthenticateUser returns the principal ID of the user with the given name and password.
+	AuthenticateUser(name, password string) (string, error)
+
+	// AuthenticateCertificate returns the principal ID of the solution user with the given DER encoded certificate.
+	AuthenticateCertificate(cert []byte) (string, error)
+}
+
+// Handler implements the STS simulator http.Handler.
+type Handler struct {
+	// Authenticator is used to authenticate Issue requests.
+	// If nil, any credentials are accepted and tokens are issued for the Administrator user.
+	Authenticator Authenticator
+}
+
 // New creates an STS simulator and configures the simulator endpoint in the given settings.
 // The path returned is that of the settings "config.vpxd.sso.sts.uri" property.
-func New(u *url.URL, settings []vim.BaseOptionValue) (string, http.Handler) {
+func New(u *url.URL, settings []vim.BaseOptionValue) (string, *Handler) {
 	for i := range settings {
 		setting := settings[i].GetOptionValue()
 		if setting.Key == "config.vpxd.sso.sts.uri" {
@@ -39,60 +63,249 @@ func New(u *url.URL, settings []vim.BaseOptionValue) (string, http.Handler) {
 			endpoint.Host = u.Host
 			setting.Value = endpoint.String()
 			settings[i] = setting
-			return endpoint.Path, new(handler)
+			return endpoint.Path, new(Handler)
 		}
 	}
 	return "", nil
 }
 
-type handler struct{}
+// request is the subset of a RequestSecurityToken envelope used by the simulator.
+// Note that namespaces are omitted from the field tags, as the request prefixes vary.
+type request struct {
+	Header struct {
+		Security struct {
+			BinarySecurityToken string
+			UsernameToken       struct {
+				Username string
+				Password string
+			}
+		}
+	}
+	Body struct {
+		RequestSecurityToken struct {
+			Lifetime struct {
+				Created string
+				Expires string
+			}
+			Renewing struct {
+				Allow bool `xml:",attr"`
+			}
+			Delegatable bool
+			ActAs       *internal.Target
+			RenewTarget *internal.Target
+		}
+	}
+}
+
+// assertion contains the fields of the token template
+type assertion struct {
+	ID           string
+	IssueInstant string
+	Created      string
+	Expires      string
+	Subject      string
+	Certificate  string
+	Solution     bool
+	Renewable    bool
+	Delegatable  bool
+	Delegate     string
+}
+
+// subject returns the Assertion.Subject.NameID of the given token
+func subject(token string) string {
+	var a internal.Assertion
+	if err := internal.Unmarshal([]byte(token), &a); err != nil {
+		return ""
+	}
+	return a.Subject.NameID.ID
+}
+
+// renewable returns true if the given token has a RenewRestriction condition
+func renewable(token string) bool {
+	var a internal.Assertion
+	if err := internal.Unmarshal([]byte(token), &a); err != nil {
+		return false
+	}
+	for _, c := range a.Conditions.Condition {
+		if _, ok := c.(*internal.RenewRestriction); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate sets the token Subject for the given request.
+func (s *Handler) authenticate(action string, req *request, info *assertion) error {
+	rst := &req.Body.RequestSecurityToken
+
+	switch {
+	case action == "Renew":
+		if rst.RenewTarget == nil {
+			return fmt.Errorf("RenewTarget is required")
+		}
+		if !renewable(rst.RenewTarget.Token) {
+			return fmt.Errorf("token is not renewable")
+		}
+		info.Subject = subject(rst.RenewTarget.Token)
+		info.Renewable = true // Renew requests do not include the Renewing element, the restriction is retained
+	case rst.ActAs != nil && strings.TrimSpace(rst.ActAs.Token) != "":
+		// Delegated token, the solution user acts on behalf of the ActAs token subject
+		info.Subject = subject(rst.ActAs.Token)
+		if s.Authenticator != nil {
+			var err error
+			info.Delegate, _, err = s.principal(req)
+			if err != nil {
+				return err
+			}
+		}
+	case s.Authenticator == nil:
+		info.Subject = "Administrator@VSPHERE.LOCAL"
+	default:
+		var err error
+		info.Subject, info.Solution, err = s.principal(req)
+		if err != nil {
+			return err
+		}
+	}
+
+	if info.Subject == "" {
+		return fmt.Errorf("invalid token subject")
+	}
+
+	return nil
+}
+
+// principal authenticates the request's BinarySecurityToken or UsernameToken,
+// returning the principal ID and true if the principal is a solution user.
+func (s *Handler) principal(req *request) (string, bool, error) {
+	security := &req.Header.Security
+
+	if security.BinarySecurityToken != "" {
+		cert, err := base64.StdEncoding.DecodeString(security.BinarySecurityToken)
+		if err != nil {
+			return "", false, err
+		}
+		id, err := s.Authenticator.AuthenticateCertificate(cert)
+		return id, true, err
+	}
+
+	id, err := s.Authenticator.AuthenticateUser(security.UsernameToken.Username, security.UsernameToken.Password)
+	return id, false, err
+}
+
+// lifetime returns the token lifetime for the given request, defaults to 5 minutes.
+func lifetime(req *request) *internal.Lifetime {
+	now := time.Now().UTC()
+	duration := 5 * time.Minute
+
+	l := &req.Body.RequestSecurityToken.Lifetime
+	created, cerr := time.Parse(internal.Time, l.Created)
+	expires, eerr := time.Parse(internal.Time, l.Expires)
+	if cerr == nil && eerr == nil && expires.After(created) {
+		duration = expires.Sub(created)
+	}
+
+	return &internal.Lifetime{
+		Created: now.Format(internal.Time),
+		Expires: now.Add(duration).Format(internal.Time),
+	}
+}
+
+func fault(w http.ResponseWriter, env soap.Envelope, err error) {
+	env.Body = internal.RequestSecurityTokenBody{
+		Fault_: &soap.Fault{
+			Code:   "ns0:FailedAuthentication",
+			String: err.Error(),
+		},
+	}
+	w.WriteHeader(http.StatusInternalServerError)
+	fmt.Fprint(w, internal.Marshal(env))
+}
 
 // ServeHTTP handles STS requests.
-func (s *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	action := r.Header.Get("SOAPAction")
+func (s *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	action := path.Base(r.Header.Get("SOAPAction"))
 	env := soap.Envelope{}
-	now := time.Now()
-	lifetime := &internal.Lifetime{
-		Created: now.Format(internal.Time),
-		Expires: now.Add(5 * time.Minute).Format(internal.Time),
+
+	switch action {
+	case "Issue", "Renew":
+	default:
+		log.Printf("sts: unsupported action=%s", action)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var req request
+	body, err := ioutil.ReadAll(r.Body)
+	if err == nil {
+		err = xml.Unmarshal(body, &req)
+	}
+	if err != nil {
+		log.Printf("sts: decoding %s request: %s", action, err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	life := lifetime(&req)
+	info := assertion{
+		ID:           "_" + uuid.New().String(),
+		IssueInstant: life.Created,
+		Created:      life.Created,
+		Expires:      life.Expires,
+		Certificate:  req.Header.Security.BinarySecurityToken,
+		Renewable:    req.Body.RequestSecurityToken.Renewing.Allow,
+		Delegatable:  req.Body.RequestSecurityToken.Delegatable,
+	}
+
+	if err = s.authenticate(action, &req, &info); err != nil {
+		fault(w, env, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = token.Execute(&buf, info); err != nil {
+		log.Printf("sts: %s", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
 
-	switch path.Base(action) {
+	rst := internal.RequestedSecurityToken{
+		Assertion: strings.TrimSpace(buf.String()),
+	}
+
+	switch action {
 	case "Issue":
-		body := internal.RequestSecurityTokenBody{
+		env.Body = internal.RequestSecurityTokenBody{
 			Res: &internal.RequestSecurityTokenResponseCollection{
 				RequestSecurityTokenResponse: internal.RequestSecurityTokenResponse{
-					RequestedSecurityToken: internal.RequestedSecurityToken{
-						Assertion: token,
-					},
-					Lifetime: lifetime,
+					RequestedSecurityToken: rst,
+					Lifetime:               life,
 				},
 			},
 		}
-		env.Body = body
 	case "Renew":
-		body := internal.RenewSecurityTokenBody{
+		env.Body = internal.RenewSecurityTokenBody{
 			Res: &internal.RequestSecurityTokenResponse{
-				RequestedSecurityToken: internal.RequestedSecurityToken{
-					Assertion: token,
-				},
-				Lifetime: lifetime,
+				RequestedSecurityToken: rst,
+				Lifetime:               life,
 			},
 		}
-		env.Body = body
-	default:
-		log.Printf("sts: unsupported action=%s", action)
-		w.WriteHeader(http.StatusNotFound)
-		return
 	}
 
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprint(w, internal.Marshal(env))
 }
 
-// Currently simulator.SessionManager.LoginByToken() only checks for a non-empty Assertion.Subject.NameID field,
-// so the token below is returned by Issue and Renew requests for now.
-var token = `<saml2:Assertion xmlns:saml2="urn:oasis:names:tc:SAML:2.0:assertion" xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" ID="_1881a9ba-4a76-4baa-839b-36e2cba10743" IssueInstant="2018-03-04T00:27:56.409Z" Version="2.0"><saml2:Issuer Format="urn:oasis:names:tc:SAML:2.0:nameid-format:entity">https://office1-sfo2-dhcp221.eng.vmware.com/websso/SAML2/Metadata/vsphere.local</saml2:Issuer><ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:SignedInfo><ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/><ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"/><ds:Reference URI="#_1881a9ba-4a76-4baa-839b-36e2cba10743"><ds:Transforms><ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"/><ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"><ec:InclusiveNamespaces xmlns:ec="http://www.w3.org/2001/10/xml-exc-c14n#" PrefixList="xs xsi"/></ds:Transform></ds:Transforms><ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"/><ds:DigestValue>l/0AzCGiPB69oTstUdrCkihBIDtwb83A93zAe10tG3k=</ds:DigestValue></ds:Reference></ds:SignedInfo><ds:SignatureValue>EKHf14V0CHctwqXRlhYSYNyID5lNJLimbw57eUBm/QlAMLY7GJ1wth44oeQPSj3eMpJaXKHEYYtn
+// escape returns the given value with XML special characters escaped, for use in the token template.
+func escape(s string) (string, error) {
+	var buf bytes.Buffer
+	err := xml.EscapeText(&buf, []byte(s))
+	return buf.String(), err
+}
+
+// token is the template for assertions returned by Issue and Renew requests.
+// The signature is not valid, simulator.SessionManager.LoginByToken() only checks for a non-empty Assertion.Subject.NameID field.
+var token = template.Must(template.New("token").Funcs(template.FuncMap{"escape": escape}).Parse(`<saml2:Assertion xmlns:saml2="urn:oasis:names:tc:SAML:2.0:assertion" xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" ID="{{.ID}}" IssueInstant="{{.IssueInstant}}" Version="2.0"><saml2:Issuer Format="urn:oasis:names:tc:SAML:2.0:nameid-format:entity">https://office1-sfo2-dhcp221.eng.vmware.com/websso/SAML2/Metadata/vsphere.local</saml2:Issuer><ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:SignedInfo><ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/><ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"/><ds:Reference URI="#{{.ID}}"><ds:Transforms><ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"/><ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"><ec:InclusiveNamespaces xmlns:ec="http://www.w3.org/2001/10/xml-exc-c14n#" PrefixList="xs xsi"/></ds:Transform></ds:Transforms><ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"/><ds:DigestValue>l/0AzCGiPB69oTstUdrCkihBIDtwb83A93zAe10tG3k=</ds:DigestValue></ds:Reference></ds:SignedInfo><ds:SignatureValue>EKHf14V0CHctwqXRlhYSYNyID5lNJLimbw57eUBm/QlAMLY7GJ1wth44oeQPSj3eMpJaXKHEYYtn
 fqMngciTrq4ZP2SS7KizxuBjcHChWGmcp+t0zn7+fTbp5sL8HfF3AfOwcyZxwj8n2S7E6Eee7zeC
 cjZpKKZ1QIEwASwpuMCs7vU9IuXsUguHAaN55Jpx3N5u7PlSo/NZE0TJZ+zNWP8m9H5shPDY272D
 Vnp3MGfoD+Dj6T4H8OVF6bMp6czbHsEHTthwPh+pBTzR8ppkyxPKWLkC7OWiOtZBKqLSMTchQyqn
@@ -131,4 +344,4 @@ vbxa1eFjLFYuBsGBqhPEHkHkdKwgpfo1sd4t0L7JaGS9wsH6zyRUQs97subV5YUI6rvAPOBGDQTm
 RmCeqz3ODZq6JwZEnTTqZjvUVckmt/L/QaRUHAW27MU+SuN8rP0Nghf/gkOabsaWfyT2ADquko4e
 b7seYIlR5mJs+pxVBBsBB2nzxuaV5EjkgestxBqpGkxMnKEDhG6+VjqVxsZoEiNzdBNU7eM67Jc2
 2KU85jHKAao9LfMbwbHOA//1RStXXElyzPQvecq17ATvpw8AxCRu2KeKRwp3Pm2RiquDQFx8aiCe
-2Re4gkrEemA=</ds:X509Certificate></ds:X509Data></ds:KeyInfo></ds:Signature><saml2:Subject><saml2:NameID Format="http://schemas.xmlsoap.org/claims/UPN">Administrator@VSPHERE.LOCAL</saml2:NameID><saml2:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer"><saml2:SubjectConfirmationData NotOnOrAfter="2018-03-04T00:27:01.401Z"/></saml2:SubjectConfirmation></saml2:Subject><saml2:Conditions NotBefore="2018-03-04T00:22:01.401Z" NotOnOrAfter="2018-03-04T00:27:01.401Z"><saml2:ProxyRestriction Count="10"/></saml2:Conditions><saml2:AuthnStatement AuthnInstant="2018-03-04T00:27:56.402Z"><saml2:AuthnContext><saml2:AuthnContextClassRef>urn:oasis:names:tc:SAML:2.0:ac:classes:PasswordProtectedTransport</saml2:AuthnContextClassRef></saml2:AuthnContext></saml2:AuthnStatement><saml2:AttributeStatement><saml2:Attribute FriendlyName="Groups" Name="http://rsa.com/schemas/attr-names/2009/01/GroupIdentity" NameFormat="urn:oasis:names:tc:SAML:2.0:attrname-format:uri"><saml2:AttributeValue xsi:type="xs:string">vsphere.local\Users</saml2:AttributeValue><saml2:AttributeValue xsi:type="xs:string">vsphere.local\Administrators</saml2:AttributeValue><saml2:AttributeValue xsi:type="xs:string">vsphere.local\CAAdmins</saml2:AttributeValue><saml2:AttributeValue xsi:type="xs:string">vsphere.local\ComponentManager.Administrators</saml2:AttributeValue><saml2:AttributeValue xsi:type="xs:string">vsphere.local\SystemConfiguration.BashShellAdministrators</saml2:AttributeValue><saml2:AttributeValue xsi:type="xs:string">vsphere.local\SystemConfiguration.Administrators</saml2:AttributeValue><saml2:AttributeValue xsi:type="xs:string">vsphere.local\LicenseService.Administrators</saml2:AttributeValue><saml2:AttributeValue xsi:type="xs:string">vsphere.local\ActAsUsers</saml2:AttributeValue><saml2:AttributeValue xsi:type="xs:string">vsphere.local\Everyone</saml2:AttributeValue></saml2:Attribute><saml2:Attribute FriendlyName="givenName" Name="http://schemas.xmlsoap.org/ws/2005/05/identity/claims/givenname" NameFormat="urn:oasis:names:tc:SAML:2.0:attrname-format:uri"><saml2:AttributeValue xsi:type="xs:string">Administrator</saml2:AttributeValue></saml2:Attribute><saml2:Attribute FriendlyName="surname" Name="http://schemas.xmlsoap.org/ws/2005/05/identity/claims/surname" NameFormat="urn:oasis:names:tc:SAML:2.0:attrname-format:uri"><saml2:AttributeValue xsi:type="xs:string">vsphere.local</saml2:AttributeValue></saml2:Attribute><saml2:Attribute FriendlyName="Subject Type" Name="http://vmware.com/schemas/attr-names/2011/07/isSolution" NameFormat="urn:oasis:names:tc:SAML:2.0:attrname-format:uri"><saml2:AttributeValue xsi:type="xs:string">false</saml2:AttributeValue></saml2:Attribute></saml2:AttributeStatement></saml2:Assertion>`
+2Re4gkrEemA=</ds:X509Certificate></ds:X509Data></ds:KeyInfo></ds:Signature><saml2:Subject><saml2:NameID Format="http://schemas.xmlsoap.org/claims/UPN">{{escape .Subject}}</saml2:NameID><saml2:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:{{if .Certificate}}holder-of-key{{else}}bearer{{end}}">{{if .Certificate}}<saml2:SubjectConfirmationData xsi:type="saml2:KeyInfoConfirmationDataType"><ds:KeyInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:X509Data><ds:X509Certificate>{{escape .Certificate}}</ds:X509Certificate></ds:X509Data></ds:KeyInfo></saml2:SubjectConfirmationData>{{else}}<saml2:SubjectConfirmationData NotOnOrAfter="{{.Expires}}"/>{{end}}</saml2:SubjectConfirmation></saml2:Subject><saml2:Conditions NotBefore="{{.Created}}" NotOnOrAfter="{{.Expires}}">{{if .Delegatable}}<saml2:ProxyRestriction Count="10"/>{{end}}{{if .Delegate}}<saml2:Condition xmlns:del="http://www.rsa.com/names/2009/12/std-ext/SAML2.0" xsi:type="del:DelegationRestrictionType"><del:Delegate DelegationInstant="{{.IssueInstant}}"><saml2:NameID Format="http://schemas.xmlsoap.org/claims/UPN">{{escape .Delegate}}</saml2:NameID></del:Delegate></saml2:Condition>{{end}}{{if .Renewable}}<saml2:Condition xmlns:rsa="http://www.rsa.com/names/2009/12/std-ext/SAML2.0" Count="10" xsi:type="rsa:RenewRestrictionType"/>{{end}}</saml2:Conditions><saml2:AuthnStatement AuthnInstant="{{.IssueInstant}}"><saml2:AuthnContext><saml2:AuthnContextClassRef>urn:oasis:names:tc:SAML:2.0:ac:classes:PasswordProtectedTransport</saml2:AuthnContextClassRef></saml2:AuthnContext></saml2:AuthnStatement><saml2:AttributeStatement><saml2:Attribute FriendlyName="Groups" Name="http://rsa.com/schemas/attr-names/2009/01/GroupIdentity" NameFormat="urn:oasis:names:tc:SAML:2.0:attrname-format:uri"><saml2:AttributeValue xsi:type="xs:string">vsphere.local\Users</saml2:AttributeValue><saml2:AttributeValue xsi:type="xs:string">vsphere.local\Administrators</saml2:AttributeValue><saml2:AttributeValue xsi:type="xs:string">vsphere.local\CAAdmins</saml2:AttributeValue><saml2:AttributeValue xsi:type="xs:string">vsphere.local\ComponentManager.Administrators</saml2:AttributeValue><saml2:AttributeValue xsi:type="xs:string">vsphere.local\SystemConfiguration.BashShellAdministrators</saml2:AttributeValue><saml2:AttributeValue xsi:type="xs:string">vsphere.local\SystemConfiguration.Administrators</saml2:AttributeValue><saml2:AttributeValue xsi:type="xs:string">vsphere.local\LicenseService.Administrators</saml2:AttributeValue><saml2:AttributeValue xsi:type="xs:string">vsphere.local\ActAsUsers</saml2:AttributeValue><saml2:AttributeValue xsi:type="xs:string">vsphere.local\Everyone</saml2:AttributeValue></saml2:Attribute><saml2:Attribute FriendlyName="givenName" Name="http://schemas.xmlsoap.org/ws/2005/05/identity/claims/givenname" NameFormat="urn:oasis:names:tc:SAML:2.0:attrname-format:uri"><saml2:AttributeValue xsi:type="xs:string">Administrator</saml2:AttributeValue></saml2:Attribute><saml2:Attribute FriendlyName="surname" Name="http://schemas.xmlsoap.org/ws/2005/05/identity/claims/surname" NameFormat="urn:oasis:names:tc:SAML:2.0:attrname-format:uri"><saml2:AttributeValue xsi:type="xs:string">vsphere.local</saml2:AttributeValue></saml2:Attribute><saml2:Attribute FriendlyName="Subject Type" Name="http://vmware.com/schemas/attr-names/2011/07/isSolution" NameFormat="urn:oasis:names:tc:SAML:2.0:attrname-format:uri"><saml2:AttributeValue xsi:type="xs:string">{{.Solution}}</saml2:AttributeValue></saml2:Attribute></saml2:AttributeStatement></saml2:Assertion>`))
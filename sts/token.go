@@ -0,0 +1,86 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sts
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/sts/internal"
+)
+
+// TokenInfo contains details of a SAML token, as decoded from the token's Assertion.
+type TokenInfo struct {
+	ID           string    // ID is the Assertion ID
+	Issuer       string    // Issuer is the token issuer
+	Subject      string    // Subject is the Assertion.Subject.NameID
+	Confirmation string    // Confirmation is the subject confirmation method, "bearer" or "holder-of-key"
+	NotBefore    time.Time // NotBefore is the start of the token's validity period
+	NotOnOrAfter time.Time // NotOnOrAfter is the end of the token's validity period
+	Renewable    bool      // Renewable is true if the token has a RenewRestriction condition
+	Delegatable  bool      // Delegatable is true if the token has a ProxyRestriction condition
+	Delegates    []string  // Delegates is the DelegationRestriction chain of an ActAs token
+	Groups       []string  // Groups is the list of the subject's group membership
+}
+
+// ParseToken decodes the given SAML token.
+// Note that the token signature is not validated.
+func ParseToken(token string) (*TokenInfo, error) {
+	var a internal.Assertion
+	if err := internal.Unmarshal([]byte(token), &a); err != nil {
+		return nil, fmt.Errorf("decoding SAML token: %s", err)
+	}
+
+	info := &TokenInfo{
+		ID:           a.ID,
+		Issuer:       a.Issuer.Value,
+		Subject:      a.Subject.NameID.ID,
+		Confirmation: strings.TrimPrefix(a.Subject.SubjectConfirmation.Method, "urn:oasis:names:tc:SAML:2.0:cm:"),
+		Delegatable:  a.Conditions.ProxyRestriction != nil,
+	}
+
+	// Time format is that of internal.Time, but with optional fractional seconds
+	info.NotBefore, _ = time.Parse(time.RFC3339, a.Conditions.NotBefore)
+	info.NotOnOrAfter, _ = time.Parse(time.RFC3339, a.Conditions.NotOnOrAfter)
+
+	for _, c := range a.Conditions.Condition {
+		switch r := c.(type) {
+		case *internal.RenewRestriction:
+			info.Renewable = true
+		case *internal.DelegateRestriction:
+			info.Delegates = append(info.Delegates, r.Delegate.NameID.ID)
+		}
+	}
+
+	for _, attr := range a.AttributeStatement.Attribute {
+		if attr.FriendlyName != "Groups" {
+			continue
+		}
+		for _, val := range attr.AttributeValue {
+			info.Groups = append(info.Groups, val.Value)
+		}
+	}
+
+	return info, nil
+}
+
+// Valid returns true if the token validity period includes the current time.
+func (t *TokenInfo) Valid() bool {
+	now := time.Now()
+	return !now.Before(t.NotBefore) && now.Before(t.NotOnOrAfter)
+}
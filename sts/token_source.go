@@ -0,0 +1,137 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sts
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/vmware/govmomi/session"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// TokenSource provides a Signer with a valid SAML token, acquiring a new token before the current token expires.
+type TokenSource struct {
+	Client  *Client
+	Request TokenRequest // Request is used to issue the initial token and any tokens that follow
+
+	// Margin is the duration before the current token expires at which a new token is acquired.
+	// Defaults to 1/5 of the current token's lifetime.
+	Margin time.Duration
+
+	mu     sync.Mutex
+	signer *Signer
+}
+
+// NewTokenSource returns a TokenSource using the given client and request.
+func NewTokenSource(c *Client, req TokenRequest) *TokenSource {
+	return &TokenSource{
+		Client:  c,
+		Request: req,
+	}
+}
+
+func (ts *TokenSource) margin(s *Signer) time.Duration {
+	if ts.Margin != 0 {
+		return ts.Margin
+	}
+	return s.Lifetime.Expires.Sub(s.Lifetime.Created) / 5
+}
+
+// Signer returns a Signer with a valid token.
+// When the current token expires within Margin, the token is renewed if Request.Renewable is true and
+// Request.Certificate is set, as Renew requests must be signed with the token's key.
+// Otherwise, or if the Renew request fails, a new token is issued.
+func (ts *TokenSource) Signer(ctx context.Context) (*Signer, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	now := time.Now()
+
+	if s := ts.signer; s != nil {
+		if now.Add(ts.margin(s)).Before(s.Lifetime.Expires) {
+			return s, nil
+		}
+
+		if ts.Request.Renewable && ts.Request.Certificate != nil && now.Before(s.Lifetime.Expires) {
+			req := ts.Request
+			req.Token = s.Token
+
+			if r, err := ts.Client.Renew(ctx, req); err == nil {
+				ts.signer = r
+				return r, nil
+			}
+			// Renew count may have been exceeded, fallthrough to Issue
+		}
+	}
+
+	s, err := ts.Client.Issue(ctx, ts.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	ts.signer = s
+	return s, nil
+}
+
+// Login authenticates the given client session via LoginByToken, using a token from the TokenSource.
+// The client's RoundTripper is wrapped using session.Relogin, such that when a request fails with a NotAuthenticated fault,
+// as when the session has expired, the session is authenticated again using a valid token and the request retried.
+func (ts *TokenSource) Login(ctx context.Context, c *vim25.Client) error {
+	login := func(ctx context.Context) error {
+		s, err := ts.Signer(ctx)
+		if err != nil {
+			return err
+		}
+
+		header := soap.Header{Security: s}
+
+		return session.NewManager(c).LoginByToken(c.WithHeader(ctx, header))
+	}
+
+	if err := login(ctx); err != nil {
+		return err
+	}
+
+	rt, ok := c.RoundTripper.(*tokenRoundTripper)
+	if ok && rt.ts == ts {
+		return nil
+	}
+
+	next := c.RoundTripper
+	if ok {
+		next = rt.next // replace the login of another TokenSource
+	}
+
+	c.RoundTripper = &tokenRoundTripper{
+		RoundTripper: session.Relogin(next, login),
+		ts:           ts,
+		next:         next,
+	}
+
+	return nil
+}
+
+// tokenRoundTripper marks a client RoundTripper as wrapped by TokenSource.Login
+type tokenRoundTripper struct {
+	soap.RoundTripper
+
+	ts   *TokenSource
+	next soap.RoundTripper
+}
@@ -0,0 +1,188 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sts
+
+import (
+	"context"
+	"crypto/tls"
+	"net/url"
+	"testing"
+	"time"
+
+	lsim "github.com/vmware/govmomi/lookup/simulator"
+	"github.com/vmware/govmomi/session"
+	"github.com/vmware/govmomi/simulator"
+	stsim "github.com/vmware/govmomi/sts/simulator"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// authenticator accepts any user credentials and authenticates any certificate as solution user "govmomi-test"
+type authenticator struct{}
+
+func (authenticator) AuthenticateUser(name, _ string) (string, error) {
+	return name + "@VSPHERE.LOCAL", nil
+}
+
+func (authenticator) AuthenticateCertificate(_ []byte) (string, error) {
+	return "govmomi-test@VSPHERE.LOCAL", nil
+}
+
+func TestTokenSource(t *testing.T) {
+	ctx := context.Background()
+
+	model := simulator.VPX()
+
+	defer model.Remove()
+	err := model.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model.Service.TLS = new(tls.Config) // STS endpoint is https
+	s := model.Service.NewServer()
+	defer s.Close()
+
+	path, handler := stsim.New(s.URL, simulator.Map.OptionManager().Setting)
+	handler.Authenticator = authenticator{}
+	model.Service.ServeMux.Handle(path, handler)
+
+	model.Service.RegisterSDK(lsim.New())
+
+	c, err := vim25.NewClient(ctx, soap.NewClient(s.URL, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := NewClient(ctx, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bearer, err := tokens.Issue(ctx, TokenRequest{
+		Userinfo:    url.UserPassword("user", "pass"),
+		Delegatable: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := ParseToken(bearer.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Subject != "user@VSPHERE.LOCAL" || info.Confirmation != "bearer" || !info.Delegatable || info.Renewable || !info.Valid() {
+		t.Errorf("info=%#v", info)
+	}
+
+	if !info.NotOnOrAfter.Equal(bearer.Lifetime.Expires) {
+		t.Errorf("expires=%s", info.NotOnOrAfter)
+	}
+
+	if _, err = tokens.Renew(ctx, TokenRequest{Certificate: solutionUserCert(), Token: bearer.Token}); err == nil {
+		t.Error("expected error") // not renewable
+	}
+
+	req := TokenRequest{
+		Certificate: solutionUserCert(),
+		ActAs:       true,
+		Token:       bearer.Token,
+		Renewable:   true,
+	}
+
+	ts := NewTokenSource(tokens, req)
+
+	hok, err := ts.Signer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err = ParseToken(hok.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Subject != "user@VSPHERE.LOCAL" || info.Confirmation != "holder-of-key" || !info.Renewable {
+		t.Errorf("info=%#v", info)
+	}
+
+	if len(info.Delegates) != 1 || info.Delegates[0] != "govmomi-test@VSPHERE.LOCAL" {
+		t.Errorf("delegates=%v", info.Delegates)
+	}
+
+	if signer, _ := ts.Signer(ctx); signer != hok {
+		t.Error("expected current token")
+	}
+
+	// Token expires within Margin, Signer should renew
+	ts.Margin = time.Hour
+
+	renewed, err := ts.Signer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if renewed == hok || renewed.Token == hok.Token {
+		t.Error("expected renewed token")
+	}
+
+	ts.Margin = 0
+
+	if err = ts.Login(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+
+	m := session.NewManager(c)
+	current, err := m.UserSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if current == nil || current.UserName != info.Subject {
+		t.Fatalf("session=%#v", current)
+	}
+
+	// Terminate the token session using another client session
+	admin, err := vim25.NewClient(ctx, soap.NewClient(s.URL, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = session.NewManager(admin).Login(ctx, s.URL.User); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = session.NewManager(admin).TerminateSession(ctx, []string{current.Key}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The session should be authenticated again using the TokenSource
+	if _, err = methods.GetCurrentTime(ctx, c); err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := m.UserSession(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if next == nil || next.Key == current.Key {
+		t.Errorf("session=%#v", next)
+	}
+}
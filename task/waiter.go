@@ -0,0 +1,318 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package task
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/progress"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Result is the outcome of a task waited for by a Waiter.
+type Result struct {
+	Task types.ManagedObjectReference
+	Info *types.TaskInfo
+
+	// Err is an instance of this package's Error struct if the task finished in the error state,
+	// or the error that caused Waiter.Wait to return before the task finished.
+	Err error
+}
+
+type waiterTask struct {
+	cb     *taskCallback
+	done   bool
+	finish chan struct{}
+}
+
+// close marks the task as done, closing its progress sink, must be called with the Waiter lock held.
+func (t *waiterTask) close() {
+	t.done = true
+	if t.cb.ch != nil {
+		close(t.cb.ch)
+	}
+	close(t.finish)
+}
+
+// Waiter waits for any number of tasks to finish, using a single property collector filter
+// on the "info" property of each task, rather than a filter and collector per task as Wait does.
+// The tasks are tracked using a ListView, such that tasks can be added while waiting.
+// Wait returns the results of all tasks, while WaitForResult can be called concurrently to wait for a single task,
+// sharing the same filter with any other tasks being waited for.
+type Waiter struct {
+	c *vim25.Client
+
+	mu      sync.Mutex
+	tasks   map[types.ManagedObjectReference]*waiterTask
+	refs    []types.ManagedObjectReference
+	view    *types.ManagedObjectReference
+	running bool
+}
+
+// NewWaiter returns a Waiter for tasks created with the given client.
+func NewWaiter(c *vim25.Client) *Waiter {
+	return &Waiter{
+		c:     c,
+		tasks: make(map[types.ManagedObjectReference]*waiterTask),
+	}
+}
+
+// Add a task to the Waiter, with an optional progress.Sinker to receive progress updates for this task.
+// Tasks can be added before or during a call to Wait.
+func (w *Waiter) Add(ctx context.Context, ref types.ManagedObjectReference, s progress.Sinker) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, ok := w.tasks[ref]; ok {
+		return nil
+	}
+
+	cb := &taskCallback{}
+	if s != nil {
+		cb.ch = s.Sink()
+	}
+
+	w.tasks[ref] = &waiterTask{cb: cb, finish: make(chan struct{})}
+	w.refs = append(w.refs, ref)
+
+	if w.view == nil {
+		return nil
+	}
+
+	return w.modify(ctx, []types.ManagedObjectReference{ref}, nil)
+}
+
+// modify adds or removes tasks from the ListView, must be called with the lock held.
+func (w *Waiter) modify(ctx context.Context, add, remove []types.ManagedObjectReference) error {
+	req := types.ModifyListView{
+		This:   *w.view,
+		Add:    add,
+		Remove: remove,
+	}
+
+	_, err := methods.ModifyListView(ctx, w.c, &req)
+	return err
+}
+
+// pending returns the references of tasks that have not yet finished, must be called with the lock held.
+func (w *Waiter) pending() []types.ManagedObjectReference {
+	var refs []types.ManagedObjectReference
+
+	for _, ref := range w.refs {
+		if !w.tasks[ref].done {
+			refs = append(refs, ref)
+		}
+	}
+
+	return refs
+}
+
+// update applies the given ObjectUpdates, returning the references of tasks that finished.
+func (w *Waiter) update(updates []types.ObjectUpdate) []types.ManagedObjectReference {
+	var done []types.ManagedObjectReference
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, u := range updates {
+		t, ok := w.tasks[u.Obj]
+		if !ok || t.done {
+			continue
+		}
+
+		if t.cb.fn(u.ChangeSet) {
+			t.close()
+			done = append(done, u.Obj)
+		}
+	}
+
+	return done
+}
+
+// results returns the Result of each task, in the order the tasks were added.
+// Any sinks of tasks that have not finished are closed.
+func (w *Waiter) results(err error) []Result {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	res := make([]Result, 0, len(w.refs))
+
+	for _, ref := range w.refs {
+		t := w.tasks[ref]
+		r := Result{Task: ref, Info: t.cb.info, Err: t.cb.err}
+
+		if !t.done {
+			r.Err = err
+			t.cb.err = err
+			t.close()
+		}
+
+		res = append(res, r)
+	}
+
+	return res
+}
+
+// Wait waits for all tasks to finish with either success or failure,
+// returning the Result of each task in the order they were added.
+// Any error returned while waiting for property changes causes the function to
+// return immediately, with the error set in the Result of any unfinished tasks.
+func (w *Waiter) Wait(ctx context.Context) ([]Result, error) {
+	err := w.wait(ctx)
+	return w.results(err), err
+}
+
+// WaitForResult adds the given task as Add does and waits for it to finish, returning its info and error as Wait does.
+// The Waiter collects updates for all tasks being waited for in the background, until none are pending.
+// WaitForResult must not be used with Wait.
+func (w *Waiter) WaitForResult(ctx context.Context, ref types.ManagedObjectReference, s progress.Sinker) (*types.TaskInfo, error) {
+	if err := w.Add(ctx, ref, s); err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	t := w.tasks[ref]
+	start := !w.running
+	w.running = true
+	w.mu.Unlock()
+
+	if start {
+		go w.run(ctx)
+	}
+
+	select {
+	case <-t.finish:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return t.cb.info, t.cb.err
+}
+
+// run waits for updates until no tasks are pending, including those added while waiting.
+// If waiting fails, the error is set as the result of any unfinished tasks.
+func (w *Waiter) run(ctx context.Context) {
+	for {
+		err := w.wait(ctx)
+		if err != nil {
+			_ = w.results(err)
+		}
+
+		w.mu.Lock()
+		if len(w.pending()) == 0 {
+			w.running = false
+			w.mu.Unlock()
+			return
+		}
+		w.mu.Unlock()
+	}
+}
+
+func (w *Waiter) wait(ctx context.Context) error {
+	pc, err := property.DefaultCollector(w.c).Create(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Attempt to destroy the collector and view using the background context,
+	// as the specified context may have timed out or have been canceled.
+	defer pc.Destroy(context.Background())
+
+	w.mu.Lock()
+	refs := w.pending()
+	if len(refs) == 0 {
+		w.mu.Unlock()
+		return nil
+	}
+
+	res, err := methods.CreateListView(ctx, w.c, &types.CreateListView{
+		This: *w.c.ServiceContent.ViewManager,
+		Obj:  refs,
+	})
+	if err != nil {
+		w.mu.Unlock()
+		return err
+	}
+
+	w.view = &res.Returnval
+	w.mu.Unlock()
+
+	defer func() {
+		w.mu.Lock()
+		_, _ = methods.DestroyView(context.Background(), w.c, &types.DestroyView{This: *w.view})
+		w.view = nil
+		w.mu.Unlock()
+	}()
+
+	filter := new(property.WaitFilter).Add(*w.view, "Task", []string{"info"}, &types.TraversalSpec{
+		Type: "ListView",
+		Path: "view",
+	})
+	filter.Spec.ObjectSet[0].Skip = types.NewBool(true)
+
+	if err = pc.CreateFilter(ctx, filter.CreateFilter); err != nil {
+		return err
+	}
+
+	req := types.WaitForUpdatesEx{
+		This: pc.Reference(),
+	}
+
+	for {
+		res, err := methods.WaitForUpdatesEx(ctx, w.c, &req)
+		if err != nil {
+			if ctx.Err() == context.Canceled {
+				_ = pc.CancelWaitForUpdates(context.Background())
+			}
+			return err
+		}
+
+		set := res.Returnval
+		if set == nil {
+			continue
+		}
+
+		req.Version = set.Version
+
+		var done []types.ManagedObjectReference
+		for _, fs := range set.FilterSet {
+			done = append(done, w.update(fs.ObjectSet)...)
+		}
+
+		w.mu.Lock()
+		if len(w.pending()) == 0 {
+			w.mu.Unlock()
+			return nil
+		}
+		if len(done) != 0 {
+			// Stop collecting updates for finished tasks
+			err = w.modify(ctx, nil, done)
+		}
+		w.mu.Unlock()
+
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -53,17 +53,25 @@ func (c *Client) Do(ctx context.Context, req *http.Request, resBody interface{})
 	req.Header.Set("Accept", "application/json")
 
 	return c.Client.Do(ctx, req, func(res *http.Response) error {
-		switch res.StatusCode {
-		case http.StatusOK:
-		case http.StatusBadRequest:
-			// TODO: structured error types
+		if res.StatusCode != http.StatusOK {
 			detail, err := ioutil.ReadAll(res.Body)
 			if err != nil {
 				return err
 			}
-			return fmt.Errorf("%s: %s", res.Status, bytes.TrimSpace(detail))
-		default:
-			return fmt.Errorf("%s %s: %s", req.Method, req.URL, res.Status)
+
+			rerr := &Error{
+				StatusCode: res.StatusCode,
+				Status:     res.Status,
+			}
+			rerr.decode(detail)
+
+			if res.StatusCode == http.StatusBadRequest {
+				rerr.msg = fmt.Sprintf("%s: %s", res.Status, bytes.TrimSpace(detail))
+			} else {
+				rerr.msg = fmt.Sprintf("%s %s: %s", req.Method, req.URL, res.Status)
+			}
+
+			return rerr
 		}
 
 		if resBody == nil {
@@ -0,0 +1,72 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rest
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Error is returned by Client.Do when the server responds with an error status.
+type Error struct {
+	StatusCode int
+	Status     string
+	// Type is the vAPI standard error type, such as "com.vmware.vapi.std.errors.not_found", if provided by the server.
+	Type string
+	// Messages are the localized error messages, if provided by the server.
+	Messages []string
+
+	msg string
+}
+
+func (e *Error) Error() string {
+	return e.msg
+}
+
+// Kind returns the last component of the error Type, such as "not_found".
+func (e *Error) Kind() string {
+	return e.Type[strings.LastIndex(e.Type, ".")+1:]
+}
+
+// decode parses the vAPI error structure, if any, from the response body.
+func (e *Error) decode(body []byte) {
+	var res struct {
+		Type  string `json:"type"`
+		Value struct {
+			Messages []json.RawMessage `json:"messages"`
+		} `json:"value"`
+	}
+
+	if json.Unmarshal(body, &res) != nil {
+		return
+	}
+
+	e.Type = res.Type
+
+	for _, m := range res.Value.Messages {
+		var msg struct {
+			DefaultMessage string `json:"default_message"`
+		}
+
+		var s string
+		if json.Unmarshal(m, &s) == nil {
+			e.Messages = append(e.Messages, s)
+		} else if json.Unmarshal(m, &msg) == nil {
+			e.Messages = append(e.Messages, msg.DefaultMessage)
+		}
+	}
+}
@@ -30,9 +30,11 @@ import (
 
 	"github.com/google/uuid"
 	lookup "github.com/vmware/govmomi/lookup/simulator"
+	pbm "github.com/vmware/govmomi/pbm/simulator"
 	"github.com/vmware/govmomi/simulator"
 	"github.com/vmware/govmomi/simulator/esx"
 	"github.com/vmware/govmomi/simulator/vpx"
+	sso "github.com/vmware/govmomi/ssoadmin/simulator"
 	sts "github.com/vmware/govmomi/sts/simulator"
 	vapi "github.com/vmware/govmomi/vapi/simulator"
 )
@@ -141,16 +143,26 @@ func main() {
 	}
 
 	if !*isESX {
+		// SSO admin simulator
+		admin, principals := sso.New(s.URL, vpx.Setting)
+		model.Service.RegisterSDK(admin)
+
 		// STS simulator
-		path, handler := sts.New(s.URL, vpx.Setting)
-		model.Service.ServeMux.Handle(path, handler)
+		path, tokens := sts.New(s.URL, vpx.Setting)
+		if tokens != nil {
+			tokens.Authenticator = principals
+			model.Service.ServeMux.Handle(path, tokens)
+		}
 
 		// vAPI simulator
-		path, handler = vapi.New(s.URL, vpx.Setting)
+		path, handler := vapi.New(s.URL, vpx.Setting)
 		model.Service.ServeMux.Handle(path, handler)
 
 		// Lookup Service simulator
 		model.Service.RegisterSDK(lookup.New())
+
+		// PBM simulator
+		model.Service.RegisterSDK(pbm.New())
 	}
 
 	fmt.Fprintf(out, "export GOVC_URL=%s GOVC_SIM_PID=%d\n", s.URL, os.Getpid())
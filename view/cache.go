@@ -0,0 +1,567 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package view
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Change describes an ObjectUpdate applied to a Cache.
+type Change struct {
+	Kind types.ObjectUpdateKind
+	Ref  types.ManagedObjectReference
+
+	// Object is the cached object after the change was applied,
+	// or the last known state of the object in the case of ObjectUpdateKindLeave.
+	Object interface{}
+
+	// ChangeSet is the set of property changes applied to the Object.
+	ChangeSet []types.PropertyChange
+}
+
+type cacheEntry struct {
+	props  map[string]types.AnyType
+	object interface{}
+}
+
+// Cache maintains an in-memory mirror of the managed objects in a ContainerView,
+// such as mo.VirtualMachine and mo.HostSystem, kept current via WaitForUpdatesEx.
+// Rather than polling with Retrieve, clients can use the Cache lookup methods,
+// which are backed by indexes on moref, name, UUID and parent.
+type Cache struct {
+	// Login, if set, is called to re-establish the session when WaitForUpdatesEx fails with NotAuthenticated.
+	// The Cache is then resynced using a new view and collector.
+	// If not set, Run returns the NotAuthenticated error.
+	Login func(context.Context) error
+
+	// MaxObjectUpdates limits the number of ObjectUpdates in a single WaitForUpdatesEx response.
+	// A value of 0 leaves the limit to the server.
+	MaxObjectUpdates int32
+
+	client *vim25.Client
+	root   types.ManagedObjectReference
+	kind   []string
+	props  []string
+
+	mu       sync.RWMutex
+	objects  map[types.ManagedObjectReference]*cacheEntry
+	names    map[string]map[types.ManagedObjectReference]bool
+	uuids    map[string]map[types.ManagedObjectReference]bool
+	children map[types.ManagedObjectReference]map[types.ManagedObjectReference]bool
+	subs     map[int]func(Change)
+	nsub     int
+	ready    chan struct{}
+	once     sync.Once
+}
+
+// NewCache returns a Cache of the managed objects of the given kind, found recursively within root.
+// If the properties slice is empty, all properties are cached, otherwise the "name" and "parent"
+// properties are always included for use by the name and parent indexes.
+// UUID lookups require the relevant properties to be cached, such as "config.uuid" for VirtualMachine
+// or "hardware.systemInfo.uuid" for HostSystem.
+func NewCache(c *vim25.Client, root types.ManagedObjectReference, kind []string, ps []string) *Cache {
+	if len(ps) != 0 {
+		for _, name := range []string{"name", "parent"} {
+			if !contains(ps, name) {
+				ps = append(ps, name)
+			}
+		}
+	}
+
+	return &Cache{
+		client:   c,
+		root:     root,
+		kind:     kind,
+		props:    ps,
+		objects:  make(map[types.ManagedObjectReference]*cacheEntry),
+		names:    make(map[string]map[types.ManagedObjectReference]bool),
+		uuids:    make(map[string]map[types.ManagedObjectReference]bool),
+		children: make(map[types.ManagedObjectReference]map[types.ManagedObjectReference]bool),
+		subs:     make(map[int]func(Change)),
+		ready:    make(chan struct{}),
+	}
+}
+
+func contains(s []string, val string) bool {
+	for _, v := range s {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+// Ready returns a channel that is closed once the initial contents of the Cache have been retrieved.
+func (c *Cache) Ready() <-chan struct{} {
+	return c.ready
+}
+
+// Subscribe registers the given function to be called for each Change applied to the Cache.
+// The function is called from the Run goroutine, in the order changes are applied.
+// The returned function can be used to cancel the subscription.
+func (c *Cache) Subscribe(f func(Change)) func() {
+	c.mu.Lock()
+	id := c.nsub
+	c.nsub++
+	c.subs[id] = f
+	c.mu.Unlock()
+
+	return func() {
+		c.mu.Lock()
+		delete(c.subs, id)
+		c.mu.Unlock()
+	}
+}
+
+// Get returns the cached object for the given reference.
+func (c *Cache) Get(ref types.ManagedObjectReference) (interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	e, ok := c.objects[ref]
+	if !ok {
+		return nil, false
+	}
+
+	return e.object, true
+}
+
+// list returns the cached objects for the given set of references, sorted by reference.
+func (c *Cache) list(refs map[types.ManagedObjectReference]bool) []interface{} {
+	keys := make([]types.ManagedObjectReference, 0, len(refs))
+	for ref := range refs {
+		keys = append(keys, ref)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Type == keys[j].Type {
+			return keys[i].Value < keys[j].Value
+		}
+		return keys[i].Type < keys[j].Type
+	})
+
+	objects := make([]interface{}, 0, len(keys))
+	for _, ref := range keys {
+		objects = append(objects, c.objects[ref].object)
+	}
+
+	return objects
+}
+
+// List returns the cached objects of the given kind, or all cached objects if kind is empty.
+func (c *Cache) List(kind ...string) []interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	refs := make(map[types.ManagedObjectReference]bool)
+	for ref := range c.objects {
+		if len(kind) == 0 || contains(kind, ref.Type) {
+			refs[ref] = true
+		}
+	}
+
+	return c.list(refs)
+}
+
+// FindByName returns the cached objects with the given name.
+func (c *Cache) FindByName(name string) []interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.list(c.names[name])
+}
+
+// FindByUUID returns the cached objects with the given BIOS or instance UUID.
+func (c *Cache) FindByUUID(uuid string) []interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.list(c.uuids[strings.ToLower(uuid)])
+}
+
+// Children returns the cached objects whose parent is the given reference.
+func (c *Cache) Children(parent types.ManagedObjectReference) []interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.list(c.children[parent])
+}
+
+// name returns the cached "name" property of the given entry.
+func (e *cacheEntry) name() string {
+	name, _ := e.props["name"].(string)
+	return name
+}
+
+// parent returns the cached "parent" property of the given entry.
+func (e *cacheEntry) parent() *types.ManagedObjectReference {
+	parent, ok := e.props["parent"].(types.ManagedObjectReference)
+	if !ok {
+		return nil
+	}
+	return &parent
+}
+
+// uuids returns the BIOS and instance UUIDs of the given entry, if cached.
+func (e *cacheEntry) uuids() []string {
+	var ids []string
+
+	switch o := e.object.(type) {
+	case mo.VirtualMachine:
+		if o.Config != nil {
+			ids = append(ids, o.Config.Uuid, o.Config.InstanceUuid)
+		}
+		ids = append(ids, o.Summary.Config.Uuid, o.Summary.Config.InstanceUuid)
+	case mo.HostSystem:
+		if o.Hardware != nil {
+			ids = append(ids, o.Hardware.SystemInfo.Uuid)
+		}
+		if o.Summary.Hardware != nil {
+			ids = append(ids, o.Summary.Hardware.Uuid)
+		}
+	}
+
+	var res []string
+	for _, id := range ids {
+		if id != "" {
+			res = append(res, strings.ToLower(id))
+		}
+	}
+
+	return res
+}
+
+func addIndex(index map[string]map[types.ManagedObjectReference]bool, key string, ref types.ManagedObjectReference) {
+	if index[key] == nil {
+		index[key] = make(map[types.ManagedObjectReference]bool)
+	}
+	index[key][ref] = true
+}
+
+func removeIndex(index map[string]map[types.ManagedObjectReference]bool, key string, ref types.ManagedObjectReference) {
+	delete(index[key], ref)
+	if len(index[key]) == 0 {
+		delete(index, key)
+	}
+}
+
+// index adds (or removes if add is false) the given entry to the name, uuid and parent indexes.
+func (c *Cache) index(ref types.ManagedObjectReference, e *cacheEntry, add bool) {
+	update := removeIndex
+	if add {
+		update = addIndex
+	}
+
+	update(c.names, e.name(), ref)
+
+	for _, id := range e.uuids() {
+		update(c.uuids, id, ref)
+	}
+
+	if parent := e.parent(); parent != nil {
+		if add {
+			if c.children[*parent] == nil {
+				c.children[*parent] = make(map[types.ManagedObjectReference]bool)
+			}
+			c.children[*parent][ref] = true
+		} else {
+			delete(c.children[*parent], ref)
+			if len(c.children[*parent]) == 0 {
+				delete(c.children, *parent)
+			}
+		}
+	}
+}
+
+// load converts the entry properties to the typed object.
+func (e *cacheEntry) load(ref types.ManagedObjectReference) {
+	content := types.ObjectContent{Obj: ref}
+
+	for name, val := range e.props {
+		content.PropSet = append(content.PropSet, types.DynamicProperty{Name: name, Val: val})
+	}
+
+	// Apply parent properties before any nested properties, such as "config" before "config.uuid"
+	sort.Slice(content.PropSet, func(i, j int) bool {
+		return content.PropSet[i].Name < content.PropSet[j].Name
+	})
+
+	e.object, _ = mo.ObjectContentToType(content)
+}
+
+// change applies a PropertyChange to the entry properties.
+func (e *cacheEntry) change(p types.PropertyChange) {
+	// A change to a property replaces any nested properties
+	for name := range e.props {
+		if strings.HasPrefix(name, p.Name+".") {
+			delete(e.props, name)
+		}
+	}
+
+	switch p.Op {
+	case types.PropertyChangeOpRemove, types.PropertyChangeOpIndirectRemove:
+		delete(e.props, p.Name)
+	default:
+		e.props[p.Name] = p.Val
+	}
+}
+
+// update applies the given ObjectUpdate, returning the resulting Change.
+func (c *Cache) update(u types.ObjectUpdate) Change {
+	change := Change{
+		Kind:      u.Kind,
+		Ref:       u.Obj,
+		ChangeSet: u.ChangeSet,
+	}
+
+	e, ok := c.objects[u.Obj]
+	if ok {
+		c.index(u.Obj, e, false)
+	}
+
+	switch u.Kind {
+	case types.ObjectUpdateKindLeave:
+		if ok {
+			change.Object = e.object
+			delete(c.objects, u.Obj)
+		}
+		return change
+	case types.ObjectUpdateKindEnter:
+		e = &cacheEntry{props: make(map[string]types.AnyType)} // replaces any existing entry when resyncing
+	default:
+		if !ok {
+			e = &cacheEntry{props: make(map[string]types.AnyType)}
+		}
+	}
+
+	for _, p := range u.ChangeSet {
+		e.change(p)
+	}
+
+	e.load(u.Obj)
+	c.objects[u.Obj] = e
+	c.index(u.Obj, e, true)
+
+	change.Object = e.object
+
+	return change
+}
+
+// apply applies the given UpdateSet, recording the references of objects in seen if not nil.
+func (c *Cache) apply(set *types.UpdateSet, seen map[types.ManagedObjectReference]bool) []Change {
+	var changes []Change
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, fs := range set.FilterSet {
+		for _, u := range fs.ObjectSet {
+			if seen != nil {
+				seen[u.Obj] = u.Kind != types.ObjectUpdateKindLeave
+			}
+			changes = append(changes, c.update(u))
+		}
+	}
+
+	return changes
+}
+
+// prune removes cached objects that were not seen during a resync.
+func (c *Cache) prune(seen map[types.ManagedObjectReference]bool) []Change {
+	var changes []Change
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for ref := range c.objects {
+		if !seen[ref] {
+			changes = append(changes, c.update(types.ObjectUpdate{Obj: ref, Kind: types.ObjectUpdateKindLeave}))
+		}
+	}
+
+	return changes
+}
+
+func (c *Cache) notify(changes []Change) {
+	if len(changes) == 0 {
+		return
+	}
+
+	c.mu.RLock()
+	ids := make([]int, 0, len(c.subs))
+	for id := range c.subs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	subs := make([]func(Change), 0, len(ids))
+	for _, id := range ids {
+		subs = append(subs, c.subs[id])
+	}
+	c.mu.RUnlock()
+
+	for _, change := range changes {
+		for _, f := range subs {
+			f(change)
+		}
+	}
+}
+
+// filter returns the CreateFilter request for the given view.
+func (c *Cache) filter(v *ContainerView) types.CreateFilter {
+	spec := types.PropertyFilterSpec{
+		ObjectSet: []types.ObjectSpec{{
+			Obj:  v.Reference(),
+			Skip: types.NewBool(true),
+			SelectSet: []types.BaseSelectionSpec{
+				&types.TraversalSpec{
+					Type: v.Reference().Type,
+					Path: "view",
+				},
+			},
+		}},
+	}
+
+	kind := c.kind
+	if len(kind) == 0 {
+		kind = []string{"ManagedEntity"}
+	}
+
+	for _, t := range kind {
+		pspec := types.PropertySpec{
+			Type: t,
+		}
+
+		if len(c.props) == 0 {
+			pspec.All = types.NewBool(true)
+		} else {
+			pspec.PathSet = c.props
+		}
+
+		spec.PropSet = append(spec.PropSet, pspec)
+	}
+
+	return types.CreateFilter{Spec: spec}
+}
+
+// sync creates a view and collector, populating the Cache and then waiting for updates
+// until the given context is canceled or an error occurs.
+func (c *Cache) sync(ctx context.Context) error {
+	pc, err := property.DefaultCollector(c.client).Create(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Attempt to destroy the collector and view using the background context,
+	// as the specified context may have been canceled.
+	defer pc.Destroy(context.Background())
+
+	v, err := NewManager(c.client).CreateContainerView(ctx, c.root, c.kind, true)
+	if err != nil {
+		return err
+	}
+
+	defer v.Destroy(context.Background())
+
+	if err = pc.CreateFilter(ctx, c.filter(v)); err != nil {
+		return err
+	}
+
+	req := types.WaitForUpdatesEx{
+		This: pc.Reference(),
+		Options: &types.WaitOptions{
+			MaxObjectUpdates: c.MaxObjectUpdates,
+		},
+	}
+
+	// Objects seen during the initial sync, any others were removed while disconnected
+	seen := make(map[types.ManagedObjectReference]bool)
+
+	for {
+		res, err := methods.WaitForUpdatesEx(ctx, c.client, &req)
+		if err != nil {
+			if ctx.Err() == context.Canceled {
+				_ = pc.CancelWaitForUpdates(context.Background())
+			}
+			return err
+		}
+
+		set := res.Returnval
+		if set == nil {
+			continue
+		}
+
+		req.Version = set.Version
+
+		c.notify(c.apply(set, seen))
+
+		if seen != nil && (set.Truncated == nil || !*set.Truncated) {
+			c.notify(c.prune(seen))
+			seen = nil
+			c.once.Do(func() { close(c.ready) })
+		}
+	}
+}
+
+// resync returns true if the given error can be recovered from with a new view and collector.
+func (c *Cache) resync(ctx context.Context, err error) (bool, error) {
+	if !soap.IsSoapFault(err) {
+		return false, err
+	}
+
+	switch soap.ToSoapFault(err).VimFault().(type) {
+	case types.NotAuthenticated:
+		if c.Login == nil {
+			return false, err
+		}
+		if err = c.Login(ctx); err != nil {
+			return false, err
+		}
+		return true, nil
+	case types.InvalidCollectorVersion:
+		return true, nil
+	}
+
+	return false, err
+}
+
+// Run populates the Cache and keeps it current via WaitForUpdatesEx, until the given context is canceled.
+// If the session is lost, the Login function is used to re-establish the session, after which the Cache is resynced.
+// Objects removed while disconnected are removed from the Cache during the resync.
+// Run returns nil when the context is canceled, otherwise the error that could not be recovered from.
+func (c *Cache) Run(ctx context.Context) error {
+	for {
+		err := c.sync(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		ok, err := c.resync(ctx, err)
+		if !ok {
+			return err
+		}
+	}
+}
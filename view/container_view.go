@@ -27,6 +27,8 @@ import (
 
 type ContainerView struct {
 	ManagedObjectView
+
+	options property.RetrieveOptions
 }
 
 func NewContainerView(c *vim25.Client, ref types.ManagedObjectReference) *ContainerView {
@@ -35,9 +37,21 @@ func NewContainerView(c *vim25.Client, ref types.ManagedObjectReference) *Contai
 	}
 }
 
+// WithRetrieveOptions returns a copy of the ContainerView, configured with the given property.RetrieveOptions.
+func (v ContainerView) WithRetrieveOptions(opts property.RetrieveOptions) *ContainerView {
+	v.options = opts
+	return &v
+}
+
+// withoutPage returns a copy of the ContainerView with the RetrieveOptions.Page callback unset.
+func (v ContainerView) withoutPage() *ContainerView {
+	v.options.Page = nil
+	return &v
+}
+
 // Retrieve populates dst as property.Collector.Retrieve does, for all entities in the view of types specified by kind.
 func (v ContainerView) Retrieve(ctx context.Context, kind []string, ps []string, dst interface{}) error {
-	pc := property.DefaultCollector(v.Client())
+	pc := property.DefaultCollector(v.Client()).WithRetrieveOptions(v.options)
 
 	ospec := types.ObjectSpec{
 		Obj:  v.Reference(),
@@ -100,14 +114,14 @@ func (v ContainerView) RetrieveWithFilter(ctx context.Context, kind []string, ps
 
 	var content []types.ObjectContent
 
-	err := v.Retrieve(ctx, kind, filter.Keys(), &content)
+	err := v.withoutPage().Retrieve(ctx, kind, filter.Keys(), &content)
 	if err != nil {
 		return err
 	}
 
 	objs := filter.MatchObjectContent(content)
 
-	pc := property.DefaultCollector(v.Client())
+	pc := property.DefaultCollector(v.Client()).WithRetrieveOptions(v.options)
 
 	return pc.Retrieve(ctx, objs, ps, dst)
 }
@@ -121,10 +135,44 @@ func (v ContainerView) Find(ctx context.Context, kind []string, filter property.
 
 	var content []types.ObjectContent
 
-	err := v.Retrieve(ctx, kind, filter.Keys(), &content)
+	err := v.withoutPage().Retrieve(ctx, kind, filter.Keys(), &content)
 	if err != nil {
 		return nil, err
 	}
 
 	return filter.MatchObjectContent(content), nil
 }
+
+// FindWithPredicate returns the ObjectContent of entities of type kind matching the given predicate.
+// Only the properties referenced by the predicate are retrieved, along with the optional properties ps,
+// such as those used for sorting.  The predicate is evaluated client side.
+func (v ContainerView) FindWithPredicate(ctx context.Context, kind []string, p *property.Predicate, ps ...string) ([]types.ObjectContent, error) {
+	keys := p.Keys(kind...)
+
+	for _, path := range ps {
+		key := property.CollectPath(path, kind...)
+		found := false
+		for i := range keys {
+			if keys[i] == key {
+				found = true
+			}
+		}
+		if !found {
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		// Ensure we have at least 1 property to avoid retrieving all properties.
+		keys = []string{"name"}
+	}
+
+	var content []types.ObjectContent
+
+	err := v.withoutPage().Retrieve(ctx, kind, keys, &content)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Select(content), nil
+}
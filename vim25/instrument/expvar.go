@@ -0,0 +1,96 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instrument
+
+import (
+	"context"
+	"expvar"
+	"sync"
+
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// Expvar is a soap.Interceptor that records per-method call metrics as expvar variables.
+// Each method is published as a map with the following keys:
+//
+// calls: the number of calls
+//
+// errors: the number of calls that failed, including faults
+//
+// faults: a map of fault type name to count
+//
+// request_bytes, response_bytes: total bytes sent and received
+//
+// latency: a Histogram of call duration in seconds
+type Expvar struct {
+	vars *expvar.Map
+
+	mu      sync.Mutex
+	methods map[string]*expvar.Map
+}
+
+// NewExpvar creates an Expvar interceptor, publishing its metrics with the given name.
+// As with expvar.Publish, NewExpvar panics if the name is already registered.
+func NewExpvar(name string) *Expvar {
+	return &Expvar{
+		vars:    expvar.NewMap(name),
+		methods: make(map[string]*expvar.Map),
+	}
+}
+
+// Map returns the published expvar.Map.
+func (e *Expvar) Map() *expvar.Map {
+	return e.vars
+}
+
+func (e *Expvar) method(name string) *expvar.Map {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	m, ok := e.methods[name]
+	if !ok {
+		m = new(expvar.Map).Init()
+		m.Set("latency", NewHistogram())
+		m.Set("faults", new(expvar.Map).Init())
+		e.methods[name] = m
+		e.vars.Set(name, m)
+	}
+
+	return m
+}
+
+func (e *Expvar) Intercept(ctx context.Context, call *soap.Call, next func(context.Context) error) error {
+	err := next(ctx)
+
+	m := e.method(call.Method)
+	m.Add("calls", 1)
+	if call.RequestBytes > 0 {
+		m.Add("request_bytes", call.RequestBytes)
+	}
+	m.Add("response_bytes", call.ResponseBytes)
+	m.Get("latency").(*Histogram).Observe(call.Duration().Seconds())
+
+	if err != nil {
+		m.Add("errors", 1)
+	}
+
+	if call.Fault != "" {
+		m.Get("faults").(*expvar.Map).Add(call.Fault, 1)
+	}
+
+	return err
+}
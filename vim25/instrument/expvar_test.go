@@ -0,0 +1,169 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instrument
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vapi/rest"
+	vapi "github.com/vmware/govmomi/vapi/simulator"
+	"github.com/vmware/govmomi/vapi/tags"
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+var runs int32
+
+func TestExpvar(t *testing.T) {
+	ctx := context.Background()
+
+	m := simulator.VPX()
+	defer m.Remove()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service.NewServer()
+	defer s.Close()
+
+	path, handler := vapi.New(s.URL, nil)
+	m.Service.ServeMux.Handle(path, handler)
+
+	c, err := govmomi.NewClient(ctx, s.URL, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// expvar names can only be published once per process, for example with -count=2
+	name := fmt.Sprintf("%s-%d", t.Name(), atomic.AddInt32(&runs, 1))
+	metrics := NewExpvar(name)
+
+	var calls []soap.Call
+	c.Client.Intercept(metrics, soap.InterceptorFunc(func(ctx context.Context, call *soap.Call, next func(context.Context) error) error {
+		err := next(ctx)
+		calls = append(calls, *call)
+		return err
+	}))
+
+	_, err = methods.GetCurrentTime(ctx, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref := types.ManagedObjectReference{Type: "VirtualMachine", Value: "invalid"}
+	_, err = methods.PowerOnVM_Task(ctx, c, &types.PowerOnVM_Task{This: ref})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	r := rest.NewClient(c.Client)
+	err = r.Login(ctx, s.URL.User)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tm := tags.NewManager(r)
+
+	id, err := tm.CreateCategory(ctx, &tags.Category{Name: "my-category"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = tm.GetCategory(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []struct {
+		method string
+		fault  string
+	}{
+		{"CurrentTime", ""},
+		{"PowerOnVM_Task", "ManagedObjectNotFound"},
+		{"POST /rest/com/vmware/cis/session", ""},
+		{"POST /rest/com/vmware/cis/tagging/category", ""},
+		{"GET /rest/com/vmware/cis/tagging/category/*", ""},
+	}
+
+	if len(calls) != len(expect) {
+		t.Fatalf("calls=%d", len(calls))
+	}
+
+	for i, call := range calls {
+		if call.Method != expect[i].method || call.Fault != expect[i].fault {
+			t.Errorf("%d: method=%q, fault=%q", i, call.Method, call.Fault)
+		}
+		if call.StatusCode == 0 || call.End.Before(call.Start) {
+			t.Errorf("%d: %#v", i, call)
+		}
+	}
+
+	if calls[0].ResponseBytes == 0 || calls[3].ResponseBytes == 0 {
+		t.Errorf("response bytes not counted")
+	}
+
+	if calls[1].This != ref || calls[1].RequestBytes <= 0 {
+		t.Errorf("call=%#v", calls[1])
+	}
+
+	vars := expvar.Get(name).(*expvar.Map)
+
+	power := vars.Get("PowerOnVM_Task").(*expvar.Map)
+	if power.Get("calls").String() != "1" || power.Get("errors").String() != "1" {
+		t.Errorf("%s", power)
+	}
+
+	faults := power.Get("faults").(*expvar.Map)
+	if faults.Get("ManagedObjectNotFound").String() != "1" {
+		t.Errorf("%s", faults)
+	}
+
+	latency := vars.Get("CurrentTime").(*expvar.Map).Get("latency").(*Histogram).Snapshot()
+	if latency.Count != 1 || latency.Counts[len(latency.Counts)-1] != 1 {
+		t.Errorf("%#v", latency)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram(10, 1, 5)
+
+	for _, v := range []float64{0.5, 1, 3, 7, 20} {
+		h.Observe(v)
+	}
+
+	s := h.Snapshot()
+
+	expect := []uint64{2, 3, 4}
+	for i := range expect {
+		if s.Counts[i] != expect[i] {
+			t.Errorf("counts=%v", s.Counts)
+		}
+	}
+
+	if s.Count != 5 || s.Sum != 31.5 || s.Buckets[0] != 1 {
+		t.Errorf("%#v", s)
+	}
+}
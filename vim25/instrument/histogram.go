@@ -0,0 +1,100 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package instrument
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// DefaultBuckets are the Histogram upper bounds used by NewHistogram if none are given,
+// in seconds, suitable for vCenter API call latency.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Histogram counts observations in cumulative buckets, in the style of a Prometheus histogram.
+// Histogram implements the expvar.Var interface.
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+}
+
+// HistogramSnapshot is a point in time copy of a Histogram.
+type HistogramSnapshot struct {
+	Buckets []float64 `json:"buckets"` // Upper bounds
+	Counts  []uint64  `json:"counts"`  // Cumulative number of observations <= the Bucket of the same index
+	Count   uint64    `json:"count"`
+	Sum     float64   `json:"sum"`
+}
+
+// NewHistogram creates a Histogram with the given bucket upper bounds, or DefaultBuckets if none are given.
+func NewHistogram(buckets ...float64) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+
+	return &Histogram{
+		buckets: b,
+		counts:  make([]uint64, len(b)),
+	}
+}
+
+// Observe adds a single observation to the Histogram.
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.buckets, v)
+
+	h.mu.Lock()
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += v
+	h.mu.Unlock()
+}
+
+// Snapshot returns a copy of the Histogram's current state.
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := HistogramSnapshot{
+		Buckets: append([]float64(nil), h.buckets...),
+		Counts:  make([]uint64, len(h.counts)),
+		Count:   h.count,
+		Sum:     h.sum,
+	}
+
+	var total uint64
+	for i, n := range h.counts {
+		total += n
+		s.Counts[i] = total
+	}
+
+	return s
+}
+
+// String returns the JSON encoding of the Histogram's Snapshot.
+func (h *Histogram) String() string {
+	b, _ := json.Marshal(h.Snapshot())
+	return string(b)
+}
@@ -0,0 +1,290 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vim25
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// Priority determines the order in which queued requests are dispatched by a Limiter.
+type Priority int
+
+const (
+	// PriorityExempt requests bypass the Limiter entirely and are not counted against its budget.
+	PriorityExempt = Priority(iota - 1)
+	PriorityLow
+	PriorityNormal
+	PriorityHigh
+)
+
+// DefaultPriority exempts the long-polling WaitForUpdates methods, which can block on the server
+// for minutes at a time, and gives all other methods PriorityNormal.
+func DefaultPriority(method string) Priority {
+	switch method {
+	case "WaitForUpdates", "WaitForUpdatesEx", "CheckForUpdates", "CancelWaitForUpdates":
+		return PriorityExempt
+	}
+
+	return PriorityNormal
+}
+
+// Limit configures RateLimit.
+type Limit struct {
+	// MaxInFlight caps the number of concurrent requests, 0 for no limit.
+	MaxInFlight int
+
+	// Rate is the number of requests per second allowed by the token bucket, 0 for no limit.
+	Rate float64
+
+	// Burst is the token bucket size, defaults to the Rate rounded up (minimum of 1).
+	Burst int
+
+	// Priority assigns a Priority to each method, defaults to DefaultPriority.
+	Priority func(method string) Priority
+
+	// OnDispatch, if set, is called when a request leaves the queue with the time it spent waiting.
+	OnDispatch func(method string, priority Priority, queued time.Duration)
+}
+
+// LimiterStats is a snapshot of Limiter queue metrics.
+type LimiterStats struct {
+	InFlight     int           // Requests currently in flight, excluding exempt requests
+	Queued       int           // Requests waiting to be dispatched
+	Dispatched   int64         // Total requests dispatched, excluding exempt requests
+	Canceled     int64         // Total requests canceled while queued
+	QueueTime    time.Duration // Total time dispatched requests spent queued
+	MaxQueueTime time.Duration // Longest time a dispatched request spent queued
+}
+
+type waiter struct {
+	priority Priority
+	ready    chan struct{}
+}
+
+// Limiter is a soap.RoundTripper that limits the rate and concurrency of requests.
+type Limiter struct {
+	roundTripper soap.RoundTripper
+	limit        Limit
+
+	mu     sync.Mutex
+	queue  [PriorityHigh + 1][]*waiter
+	tokens float64
+	last   time.Time
+	timer  *time.Timer
+	stats  LimiterStats
+}
+
+// RateLimit wraps the specified soap.RoundTripper, queuing requests that exceed the given Limit
+// until they can be dispatched. Queued requests are dispatched in Priority order, FIFO within the same Priority.
+func RateLimit(roundTripper soap.RoundTripper, limit Limit) *Limiter {
+	if limit.Burst <= 0 {
+		limit.Burst = int(math.Max(1, math.Ceil(limit.Rate)))
+	}
+	if limit.Priority == nil {
+		limit.Priority = DefaultPriority
+	}
+
+	return &Limiter{
+		roundTripper: roundTripper,
+		limit:        limit,
+		tokens:       float64(limit.Burst),
+		last:         time.Now(),
+	}
+}
+
+// Stats returns a snapshot of the Limiter's metrics.
+func (l *Limiter) Stats() LimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := l.stats
+	for _, q := range l.queue {
+		stats.Queued += len(q)
+	}
+
+	return stats
+}
+
+func (l *Limiter) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	method := soap.MethodName(req)
+	priority := l.limit.Priority(method)
+
+	if priority == PriorityExempt {
+		return l.roundTripper.RoundTrip(ctx, req, res)
+	}
+
+	// Clamp priorities outside of the queue's range, other than PriorityExempt
+	switch {
+	case priority > PriorityHigh:
+		priority = PriorityHigh
+	case priority < PriorityLow:
+		priority = PriorityLow
+	}
+
+	if err := l.acquire(ctx, method, priority); err != nil {
+		return err
+	}
+
+	defer l.release()
+
+	return l.roundTripper.RoundTrip(ctx, req, res)
+}
+
+// refill adds tokens to the bucket for the time elapsed since the last refill.
+func (l *Limiter) refill(now time.Time) {
+	if l.limit.Rate <= 0 {
+		return
+	}
+
+	l.tokens += now.Sub(l.last).Seconds() * l.limit.Rate
+	if max := float64(l.limit.Burst); l.tokens > max {
+		l.tokens = max
+	}
+	l.last = now
+}
+
+// take consumes a token and an in-flight slot if both are available.
+// If only the token is unavailable, the returned duration is the time until the next token.
+func (l *Limiter) take(now time.Time) (bool, time.Duration) {
+	if l.limit.MaxInFlight > 0 && l.stats.InFlight >= l.limit.MaxInFlight {
+		return false, 0
+	}
+
+	if l.limit.Rate > 0 {
+		l.refill(now)
+		if l.tokens < 1 {
+			return false, time.Duration((1 - l.tokens) / l.limit.Rate * float64(time.Second))
+		}
+		l.tokens--
+	}
+
+	l.stats.InFlight++
+	l.stats.Dispatched++
+
+	return true, 0
+}
+
+// queued returns true if any request with a priority of at least p is waiting.
+func (l *Limiter) queued(p Priority) bool {
+	for i := p; i <= PriorityHigh; i++ {
+		if len(l.queue[i]) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch wakes queued requests, highest priority first, until the budget is exhausted.
+// Must be called with l.mu held.
+func (l *Limiter) dispatch() {
+	for p := PriorityHigh; p >= PriorityLow; p-- {
+		for len(l.queue[p]) != 0 {
+			ok, wait := l.take(time.Now())
+			if !ok {
+				if wait > 0 && l.timer == nil {
+					l.timer = time.AfterFunc(wait, func() {
+						l.mu.Lock()
+						l.timer = nil
+						l.dispatch()
+						l.mu.Unlock()
+					})
+				}
+				return
+			}
+
+			w := l.queue[p][0]
+			l.queue[p] = l.queue[p][1:]
+			close(w.ready)
+		}
+	}
+}
+
+func (l *Limiter) acquire(ctx context.Context, method string, priority Priority) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	start := time.Now()
+
+	l.mu.Lock()
+	if !l.queued(priority) {
+		if ok, _ := l.take(start); ok {
+			l.mu.Unlock()
+			l.dispatched(method, priority, 0)
+			return nil
+		}
+	}
+
+	w := &waiter{priority: priority, ready: make(chan struct{})}
+	l.queue[priority] = append(l.queue[priority], w)
+	l.dispatch() // start the token timer if needed
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		l.dispatched(method, priority, time.Since(start))
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		select {
+		case <-w.ready:
+			// Dispatched while canceling, give back the slot
+			l.stats.InFlight--
+			l.stats.Dispatched--
+			l.dispatch()
+		default:
+			q := l.queue[priority]
+			for i := range q {
+				if q[i] == w {
+					l.queue[priority] = append(q[:i], q[i+1:]...)
+					break
+				}
+			}
+		}
+
+		l.stats.Canceled++
+
+		return ctx.Err()
+	}
+}
+
+func (l *Limiter) dispatched(method string, priority Priority, queued time.Duration) {
+	l.mu.Lock()
+	l.stats.QueueTime += queued
+	if queued > l.stats.MaxQueueTime {
+		l.stats.MaxQueueTime = queued
+	}
+	l.mu.Unlock()
+
+	if l.limit.OnDispatch != nil {
+		l.limit.OnDispatch(method, priority, queued)
+	}
+}
+
+func (l *Limiter) release() {
+	l.mu.Lock()
+	l.stats.InFlight--
+	l.dispatch()
+	l.mu.Unlock()
+}
@@ -0,0 +1,211 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vim25
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/methods"
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// blockingRoundTripper blocks each call until release is closed, tracking the max number of concurrent calls.
+type blockingRoundTripper struct {
+	release chan struct{}
+
+	mu       sync.Mutex
+	inflight int
+	max      int
+	calls    []string
+}
+
+func (rt *blockingRoundTripper) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	rt.mu.Lock()
+	rt.inflight++
+	if rt.inflight > rt.max {
+		rt.max = rt.inflight
+	}
+	rt.calls = append(rt.calls, soap.MethodName(req))
+	rt.mu.Unlock()
+
+	<-rt.release
+
+	rt.mu.Lock()
+	rt.inflight--
+	rt.mu.Unlock()
+
+	return nil
+}
+
+func TestLimiterMaxInFlight(t *testing.T) {
+	rt := &blockingRoundTripper{release: make(chan struct{})}
+	l := RateLimit(rt, Limit{MaxInFlight: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = l.RoundTrip(context.Background(), new(methods.RetrievePropertiesBody), nil)
+		}()
+	}
+
+	// exempt from the budget
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = l.RoundTrip(context.Background(), new(methods.WaitForUpdatesExBody), nil)
+	}()
+
+	for l.Stats().Queued != 3 {
+		time.Sleep(time.Millisecond)
+	}
+
+	stats := l.Stats()
+	if stats.InFlight != 2 {
+		t.Errorf("InFlight=%d", stats.InFlight)
+	}
+
+	close(rt.release)
+	wg.Wait()
+
+	if rt.max != 3 {
+		t.Errorf("max=%d", rt.max)
+	}
+
+	stats = l.Stats()
+	if stats.InFlight != 0 || stats.Queued != 0 || stats.Dispatched != 5 {
+		t.Errorf("stats=%#v", stats)
+	}
+}
+
+func TestLimiterPriority(t *testing.T) {
+	rt := &blockingRoundTripper{release: make(chan struct{})}
+	l := RateLimit(rt, Limit{
+		MaxInFlight: 1,
+		Priority: func(method string) Priority {
+			switch method {
+			case "Logout":
+				return PriorityHigh + 1 // clamped to PriorityHigh
+			case "RetrieveProperties":
+				return PriorityExempt - 1 // clamped to PriorityLow
+			}
+			return PriorityNormal
+		},
+	})
+
+	var wg sync.WaitGroup
+	call := func(req soap.HasFault, n int) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = l.RoundTrip(context.Background(), req, nil)
+		}()
+		for {
+			stats := l.Stats()
+			if stats.InFlight+stats.Queued == n {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	call(new(methods.CurrentTimeBody), 1) // in flight
+	call(new(methods.RetrievePropertiesBody), 2)
+	call(new(methods.FindByUuidBody), 3)
+	call(new(methods.LogoutBody), 4)
+
+	close(rt.release)
+	wg.Wait()
+
+	expect := []string{"CurrentTime", "Logout", "FindByUuid", "RetrieveProperties"}
+	for i := range expect {
+		if rt.calls[i] != expect[i] {
+			t.Errorf("calls=%v", rt.calls)
+		}
+	}
+}
+
+func TestLimiterRate(t *testing.T) {
+	rt := &blockingRoundTripper{release: make(chan struct{})}
+	close(rt.release)
+
+	var queued int64
+	l := RateLimit(rt, Limit{
+		Rate:  100,
+		Burst: 2,
+		OnDispatch: func(_ string, _ Priority, d time.Duration) {
+			if d > 0 {
+				atomic.AddInt64(&queued, 1)
+			}
+		},
+	})
+
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		_ = l.RoundTrip(context.Background(), new(methods.CurrentTimeBody), nil)
+	}
+
+	// 2 burst + 4 at 10ms intervals
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("elapsed=%s", elapsed)
+	}
+
+	if queued != 4 {
+		t.Errorf("queued=%d", queued)
+	}
+
+	stats := l.Stats()
+	if stats.QueueTime == 0 || stats.MaxQueueTime == 0 {
+		t.Errorf("stats=%#v", stats)
+	}
+}
+
+func TestLimiterCancel(t *testing.T) {
+	rt := &blockingRoundTripper{release: make(chan struct{})}
+	l := RateLimit(rt, Limit{MaxInFlight: 1})
+
+	done := make(chan struct{})
+	go func() {
+		_ = l.RoundTrip(context.Background(), new(methods.CurrentTimeBody), nil)
+		close(done)
+	}()
+
+	for l.Stats().InFlight != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := l.RoundTrip(ctx, new(methods.CurrentTimeBody), nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("err=%v", err)
+	}
+
+	close(rt.release)
+	<-done
+
+	stats := l.Stats()
+	if stats.Canceled != 1 || stats.Queued != 0 || stats.Dispatched != 1 {
+		t.Errorf("stats=%#v", stats)
+	}
+}
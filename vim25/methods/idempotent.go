@@ -0,0 +1,126 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package methods
+
+// idempotent is the set of methods that can safely be called more than once with the same request,
+// such as methods that only read state. Methods not in this set are assumed to be non-idempotent.
+// ContinueRetrievePropertiesEx is not included, as each call advances the server-side cursor.
+var idempotent = map[string]bool{
+	"CheckForUpdates":                            true,
+	"CurrentTime":                                true,
+	"FetchUserPrivilegeOnEntities":               true,
+	"FindAllByDnsName":                           true,
+	"FindAllByIp":                                true,
+	"FindAllByUuid":                              true,
+	"FindByDatastorePath":                        true,
+	"FindByDnsName":                              true,
+	"FindByInventoryPath":                        true,
+	"FindByIp":                                   true,
+	"FindByUuid":                                 true,
+	"FindChild":                                  true,
+	"FindExtension":                              true,
+	"FindRulesForVm":                             true,
+	"HasPrivilegeOnEntities":                     true,
+	"HasPrivilegeOnEntity":                       true,
+	"HasUserPrivilegeOnEntities":                 true,
+	"ListFilesInGuest":                           true,
+	"ListGuestAliases":                           true,
+	"ListGuestMappedAliases":                     true,
+	"ListKeys":                                   true,
+	"ListKmipServers":                            true,
+	"ListProcessesInGuest":                       true,
+	"ListRegistryKeysInGuest":                    true,
+	"ListRegistryValuesInGuest":                  true,
+	"ListVStorageObject":                         true,
+	"QueryAssignedLicenses":                      true,
+	"QueryAvailableDisksForVmfs":                 true,
+	"QueryAvailablePartition":                    true,
+	"QueryAvailablePerfMetric":                   true,
+	"QueryBootDevices":                           true,
+	"QueryChangedDiskAreas":                      true,
+	"QueryCmmds":                                 true,
+	"QueryCompatibleHostForExistingDvs":          true,
+	"QueryCompatibleHostForNewDvs":               true,
+	"QueryConfigOption":                          true,
+	"QueryConfigOptionDescriptor":                true,
+	"QueryConfigOptionEx":                        true,
+	"QueryConfigTarget":                          true,
+	"QueryConnectionInfo":                        true,
+	"QueryConnectionInfoViaSpec":                 true,
+	"QueryDatastorePerformanceSummary":           true,
+	"QueryDescriptions":                          true,
+	"QueryDvsByUuid":                             true,
+	"QueryDvsCompatibleHostSpec":                 true,
+	"QueryDvsConfigTarget":                       true,
+	"QueryDvsFeatureCapability":                  true,
+	"QueryEvents":                                true,
+	"QueryFaultToleranceCompatibility":           true,
+	"QueryHostConnectionInfo":                    true,
+	"QueryIPAllocations":                         true,
+	"QueryIpPools":                               true,
+	"QueryLicenseSourceAvailability":             true,
+	"QueryLicenseUsage":                          true,
+	"QueryManagedBy":                             true,
+	"QueryMemoryOverhead":                        true,
+	"QueryMemoryOverheadEx":                      true,
+	"QueryNetworkHint":                           true,
+	"QueryOptions":                               true,
+	"QueryPartitionCreateDesc":                   true,
+	"QueryPartitionCreateOptions":                true,
+	"QueryPathSelectionPolicyOptions":            true,
+	"QueryPerf":                                  true,
+	"QueryPerfComposite":                         true,
+	"QueryPerfCounter":                           true,
+	"QueryPerfCounterByLevel":                    true,
+	"QueryPerfProviderSummary":                   true,
+	"QueryStorageArrayTypePolicyOptions":         true,
+	"QuerySupportedFeatures":                     true,
+	"QueryTargetCapabilities":                    true,
+	"QueryTpmAttestationReport":                  true,
+	"QueryUnmonitoredHosts":                      true,
+	"QueryUsedVlanIdInDvs":                       true,
+	"QueryVirtualDiskFragmentation":              true,
+	"QueryVirtualDiskGeometry":                   true,
+	"QueryVirtualDiskUuid":                       true,
+	"QueryVmfsDatastoreCreateOptions":            true,
+	"QueryVsanObjects":                           true,
+	"ReadEnvironmentVariableInGuest":             true,
+	"RetrieveAllPermissions":                     true,
+	"RetrieveArgumentDescription":                true,
+	"RetrieveDasAdvancedRuntimeInfo":             true,
+	"RetrieveDiskPartitionInfo":                  true,
+	"RetrieveEntityPermissions":                  true,
+	"RetrieveHardwareUptime":                     true,
+	"RetrieveProductComponents":                  true,
+	"RetrieveProperties":                         true,
+	"RetrievePropertiesEx":                       true,
+	"RetrieveRolePermissions":                    true,
+	"RetrieveServiceContent":                     true,
+	"RetrieveSnapshotInfo":                       true,
+	"RetrieveUserGroups":                         true,
+	"RetrieveVStorageInfrastructureObjectPolicy": true,
+	"RetrieveVStorageObject":                     true,
+	"SessionIsActive":                            true,
+	"ValidateCredentialsInGuest":                 true,
+	"WaitForUpdates":                             true,
+	"WaitForUpdatesEx":                           true,
+}
+
+// IsIdempotent returns true if the given method can safely be retried.
+func IsIdempotent(method string) bool {
+	return idempotent[method]
+}
@@ -18,6 +18,7 @@ package mo
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 
 	"github.com/vmware/govmomi/vim25/methods"
@@ -81,9 +82,42 @@ func ApplyPropertyChange(obj Reference, changes []types.PropertyChange) {
 	}
 }
 
+// loadRetrievePropertiesResponseMap loads each managed object into the dst entry for its type.
+func loadRetrievePropertiesResponseMap(res *types.RetrievePropertiesResponse, dst map[string]interface{}) error {
+	var kinds []string
+	content := make(map[string][]types.ObjectContent)
+
+	for _, o := range res.Returnval {
+		kind := o.Obj.Type
+		if _, ok := dst[kind]; !ok {
+			return fmt.Errorf("no destination for type %s", kind)
+		}
+		if _, ok := content[kind]; !ok {
+			kinds = append(kinds, kind)
+		}
+		content[kind] = append(content[kind], o)
+	}
+
+	for _, kind := range kinds {
+		err := LoadRetrievePropertiesResponse(&types.RetrievePropertiesResponse{Returnval: content[kind]}, dst[kind])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // LoadRetrievePropertiesResponse converts the response of a call to
 // RetrieveProperties to one or more managed objects.
+// The dst argument can also be a map of type name to pointer, for responses
+// containing managed objects of mixed types, such as:
+// map[string]interface{}{"VirtualMachine": &[]VirtualMachine{}, "HostSystem": &[]HostSystem{}}
 func LoadRetrievePropertiesResponse(res *types.RetrievePropertiesResponse, dst interface{}) error {
+	if m, ok := dst.(map[string]interface{}); ok {
+		return loadRetrievePropertiesResponseMap(res, m)
+	}
+
 	rt := reflect.TypeOf(dst)
 	if rt == nil || rt.Kind() != reflect.Ptr {
 		panic("need pointer")
@@ -142,3 +142,54 @@ func TestEmbeddedTypePropertySlice(t *testing.T) {
 		t.Fatal("Name fields should not be the same")
 	}
 }
+
+func TestLoadRetrievePropertiesResponseMap(t *testing.T) {
+	res := &types.RetrievePropertiesResponse{
+		Returnval: []types.ObjectContent{
+			{
+				Obj:     types.ManagedObjectReference{Type: "VirtualMachine", Value: "vm-1"},
+				PropSet: []types.DynamicProperty{{Name: "name", Val: "vm1"}},
+			},
+			{
+				Obj:     types.ManagedObjectReference{Type: "HostSystem", Value: "host-1"},
+				PropSet: []types.DynamicProperty{{Name: "name", Val: "host1"}},
+			},
+			{
+				Obj:     types.ManagedObjectReference{Type: "VirtualMachine", Value: "vm-2"},
+				PropSet: []types.DynamicProperty{{Name: "name", Val: "vm2"}},
+			},
+		},
+	}
+
+	var vms []VirtualMachine
+	var hosts []HostSystem
+
+	err := LoadRetrievePropertiesResponse(res, map[string]interface{}{"VirtualMachine": &vms, "HostSystem": &hosts})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vms) != 2 || vms[1].Name != "vm2" {
+		t.Errorf("vms=%#v", vms)
+	}
+
+	if len(hosts) != 1 || hosts[0].Name != "host1" {
+		t.Errorf("hosts=%#v", hosts)
+	}
+
+	err = LoadRetrievePropertiesResponse(res, map[string]interface{}{"VirtualMachine": &vms})
+	if err == nil {
+		t.Error("expected error") // no destination for HostSystem
+	}
+
+	var objs []interface{}
+
+	err = LoadRetrievePropertiesResponse(res, &objs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(objs) != 3 || objs[1].(HostSystem).Name != "host1" {
+		t.Errorf("objs=%#v", objs)
+	}
+}
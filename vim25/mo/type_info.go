@@ -253,3 +253,24 @@ func (t *typeInfo) LoadFromObjectContent(o types.ObjectContent) (reflect.Value,
 
 	return v, nil
 }
+
+// CollectPath returns the longest prefix of the given property path that can be collected for the given
+// managed object type, as the PropertyCollector does not support paths into arrays, such as the
+// "ipAddress" field of the VirtualMachine "guest.net" property.  The path is returned as-is if the type is unknown.
+func CollectPath(kind string, path string) string {
+	if _, ok := t[kind]; !ok {
+		return path
+	}
+
+	ti := typeInfoForType(kind)
+	elem := strings.Split(path, ".")
+
+	for i := len(elem); i > 0; i-- {
+		prefix := strings.Join(elem[:i], ".")
+		if _, ok := ti.props[prefix]; ok {
+			return prefix
+		}
+	}
+
+	return path
+}
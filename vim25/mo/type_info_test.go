@@ -27,6 +27,26 @@ func TestLoadAll(*testing.T) {
 	}
 }
 
+func TestCollectPath(t *testing.T) {
+	tests := []struct {
+		kind, path, expect string
+	}{
+		{"VirtualMachine", "name", "name"},
+		{"VirtualMachine", "summary.quickStats.overallCpuUsage", "summary.quickStats.overallCpuUsage"},
+		{"VirtualMachine", "guest.net.ipAddress", "guest.net"},
+		{"VirtualMachine", "config.hardware.device.key", "config.hardware.device"},
+		{"HostSystem", "enoent.foo", "enoent.foo"},
+		{"Enoent", "guest.net.ipAddress", "guest.net.ipAddress"},
+	}
+
+	for _, test := range tests {
+		path := CollectPath(test.kind, test.path)
+		if path != test.expect {
+			t.Errorf("%s %s: %s", test.kind, test.path, path)
+		}
+	}
+}
+
 // The virtual machine managed object has about 500 nested properties.
 // It's likely to be indicative of the function's performance in general.
 func BenchmarkLoadVirtualMachine(b *testing.B) {
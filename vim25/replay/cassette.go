@@ -0,0 +1,351 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Secrets are the XML element and JSON field names whose values are scrubbed from recorded requests and responses.
+// Within a response, the values of any elements nested in a secret element are also scrubbed,
+// such as the SAML token Assertion issued by the STS.
+var Secrets = []string{"password", "Password", "secret", "token", "cookie", "Assertion", "BinarySecret"}
+
+// SecretMethods are the methods for which all values are scrubbed from recorded responses,
+// such as tickets and session IDs.
+var SecretMethods = []string{
+	"AcquireCloneTicket",
+	"AcquireGenericServiceTicket",
+	"POST /rest/com/vmware/cis/session",
+}
+
+// Cursors maps methods to the element name of their paging token, which is not scrubbed as a secret,
+// such that each page is matched by its token during playback.
+var Cursors = map[string]string{
+	"RetrievePropertiesEx":         "token",
+	"ContinueRetrievePropertiesEx": "token",
+	"CancelRetrievePropertiesEx":   "token",
+}
+
+// Scrubbed replaces secret values in a Cassette.
+const Scrubbed = "(scrubbed)"
+
+// Cassette is a recording of SOAP and REST interactions.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request and response pair.
+type Interaction struct {
+	// Method is the SOAP method name or the HTTP method and path of a REST request.
+	Method string `json:"method"`
+	// This is the managed object reference a SOAP method was invoked on, in the form "Type:Value".
+	This string `json:"this,omitempty"`
+	// Request is the normalized request body, with the SOAP Header and secrets removed.
+	Request string `json:"request,omitempty"`
+
+	Response Response `json:"response"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// key is used to match requests with recorded Interactions.
+func (i *Interaction) key() string {
+	return strings.Join([]string{i.Method, i.This, i.Request}, "\n")
+}
+
+// Load reads a Cassette from the given file.
+func Load(name string) (*Cassette, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	c := new(Cassette)
+	return c, json.NewDecoder(f).Decode(c)
+}
+
+// Save writes the Cassette to the given file.
+func (c *Cassette) Save(name string) error {
+	var buf bytes.Buffer
+
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // keep the XML bodies readable
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(name, buf.Bytes(), 0600)
+}
+
+func isSecret(name string) bool {
+	for _, s := range Secrets {
+		if strings.EqualFold(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSecret returns true if the given element name is a secret, excluding the paging token of the Interaction's Method.
+func (i *Interaction) isSecret(name string) bool {
+	return Cursors[i.Method] != name && isSecret(name)
+}
+
+// newInteraction returns an Interaction for the given request, reading and restoring req.Body.
+func newInteraction(req *http.Request) (*Interaction, error) {
+	var body []byte
+
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	i := new(Interaction)
+
+	if strings.Contains(req.Header.Get("Content-Type"), "xml") {
+		if err := i.normalizeSOAP(body); err != nil {
+			return nil, err
+		}
+		return i, nil
+	}
+
+	i.Method = req.Method + " " + req.URL.Path
+	if req.URL.RawQuery != "" {
+		i.Method += "?" + req.URL.RawQuery
+	}
+	i.Request = normalizeJSON(body)
+
+	return i, nil
+}
+
+// normalizeSOAP sets the Interaction Method and This fields, along with a normalized form of the request body,
+// where the SOAP Header, namespace prefixes, insignificant whitespace and secret values are removed.
+func (i *Interaction) normalizeSOAP(body []byte) error {
+	var buf bytes.Buffer
+	var path []string
+	secret := false
+
+	dec := xml.NewDecoder(bytes.NewReader(body))
+
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			path = append(path, t.Name.Local)
+
+			if len(path) == 2 && t.Name.Local == "Header" {
+				if err = dec.Skip(); err != nil {
+					return err
+				}
+				path = path[:1]
+				continue
+			}
+
+			if len(path) == 3 {
+				i.Method = t.Name.Local // Envelope > Body > Method
+			}
+
+			if len(path) < 3 {
+				continue
+			}
+
+			var attrs []string
+			for _, a := range t.Attr {
+				if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+					continue
+				}
+				attrs = append(attrs, fmt.Sprintf(" %s=%q", a.Name.Local, a.Value))
+			}
+			sort.Strings(attrs)
+
+			fmt.Fprintf(&buf, "<%s%s>", t.Name.Local, strings.Join(attrs, ""))
+
+			secret = i.isSecret(t.Name.Local)
+
+			if len(path) == 4 && t.Name.Local == "_this" {
+				var ref struct {
+					Type  string `xml:"type,attr"`
+					Value string `xml:",chardata"`
+				}
+				if err = dec.DecodeElement(&ref, &t); err != nil {
+					return err
+				}
+				i.This = ref.Type + ":" + ref.Value
+				fmt.Fprintf(&buf, "%s</_this>", ref.Value)
+				path = path[:len(path)-1]
+			}
+		case xml.EndElement:
+			if len(path) >= 3 {
+				fmt.Fprintf(&buf, "</%s>", t.Name.Local)
+			}
+			path = path[:len(path)-1]
+			secret = false
+		case xml.CharData:
+			if len(path) < 3 {
+				continue
+			}
+			s := strings.TrimSpace(string(t))
+			if secret && s != "" {
+				s = Scrubbed
+			}
+			_ = xml.EscapeText(&buf, []byte(s))
+		}
+	}
+
+	i.Request = buf.String()
+
+	return nil
+}
+
+// scrubJSON returns the decoded JSON value with the values of secret fields replaced by Scrubbed,
+// or all string values if all is true.
+func scrubJSON(val interface{}, all bool) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for k := range v {
+			if isSecret(k) {
+				v[k] = Scrubbed
+			} else {
+				v[k] = scrubJSON(v[k], all)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = scrubJSON(v[i], all)
+		}
+	case string:
+		if all {
+			return Scrubbed
+		}
+	}
+
+	return val
+}
+
+// normalizeJSON returns the given JSON body with sorted keys, insignificant whitespace and secret values removed.
+func normalizeJSON(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var val interface{}
+	if err := json.Unmarshal(body, &val); err != nil {
+		return string(body) // not JSON
+	}
+
+	b, _ := json.Marshal(scrubJSON(val, false))
+	return string(b)
+}
+
+// scrubXML returns the given XML body with the character data of secret elements, including any nested elements,
+// replaced by Scrubbed. If all is true, all character data within the SOAP Body is replaced.
+func (i *Interaction) scrubXML(body []byte, all bool) []byte {
+	var buf bytes.Buffer
+	var last int64
+	depth := 0
+	secret := 0 // depth of the outermost secret element, 0 if none
+
+	dec := xml.NewDecoder(bytes.NewReader(body))
+
+	for {
+		offset := dec.InputOffset()
+
+		tok, err := dec.RawToken()
+		if err != nil {
+			break // io.EOF, or the remainder is not XML and copied as-is
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if secret == 0 && (i.isSecret(t.Name.Local) || (all && depth == 3)) { // Envelope > Body > Response
+				secret = depth
+			}
+		case xml.EndElement:
+			if secret == depth {
+				secret = 0
+			}
+			depth--
+		case xml.CharData:
+			if secret != 0 && len(bytes.TrimSpace(t)) != 0 {
+				buf.Write(body[last:offset])
+				buf.WriteString(Scrubbed)
+				last = dec.InputOffset()
+			}
+		}
+	}
+
+	buf.Write(body[last:])
+
+	return buf.Bytes()
+}
+
+// scrubResponse returns the given response body with the values of Secrets removed,
+// or all values if the Interaction's Method is one of SecretMethods.
+func (i *Interaction) scrubResponse(body []byte, contentType string) string {
+	all := false
+	for _, m := range SecretMethods {
+		if i.Method == m {
+			all = true
+		}
+	}
+
+	if strings.Contains(contentType, "xml") {
+		return string(i.scrubXML(body, all))
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var val interface{}
+	if err := dec.Decode(&val); err != nil {
+		return string(body) // not JSON
+	}
+
+	b, _ := json.Marshal(scrubJSON(val, all))
+	return string(b)
+}
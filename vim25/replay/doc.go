@@ -0,0 +1,29 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*
+Package replay records SOAP and REST interactions to a Cassette file, which can be
+replayed later without a connection to vCenter or ESX, for example to reproduce
+a bug seen against a customer's environment in a unit test.
+
+Interactions are recorded at the HTTP layer of a soap.Client, via Recorder.Attach.
+Session cookies, the values of request and response fields named in Secrets and
+the response values of SecretMethods, such as tickets and session IDs, are scrubbed
+from the recording. A Player serves the recorded responses, matching requests by
+method, managed object reference and a normalized form of the request body.
+Paging tokens named in Cursors are kept, such that each page is matched by its token.
+*/
+package replay
@@ -0,0 +1,196 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+// Recorder is an http.RoundTripper that records interactions to a Cassette.
+// Recording at the HTTP layer captures both SOAP traffic and vapi/rest.Client traffic.
+type Recorder struct {
+	mu       sync.Mutex
+	cassette Cassette
+
+	// Scrub, if set, is called to remove any sensitive data from a recorded Response,
+	// in addition to the Set-Cookie header values, Secrets and SecretMethods values which are always scrubbed.
+	Scrub func(*Interaction)
+}
+
+// NewRecorder creates a new Recorder.
+func NewRecorder() *Recorder {
+	return new(Recorder)
+}
+
+// Attach records all requests made by the given client, which can be a SOAP client or the
+// soap.Client embedded by vapi/rest.Client. Multiple clients can be attached to the same Recorder.
+func (r *Recorder) Attach(c *soap.Client) {
+	rt := c.Client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	c.Client.Transport = roundTripper(func(req *http.Request) (*http.Response, error) {
+		return r.record(rt, req)
+	})
+}
+
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func (r *Recorder) record(rt http.RoundTripper, req *http.Request) (*http.Response, error) {
+	i, err := newInteraction(req)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	ct := res.Header.Get("Content-Type")
+
+	i.Response = Response{
+		StatusCode: res.StatusCode,
+		Header:     make(http.Header),
+		Body:       i.scrubResponse(body, ct),
+	}
+
+	if ct != "" {
+		i.Response.Header.Set("Content-Type", ct)
+	}
+
+	for _, cookie := range res.Cookies() {
+		cookie.Value = Scrubbed
+		i.Response.Header.Add("Set-Cookie", cookie.String())
+	}
+
+	if r.Scrub != nil {
+		r.Scrub(i)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, i)
+	r.mu.Unlock()
+
+	return res, nil
+}
+
+// Cassette returns a copy of the recorded interactions.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return &Cassette{
+		Interactions: append([]*Interaction(nil), r.cassette.Interactions...),
+	}
+}
+
+// Save writes the recorded interactions to the given file.
+func (r *Recorder) Save(name string) error {
+	return r.Cassette().Save(name)
+}
+
+// Player is an http.RoundTripper that serves responses from a Cassette, without making any network requests.
+// Requests are matched by method, managed object reference and normalized request body.
+// Identical requests are served their recorded responses in order, after which the last response is repeated.
+type Player struct {
+	mu      sync.Mutex
+	replies map[string][]*Interaction
+}
+
+// NewPlayer creates a Player for the given Cassette.
+func NewPlayer(c *Cassette) *Player {
+	p := &Player{
+		replies: make(map[string][]*Interaction),
+	}
+
+	for _, i := range c.Interactions {
+		key := i.key()
+		p.replies[key] = append(p.replies[key], i)
+	}
+
+	return p
+}
+
+// Attach replaces the given client's transport with the Player.
+func (p *Player) Attach(c *soap.Client) {
+	c.Client.Transport = p
+}
+
+// RoundTrip implements http.RoundTripper.
+func (p *Player) RoundTrip(req *http.Request) (*http.Response, error) {
+	i, err := newInteraction(req)
+	if err != nil {
+		return nil, err
+	}
+
+	key := i.key()
+
+	p.mu.Lock()
+	replies := p.replies[key]
+	if len(replies) > 1 {
+		p.replies[key] = replies[1:]
+	}
+	p.mu.Unlock()
+
+	if len(replies) == 0 {
+		name := i.Method
+		if i.This != "" {
+			name += " " + i.This
+		}
+		return nil, fmt.Errorf("replay: no recorded response for %s", name)
+	}
+
+	r := replies[0].Response
+
+	res := &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          ioutil.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+
+	for k, v := range r.Header {
+		res.Header[k] = append([]string(nil), v...)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,242 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replay
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/session"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vapi/rest"
+	vapi "github.com/vmware/govmomi/vapi/simulator"
+	"github.com/vmware/govmomi/vapi/tags"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+)
+
+type result struct {
+	vms        []string
+	categories []string
+	ticket     string
+}
+
+// run is the workload recorded by, and replayed with, the given attach func.
+func run(t *testing.T, u *url.URL, attach func(*soap.Client)) result {
+	ctx := context.Background()
+
+	sc := soap.NewClient(u, true)
+	attach(sc)
+
+	c, err := vim25.NewClient(ctx, sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sm := session.NewManager(c)
+	err = sm.Login(ctx, u.User)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ticket, err := sm.AcquireCloneTicket(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := view.NewManager(c)
+	v, err := m.CreateContainerView(ctx, c.ServiceContent.RootFolder, []string{"VirtualMachine"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// paged, such that each page is matched by its token
+	var vms []mo.VirtualMachine
+	err = v.WithRetrieveOptions(property.RetrieveOptions{MaxObjects: 1}).Retrieve(ctx, []string{"VirtualMachine"}, []string{"name"}, &vms)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := rest.NewClient(c)
+	attach(r.Client)
+
+	err = r.Login(ctx, u.User)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	categories, err := tags.NewManager(r).ListCategories(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res result
+	for _, vm := range vms {
+		res.vms = append(res.vms, vm.Name)
+	}
+	res.categories = categories
+	res.ticket = ticket
+
+	return res
+}
+
+func TestRecordReplay(t *testing.T) {
+	m := simulator.VPX()
+	defer m.Remove()
+
+	err := m.Create()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := m.Service.NewServer()
+
+	path, handler := vapi.New(s.URL, nil)
+	m.Service.ServeMux.Handle(path, handler)
+
+	u := *s.URL
+	u.User = url.UserPassword("root", "Replay-S3cret!")
+
+	recorder := NewRecorder()
+	recorded := run(t, &u, recorder.Attach)
+	if len(recorded.vms) == 0 {
+		t.Fatal("no vms")
+	}
+
+	s.Close() // replay must not depend on the server
+
+	f, err := ioutil.TempFile("", "govmomi-cassette")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+	defer os.Remove(f.Name())
+
+	err = recorder.Save(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	password, _ := u.User.Password()
+	if strings.Contains(string(b), password) {
+		t.Error("cassette contains password")
+	}
+
+	if !strings.Contains(string(b), Scrubbed) {
+		t.Error("cassette not scrubbed")
+	}
+
+	if strings.Contains(string(b), recorded.ticket) {
+		t.Error("cassette contains clone ticket")
+	}
+
+	pages := make(map[string]bool)
+	for _, i := range recorder.Cassette().Interactions {
+		if i.Method == "ContinueRetrievePropertiesEx" {
+			if strings.Contains(i.Request, Scrubbed) || pages[i.Request] {
+				t.Errorf("paging token not matched: %s", i.Request)
+			}
+			pages[i.Request] = true
+		}
+	}
+	if len(pages) == 0 {
+		t.Error("no pages recorded")
+	}
+
+	cassette, err := Load(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayed := run(t, &u, NewPlayer(cassette).Attach)
+	if replayed.ticket != Scrubbed {
+		t.Errorf("ticket=%s", replayed.ticket)
+	}
+	recorded.ticket = replayed.ticket
+
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Errorf("%#v != %#v", recorded, replayed)
+	}
+
+	// unrecorded requests fail
+	sc := soap.NewClient(&u, true)
+	NewPlayer(new(Cassette)).Attach(sc)
+	_, err = vim25.NewClient(context.Background(), sc)
+	if err == nil || !strings.Contains(err.Error(), "no recorded response for RetrieveServiceContent ServiceInstance:ServiceInstance") {
+		t.Errorf("err=%v", err)
+	}
+}
+
+func TestRecordScrubResponse(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "a", Value: "cookie-a"})
+		http.SetCookie(w, &http.Cookie{Name: "b", Value: "cookie-b"})
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = io.WriteString(w, `<Envelope><Body><Response><Token><Assertion><Id>assertion-id</Id></Assertion></Token><Name>vcsim</Name></Response></Body></Envelope>`)
+	}))
+	defer s.Close()
+
+	req, err := http.NewRequest(http.MethodPost, s.URL, strings.NewReader("<Envelope><Body><Issue/></Body></Envelope>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := NewRecorder()
+	res, err := recorder.record(http.DefaultTransport, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = res.Body.Close()
+
+	i := recorder.Cassette().Interactions[0]
+
+	if n := len(i.Response.Header["Set-Cookie"]); n != 2 {
+		t.Errorf("Set-Cookie=%d", n)
+	}
+
+	for _, secret := range []string{"cookie-a", "cookie-b", "assertion-id"} {
+		if strings.Contains(i.Response.Body, secret) || strings.Contains(strings.Join(i.Response.Header["Set-Cookie"], ";"), secret) {
+			t.Errorf("%s not scrubbed", secret)
+		}
+	}
+
+	if !strings.Contains(i.Response.Body, "<Name>vcsim</Name>") {
+		t.Errorf("body=%s", i.Response.Body)
+	}
+
+	i = &Interaction{Method: "POST /rest/com/vmware/cis/session"}
+	body := i.scrubResponse([]byte(`{"value":"session-id"}`), "application/json")
+	if strings.Contains(body, "session-id") {
+		t.Errorf("body=%s", body)
+	}
+}
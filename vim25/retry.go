@@ -18,11 +18,17 @@ package vim25
 
 import (
 	"context"
+	"math"
+	"math/rand"
 	"net"
+	"net/http"
 	"net/url"
+	"reflect"
 	"time"
 
+	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 type RetryFunc func(err error) (retry bool, delay time.Duration)
@@ -32,22 +38,7 @@ type RetryFunc func(err error) (retry bool, delay time.Duration)
 // network error (for example: a connect timeout).
 func TemporaryNetworkError(n int) RetryFunc {
 	return func(err error) (retry bool, delay time.Duration) {
-		var nerr net.Error
-		var ok bool
-
-		// Never retry if this is not a network error.
-		switch rerr := err.(type) {
-		case *url.Error:
-			if nerr, ok = rerr.Err.(net.Error); !ok {
-				return false, 0
-			}
-		case net.Error:
-			nerr = rerr
-		default:
-			return false, 0
-		}
-
-		if !nerr.Temporary() {
+		if !isTemporaryNetworkError(err) {
 			return false, 0
 		}
 
@@ -60,6 +51,26 @@ func TemporaryNetworkError(n int) RetryFunc {
 	}
 }
 
+// isTemporaryNetworkError returns true if err is a temporary network error.
+func isTemporaryNetworkError(err error) bool {
+	var nerr net.Error
+	var ok bool
+
+	// Never retry if this is not a network error.
+	switch rerr := err.(type) {
+	case *url.Error:
+		if nerr, ok = rerr.Err.(net.Error); !ok {
+			return false
+		}
+	case net.Error:
+		nerr = rerr
+	default:
+		return false
+	}
+
+	return nerr.Temporary()
+}
+
 type retry struct {
 	roundTripper soap.RoundTripper
 
@@ -103,3 +114,168 @@ func (r *retry) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
 
 	return err
 }
+
+// RetryPolicy configures RetryWithPolicy, where zero values are replaced with the defaults noted below.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries of a single call, 0 for no limit other than MaxElapsedTime.
+	MaxRetries int
+
+	// InitialInterval is the delay before the first retry, defaults to 500ms.
+	InitialInterval time.Duration
+
+	// MaxInterval caps the delay between retries, defaults to 30s.
+	MaxInterval time.Duration
+
+	// Multiplier is applied to the delay after each retry, defaults to 2.
+	Multiplier float64
+
+	// Jitter randomizes each delay by up to the given factor, where 0.5 results in a
+	// delay between 50% and 150% of the computed interval. Defaults to 0, no jitter.
+	Jitter float64
+
+	// MaxElapsedTime limits the total time spent on a single call, including retries, 0 for no limit.
+	// A deadline on the call's context is also respected.
+	MaxElapsedTime time.Duration
+
+	// Retryable classifies errors as retryable, defaults to IsRetryable.
+	Retryable func(error) bool
+
+	// OnRetry, if set, is called before each retry of the given method.
+	OnRetry func(method string, attempt int, delay time.Duration, err error)
+}
+
+// retryableFaults are the faults for which a method call can be retried.
+var retryableFaults = []reflect.Type{
+	reflect.TypeOf((*types.BaseTaskInProgress)(nil)).Elem(),
+	reflect.TypeOf((*types.BaseResourceInUse)(nil)).Elem(),
+	reflect.TypeOf((*types.BaseHostCommunication)(nil)).Elem(),
+}
+
+// isRetryableFault returns true if fault is, or extends, one of the retryableFaults.
+func isRetryableFault(fault interface{}) bool {
+	if fault == nil {
+		return false
+	}
+
+	// soap.Fault.VimFault() values are not pointers, as required by the Base interfaces.
+	v := reflect.ValueOf(fault)
+	if v.Kind() != reflect.Ptr {
+		p := reflect.New(v.Type())
+		p.Elem().Set(v)
+		v = p
+	}
+
+	for _, t := range retryableFaults {
+		if v.Type().Implements(t) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsRetryable returns true if err is a temporary network error, an HTTP 503 (Service Unavailable) response,
+// or a fault that is likely to succeed if retried: TaskInProgress, ResourceInUse, HostCommunication or their subtypes.
+func IsRetryable(err error) bool {
+	switch {
+	case isTemporaryNetworkError(err):
+		return true
+	case soap.StatusCode(err) == http.StatusServiceUnavailable:
+		return true
+	case soap.IsSoapFault(err):
+		return isRetryableFault(soap.ToSoapFault(err).VimFault())
+	case soap.IsVimFault(err):
+		return isRetryableFault(soap.ToVimFault(err))
+	}
+
+	return false
+}
+
+type retryPolicy struct {
+	roundTripper soap.RoundTripper
+	policy       RetryPolicy
+}
+
+// RetryWithPolicy wraps the specified soap.RoundTripper, retrying calls that fail with an error
+// classified as retryable by the RetryPolicy, using exponential backoff.
+// Methods that are not idempotent, as determined by methods.IsIdempotent, are never retried.
+func RetryWithPolicy(roundTripper soap.RoundTripper, policy RetryPolicy) soap.RoundTripper {
+	if policy.InitialInterval == 0 {
+		policy.InitialInterval = 500 * time.Millisecond
+	}
+	if policy.MaxInterval == 0 {
+		policy.MaxInterval = 30 * time.Second
+	}
+	if policy.Multiplier == 0 {
+		policy.Multiplier = 2
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = IsRetryable
+	}
+
+	return &retryPolicy{
+		roundTripper: roundTripper,
+		policy:       policy,
+	}
+}
+
+// delay returns the backoff delay for the given retry attempt, starting at 1.
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	interval := float64(p.InitialInterval) * math.Pow(p.Multiplier, float64(attempt-1))
+	if interval > float64(p.MaxInterval) {
+		interval = float64(p.MaxInterval)
+	}
+
+	if p.Jitter > 0 {
+		delta := p.Jitter * interval
+		interval = interval - delta + rand.Float64()*(2*delta)
+	}
+
+	return time.Duration(interval)
+}
+
+func (r *retryPolicy) RoundTrip(ctx context.Context, req, res soap.HasFault) error {
+	method := soap.MethodName(req)
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		err := r.roundTripper.RoundTrip(ctx, req, res)
+		if err == nil || !methods.IsIdempotent(method) || !r.policy.Retryable(err) {
+			return err
+		}
+
+		if r.policy.MaxRetries > 0 && attempt > r.policy.MaxRetries {
+			return err
+		}
+
+		delay := r.policy.delay(attempt)
+		deadline := time.Now().Add(delay)
+
+		if r.policy.MaxElapsedTime > 0 && deadline.Sub(start) > r.policy.MaxElapsedTime {
+			return err
+		}
+
+		if ctx != nil {
+			if d, ok := ctx.Deadline(); ok && deadline.After(d) {
+				return err
+			}
+		}
+
+		if r.policy.OnRetry != nil {
+			r.policy.OnRetry(method, attempt, delay, err)
+		}
+
+		if ctx == nil {
+			time.Sleep(delay)
+			continue
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
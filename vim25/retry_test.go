@@ -18,9 +18,15 @@ package vim25
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
+	"github.com/vmware/govmomi/vim25/methods"
 	"github.com/vmware/govmomi/vim25/soap"
+	"github.com/vmware/govmomi/vim25/types"
 )
 
 type tempError struct{}
@@ -84,3 +90,157 @@ func TestRetry(t *testing.T) {
 		}
 	}
 }
+
+func fault(f types.AnyType) error {
+	sf := &soap.Fault{}
+	sf.Detail.Fault = f
+	return soap.WrapSoapFault(sf)
+}
+
+func TestRetryPolicy(t *testing.T) {
+	inProgress := fault(types.TaskInProgress{})
+	notConnected := fault(types.HostNotConnected{}) // extends HostCommunication
+	notFound := fault(types.ManagedObjectNotFound{})
+
+	var tcs = []struct {
+		req      soap.HasFault
+		errs     []error
+		expected error
+		retries  int
+	}{
+		{new(methods.RetrievePropertiesBody), []error{nil}, nil, 0},
+		{new(methods.RetrievePropertiesBody), []error{inProgress, notConnected, nil}, nil, 2},
+		{new(methods.RetrievePropertiesBody), []error{tempError{}, inProgress, inProgress, inProgress}, inProgress, 3},
+		{new(methods.RetrievePropertiesBody), []error{notFound}, notFound, 0},
+		{new(methods.RetrievePropertiesBody), []error{soap.WrapVimFault(&types.ResourceInUse{}), nil}, nil, 1},
+		{new(methods.PowerOnVM_TaskBody), []error{inProgress}, inProgress, 0},                 // not idempotent
+		{new(methods.ContinueRetrievePropertiesExBody), []error{tempError{}}, tempError{}, 0}, // cursor moves on each call
+	}
+
+	for i, tc := range tcs {
+		retries := 0
+
+		rt := RetryWithPolicy(&fakeRoundTripper{errs: tc.errs}, RetryPolicy{
+			MaxRetries:      3,
+			InitialInterval: time.Millisecond,
+			Jitter:          0.5,
+			OnRetry: func(method string, attempt int, delay time.Duration, err error) {
+				retries++
+				if method != soap.MethodName(tc.req) || attempt != retries {
+					t.Errorf("%d: method=%s, attempt=%d", i, method, attempt)
+				}
+			},
+		})
+
+		err := rt.RoundTrip(context.Background(), tc.req, nil)
+		if err != tc.expected {
+			t.Errorf("%d: expected: %s, got: %s", i, tc.expected, err)
+		}
+
+		if retries != tc.retries {
+			t.Errorf("%d: expected %d retries, got: %d", i, tc.retries, retries)
+		}
+	}
+}
+
+func TestRetryPolicyDeadline(t *testing.T) {
+	rt := RetryWithPolicy(&fakeRoundTripper{errs: []error{tempError{}, nil}}, RetryPolicy{
+		InitialInterval: time.Minute,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// the first retry delay exceeds the context deadline
+	err := rt.RoundTrip(ctx, new(methods.RetrievePropertiesBody), nil)
+	if err != (tempError{}) {
+		t.Errorf("err=%v", err)
+	}
+
+	rt = RetryWithPolicy(&fakeRoundTripper{errs: []error{tempError{}, tempError{}, tempError{}}}, RetryPolicy{
+		InitialInterval: 10 * time.Millisecond,
+		MaxElapsedTime:  25 * time.Millisecond,
+	})
+
+	// the second retry would exceed MaxElapsedTime
+	err = rt.RoundTrip(ctx, new(methods.RetrievePropertiesBody), nil)
+	if err != (tempError{}) {
+		t.Errorf("err=%v", err)
+	}
+}
+
+func TestRetryPolicyServiceUnavailable(t *testing.T) {
+	hits := 0
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rt := RetryWithPolicy(soap.NewClient(u, true), RetryPolicy{
+		MaxRetries:      2,
+		InitialInterval: time.Millisecond,
+	})
+
+	req := &methods.RetrievePropertiesBody{Req: new(types.RetrieveProperties)}
+	err = rt.RoundTrip(context.Background(), req, new(methods.RetrievePropertiesBody))
+	if soap.StatusCode(err) != http.StatusServiceUnavailable {
+		t.Errorf("err=%v", err)
+	}
+
+	if hits != 3 {
+		t.Errorf("hits=%d", hits)
+	}
+}
+
+func TestRetryPolicyFault(t *testing.T) {
+	hits := 0
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "text/xml")
+
+		if hits == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+<soapenv:Body><soapenv:Fault><faultcode>ServerFaultCode</faultcode><faultstring>busy</faultstring>
+<detail><TaskInProgressFault xmlns="urn:vim25" xsi:type="TaskInProgress"></TaskInProgressFault></detail>
+</soapenv:Fault></soapenv:Body></soapenv:Envelope>`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<soapenv:Envelope xmlns:soapenv="http://schemas.xmlsoap.org/soap/envelope/">
+<soapenv:Body><RetrievePropertiesResponse xmlns="urn:vim25"></RetrievePropertiesResponse></soapenv:Body></soapenv:Envelope>`))
+	}))
+	defer s.Close()
+
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rt := RetryWithPolicy(soap.NewClient(u, true), RetryPolicy{
+		MaxRetries:      3,
+		InitialInterval: time.Millisecond,
+	})
+
+	// the Fault decoded by the first attempt must not leak into the response of the retry
+	req := &methods.RetrievePropertiesBody{Req: new(types.RetrieveProperties)}
+	res := new(methods.RetrievePropertiesBody)
+	err = rt.RoundTrip(context.Background(), req, res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hits != 2 || res.Res == nil {
+		t.Errorf("hits=%d, res=%#v", hits, res)
+	}
+}
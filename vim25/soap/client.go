@@ -35,6 +35,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"strings"
 	"sync"
@@ -73,6 +74,8 @@ type Client struct {
 	UserAgent string
 
 	cookie string
+
+	interceptors []Interceptor
 }
 
 var schemeMatch = regexp.MustCompile(`^\w+://`)
@@ -191,6 +194,8 @@ func (c *Client) NewServiceClient(path string, namespace string) *Client {
 		}
 	}
 
+	client.interceptors = c.interceptors
+
 	// Copy any query params (e.g. GOVMOMI_TUNNEL_PROXY_PORT used in testing)
 	client.u.RawQuery = vc.RawQuery
 
@@ -455,6 +460,43 @@ func (c *Client) Do(ctx context.Context, req *http.Request, f func(*http.Respons
 	if ctx == nil {
 		ctx = context.Background()
 	}
+
+	if len(c.interceptors) == 0 {
+		return c.do(ctx, req, f, nil)
+	}
+
+	call, ok := ctx.Value(callContext{}).(*Call)
+	if !ok {
+		call = &Call{
+			Method:       req.Method + " " + callPath(req.URL),
+			RequestBytes: req.ContentLength,
+		}
+	}
+
+	next := func(ctx context.Context) error {
+		return c.do(ctx, req, f, call)
+	}
+
+	for i := len(c.interceptors) - 1; i >= 0; i-- {
+		interceptor, inner := c.interceptors[i], next
+		next = func(ctx context.Context) error {
+			return interceptor.Intercept(ctx, call, inner)
+		}
+	}
+
+	return next(ctx)
+}
+
+func (c *Client) do(ctx context.Context, req *http.Request, f func(*http.Response) error, call *Call) (err error) {
+	if call != nil {
+		call.Start = time.Now()
+		defer func() {
+			call.End = time.Now()
+			call.Err = err
+			call.Fault = faultName(err)
+		}()
+	}
+
 	// Create debugging context for this round trip
 	d := c.d.newRoundTrip()
 	if d.enabled() {
@@ -493,6 +535,11 @@ func (c *Client) Do(ctx context.Context, req *http.Request, f func(*http.Respons
 		d.debugResponse(res)
 	}
 
+	if call != nil {
+		call.StatusCode = res.StatusCode
+		res.Body = countReader{res.Body, &call.ResponseBytes}
+	}
+
 	return f(res)
 }
 
@@ -558,14 +605,24 @@ func (c *Client) RoundTrip(ctx context.Context, reqBody, resBody HasFault) error
 	}
 	req.Header.Set(`SOAPAction`, action)
 
-	return c.Do(context.WithValue(ctx, kindContext{}, resBody), req, func(res *http.Response) error {
+	ctx = context.WithValue(ctx, kindContext{}, resBody)
+	if len(c.interceptors) != 0 {
+		ctx = context.WithValue(ctx, callContext{}, newCall(reqBody, len(xml.Header)+len(b)))
+	}
+
+	return c.Do(ctx, req, func(res *http.Response) error {
 		switch res.StatusCode {
 		case http.StatusOK:
 			// OK
 		case http.StatusInternalServerError:
 			// Error, but typically includes a body explaining the error
 		default:
-			return errors.New(res.Status)
+			return statusError{res.StatusCode, res.Status}
+		}
+
+		// Zero the response body, which may have been decoded by a previous attempt, such as a Fault when retrying a call.
+		if v := reflect.ValueOf(resBody); v.Kind() == reflect.Ptr && !v.IsNil() {
+			v.Elem().Set(reflect.Zero(v.Elem().Type()))
 		}
 
 		dec := xml.NewDecoder(res.Body)
@@ -31,6 +31,24 @@ func (r regularError) Error() string {
 	return r.err.Error()
 }
 
+type statusError struct {
+	code   int
+	status string
+}
+
+func (s statusError) Error() string {
+	return s.status
+}
+
+// StatusCode returns the HTTP status code of an error returned by Client.RoundTrip
+// when the response status is neither 200 nor 500, or 0 if err is not such an error.
+func StatusCode(err error) int {
+	if s, ok := err.(statusError); ok {
+		return s.code
+	}
+	return 0
+}
+
 type soapFaultError struct {
 	fault *Fault
 }
@@ -0,0 +1,193 @@
+/*
+Copyright (c) 2018 VMware, Inc. All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package soap
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Call describes a single SOAP or REST call made via Client.Do, for use by an Interceptor.
+// The request fields are set before the Interceptor is invoked, the response fields are set
+// when the next function returns.
+type Call struct {
+	// Method is the SOAP method name, such as "RetrieveProperties", or the HTTP method and path of other requests,
+	// with object identifiers in the path replaced by "*", such as "GET /rest/vcenter/vm/*".
+	Method string
+	// This is the managed object reference the SOAP method was invoked on, zero value for other requests.
+	This types.ManagedObjectReference
+	// RequestBytes is the size of the encoded request body, -1 if unknown.
+	RequestBytes int64
+
+	Start         time.Time
+	End           time.Time
+	StatusCode    int
+	ResponseBytes int64  // Number of response body bytes read
+	Fault         string // Fault type name, such as "NotAuthenticated", if the call failed with a SOAP fault
+	Err           error
+}
+
+// Duration returns the elapsed time of the call, including decoding of the response.
+func (c *Call) Duration() time.Duration {
+	return c.End.Sub(c.Start)
+}
+
+// Interceptor can be registered via Client.Intercept to observe each call,
+// for example to record metrics or add tracing spans.
+// Implementations must call next to continue the call, optionally with a derived context.
+type Interceptor interface {
+	Intercept(ctx context.Context, call *Call, next func(context.Context) error) error
+}
+
+// InterceptorFunc is an adapter allowing an ordinary function to be used as an Interceptor.
+type InterceptorFunc func(ctx context.Context, call *Call, next func(context.Context) error) error
+
+func (f InterceptorFunc) Intercept(ctx context.Context, call *Call, next func(context.Context) error) error {
+	return f(ctx, call, next)
+}
+
+// Intercept registers the given Interceptors, which are invoked in the order registered.
+// Interceptors are inherited by clients created via NewServiceClient, such as the vapi rest.Client,
+// and should be registered before the Client is used.
+func (c *Client) Intercept(interceptors ...Interceptor) {
+	c.interceptors = append(c.interceptors, interceptors...)
+}
+
+type callContext struct{}
+
+// MethodName returns the method name of the given request body, for example "RetrieveProperties" for a *methods.RetrievePropertiesBody.
+// An empty string is returned if body does not have a Req field.
+func MethodName(body HasFault) string {
+	v := reflect.ValueOf(body)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+
+	req, ok := v.Type().FieldByName("Req")
+	if !ok {
+		return ""
+	}
+
+	t := req.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
+
+// callPath returns the path of a non-SOAP request for use as Call.Method.
+// Path segments that identify an object, such as "vm-42" in "/rest/vcenter/vm/vm-42", are replaced with "*"
+// and file transfer paths are truncated to their first segment, bounding the number of distinct methods.
+func callPath(u *url.URL) string {
+	segments := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+
+	switch segments[0] {
+	case "rest", "api":
+		for i, s := range segments {
+			if strings.ContainsAny(s, "0123456789:") {
+				segments[i] = "*"
+			}
+		}
+	default:
+		// datastore, guest and nfc file transfers
+		if len(segments) > 1 {
+			segments = []string{segments[0], "*"}
+		}
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
+
+// newCall returns a Call for the given SOAP request body, of type methods.*Body
+func newCall(req HasFault, size int) *Call {
+	call := &Call{
+		Method:       MethodName(req),
+		RequestBytes: int64(size),
+	}
+
+	v := reflect.ValueOf(req)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return call
+	}
+
+	if r := v.FieldByName("Req"); r.IsValid() {
+		if r.Kind() == reflect.Ptr {
+			if r.IsNil() {
+				return call
+			}
+			r = r.Elem()
+		}
+
+		if this := r.FieldByName("This"); this.IsValid() {
+			call.This, _ = this.Interface().(types.ManagedObjectReference)
+		}
+	}
+
+	return call
+}
+
+// faultName returns the type name of the given error's SOAP or vim fault, if any.
+func faultName(err error) string {
+	var fault interface{}
+
+	switch {
+	case IsSoapFault(err):
+		fault = ToSoapFault(err).VimFault()
+	case IsVimFault(err):
+		fault = ToVimFault(err)
+	default:
+		return ""
+	}
+
+	if fault == nil {
+		return ""
+	}
+
+	t := reflect.TypeOf(fault)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t.Name()
+}
+
+// countReader counts the bytes read from the wrapped io.ReadCloser.
+type countReader struct {
+	io.ReadCloser
+	n *int64
+}
+
+func (r countReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	*r.n += int64(n)
+	return n, err
+}
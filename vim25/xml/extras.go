@@ -47,6 +47,16 @@ func stringToType(s string) reflect.Type {
 	return stringToTypeMap[s]
 }
 
+// Return a string for the specified reflect.Type, using the Encoder's TypeName func if set.
+func (p *printer) typeToString(typ reflect.Type) string {
+	if p.encoder.TypeName != nil && typ.Name() != "" {
+		if name, ok := p.encoder.TypeName(typ); ok {
+			return name
+		}
+	}
+	return typeToString(typ)
+}
+
 // Return a string for the specified reflect.Type. Panic if unknown.
 func typeToString(typ reflect.Type) string {
 	switch typ.Kind() {
@@ -220,3 +220,32 @@ func TestUnmarshalInterfaceWithoutTypeAttr(t *testing.T) {
 		}
 	}
 }
+
+func TestMarshalOmitEmptySliceElements(t *testing.T) {
+	var r struct {
+		XMLName Name     `xml:"root"`
+		Values  []int64  `xml:"value,omitempty"`
+		Names   []string `xml:"name,omitempty"`
+	}
+
+	b, err := Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); s != "<root></root>" {
+		t.Errorf("Marshal=%s", s)
+	}
+
+	r.Values = []int64{0, 0, 10, 0, 20}
+	r.Names = []string{"", "a"}
+
+	b, err = Marshal(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := "<root><value>0</value><value>0</value><value>10</value><value>0</value><value>20</value><name></name><name>a</name></root>"
+	if s := string(b); s != expect {
+		t.Errorf("Marshal=%s", s)
+	}
+}
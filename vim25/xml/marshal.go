@@ -126,11 +126,15 @@ func MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
 // An Encoder writes XML data to an output stream.
 type Encoder struct {
 	p printer
+
+	// TypeName, if set, is used to derive the xsi:type attribute value of
+	// a named type, falling back to the type name when it returns false.
+	TypeName func(typ reflect.Type) (string, bool)
 }
 
 // NewEncoder returns a new encoder that writes to w.
 func NewEncoder(w io.Writer) *Encoder {
-	e := &Encoder{printer{Writer: bufio.NewWriter(w)}}
+	e := &Encoder{p: printer{Writer: bufio.NewWriter(w)}}
 	e.p.encoder = e
 	return e
 }
@@ -398,8 +402,15 @@ func (p *printer) marshalValue(val reflect.Value, finfo *fieldInfo, startTemplat
 
 	// Slices and arrays iterate over the elements. They do not have an enclosing tag.
 	if (kind == reflect.Slice || kind == reflect.Array) && typ.Elem().Kind() != reflect.Uint8 {
+		// omitempty applies to the slice itself, zero value elements must not be dropped
+		efinfo := finfo
+		if finfo != nil && finfo.flags&fOmitEmpty != 0 {
+			f := *finfo
+			f.flags &^= fOmitEmpty
+			efinfo = &f
+		}
 		for i, n := 0, val.Len(); i < n; i++ {
-			if err := p.marshalValue(val.Index(i), finfo, startTemplate); err != nil {
+			if err := p.marshalValue(val.Index(i), efinfo, startTemplate); err != nil {
 				return err
 			}
 		}
@@ -443,7 +454,7 @@ func (p *printer) marshalValue(val reflect.Value, finfo *fieldInfo, startTemplat
 
 	// Add type attribute if necessary
 	if finfo != nil && finfo.flags&fTypeAttr != 0 {
-		start.Attr = append(start.Attr, Attr{xmlSchemaInstance, typeToString(typ)})
+		start.Attr = append(start.Attr, Attr{xmlSchemaInstance, p.typeToString(typ)})
 	}
 
 	// Attributes